@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 21:40:12
+ */
+
+package gologging
+
+// checkWritable skips the check, as access(2) isn't provided on the
+// platform, and the file is checked when it's opened.
+func checkWritable(path string) error {
+	return nil
+}
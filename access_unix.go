@@ -0,0 +1,23 @@
+//go:build unix
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 23:12:40
+ */
+
+package gologging
+
+import (
+	"syscall"
+)
+
+const (
+	_W_OK = 0x2
+)
+
+// checkWritable checks the file or directory is writable by the process
+// without writing it.
+func checkWritable(path string) error {
+	return syscall.Access(path, _W_OK)
+}
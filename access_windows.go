@@ -0,0 +1,29 @@
+//go:build windows
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 23:12:40
+ */
+
+package gologging
+
+import (
+	"errors"
+	"os"
+)
+
+// checkWritable checks the read-only attribute of the file or directory,
+// ACLs aren't checked.
+func checkWritable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() && info.Mode().Perm()&0200 == 0 {
+		return errors.New("file is read-only")
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+/**
+ * Access log middleware of net/http.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 20:16:05
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AccessLogFormat int
+
+const (
+	// Apache Common Log Format followed by the duration in microseconds,
+	// as '%h %l %u %t "%r" %>s %b %D' of Apache.
+	ACCESS_LOG_COMMON AccessLogFormat = iota
+	// Apache Combined Log Format followed by the duration in microseconds,
+	// as '%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i" %D' of Apache.
+	ACCESS_LOG_COMBINED
+	// A JSON object of all the fields.
+	ACCESS_LOG_JSON
+)
+
+const (
+	DEFAULT_REQUEST_ID_HEADER = "X-Request-ID"
+
+	_CLF_TIME_LAYOUT    = "02/Jan/2006:15:04:05 -0700"
+	_REQUEST_ID_BYTES   = 16
+	_MAX_REQUEST_ID_LEN = 128
+)
+
+var accessLogFormatNames = []string{
+	"common",
+	"combined",
+	"json",
+}
+
+func NewAccessLogFormatString(name string) (AccessLogFormat, error) {
+	for idx, formatName := range accessLogFormatNames {
+		if formatName == strings.ToLower(name) {
+			return AccessLogFormat(idx), nil
+		}
+	}
+
+	return ACCESS_LOG_COMMON, goutils.NewErr("unknown access log format: %s", name)
+}
+
+func (format AccessLogFormat) Name() string {
+	if format >= ACCESS_LOG_COMMON && int(format) < len(accessLogFormatNames) {
+		return accessLogFormatNames[format]
+	}
+
+	return "UNKNOWN_ACCESS_LOG_FORMAT"
+}
+
+// AccessLogConfig configures the access log middleware, the zero value
+// logs all the requests in ACCESS_LOG_COMMON.
+type AccessLogConfig struct {
+	Format AccessLogFormat
+	// Requests of these paths aren't logged, e.g. health checks. A path
+	// ending with '*' matches the paths with the prefix.
+	SkipPaths []string
+	// Requests are not logged if Skip returns true.
+	Skip func(r *http.Request) bool
+	// Level of the log by the status, ERROR for 5xx, WARN for 4xx and INFO
+	// for others by default.
+	Level func(status int) Level
+	// Header of the request ID, DEFAULT_REQUEST_ID_HEADER by default. The
+	// request ID is taken from the request or generated, and it's set to the
+	// response header and the context of the request by WithRequestID.
+	RequestIDHeader string
+	// Generates a request ID if the request has none, 16 random bytes in
+	// hex by default.
+	NewRequestID func() string
+}
+
+// AccessLog returns a middleware which logs each request to 'logger' after
+// it's served. The caller info of the logs is empty, as the caller is in
+// net/http.
+func AccessLog(logger *Logger, config AccessLogConfig) func(http.Handler) http.Handler {
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = DEFAULT_REQUEST_ID_HEADER
+	}
+	if config.NewRequestID == nil {
+		config.NewRequestID = newRequestID
+	}
+	if config.Level == nil {
+		config.Level = levelOfStatus
+	}
+
+	return func(next http.Handler) http.Handler {
+		return &accessLogHandler{logger: logger, config: config, next: next}
+	}
+}
+
+type accessLogHandler struct {
+	logger *Logger
+	config AccessLogConfig
+	next   http.Handler
+}
+
+// Fields of an access log.
+type accessRecord struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	User       string    `json:"user,omitempty"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	Duration   float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	RequestID  string    `json:"request_id"`
+}
+
+func (h *accessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(h.config.RequestIDHeader)
+	if requestID == "" || len(requestID) > _MAX_REQUEST_ID_LEN {
+		requestID = h.config.NewRequestID()
+	}
+	w.Header().Set(h.config.RequestIDHeader, requestID)
+	ctx := WithRequestID(r.Context(), requestID)
+	r = r.WithContext(ctx)
+
+	if h.skip(r) {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	rw := &responseRecorder{ResponseWriter: w}
+	h.next.ServeHTTP(rw, r)
+
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	record := &accessRecord{
+		Time:       start,
+		RemoteAddr: remoteHost(r.RemoteAddr),
+		Method:     r.Method,
+		URI:        r.RequestURI,
+		Proto:      r.Proto,
+		Status:     status,
+		Bytes:      rw.bytes,
+		Duration:   float64(time.Since(start).Microseconds()) / 1000,
+		Referer:    r.Referer(),
+		UserAgent:  r.UserAgent(),
+		RequestID:  requestID,
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		record.User = user
+	}
+	if record.URI == "" {
+		record.URI = r.URL.RequestURI()
+	}
+
+	// The caller is in net/http, so the caller info is left out
+	h.logger.withoutCaller().log(ctx, h.config.Level(status), "%s", h.format(record))
+}
+
+func (h *accessLogHandler) skip(r *http.Request) bool {
+	for _, path := range h.config.SkipPaths {
+		if strings.HasSuffix(path, "*") {
+			if strings.HasPrefix(r.URL.Path, path[:len(path)-1]) {
+				return true
+			}
+		} else if r.URL.Path == path {
+			return true
+		}
+	}
+
+	return h.config.Skip != nil && h.config.Skip(r)
+}
+
+func (h *accessLogHandler) format(record *accessRecord) []byte {
+	if h.config.Format == ACCESS_LOG_JSON {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return []byte(err.Error())
+		}
+		return data
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s - %s [%s] \"%s %s %s\" %d %s",
+		dashIfEmpty(record.RemoteAddr), dashIfEmpty(record.User), record.Time.Format(_CLF_TIME_LAYOUT),
+		record.Method, escapeQuote(record.URI), record.Proto, record.Status, clfBytes(record.Bytes))
+	if h.config.Format == ACCESS_LOG_COMBINED {
+		fmt.Fprintf(&b, " \"%s\" \"%s\"", escapeQuote(record.Referer), escapeQuote(record.UserAgent))
+	}
+	fmt.Fprintf(&b, " %d", int64(record.Duration*1000))
+
+	return b.Bytes()
+}
+
+// responseRecorder records the status and the size of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+
+	return n, err
+}
+
+func (rw *responseRecorder) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rw *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, goutils.NewErr("response writer doesn't support hijacking")
+	}
+
+	return hijacker.Hijack()
+}
+
+// Unwrap is used by http.ResponseController.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func levelOfStatus(status int) Level {
+	switch {
+	case status >= 500:
+		return ERROR
+	case status >= 400:
+		return WARN
+	default:
+		return INFO
+	}
+}
+
+func newRequestID() string {
+	var b [_REQUEST_ID_BYTES]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(b[:])
+}
+
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return _EMPTY_FIELD
+	}
+
+	return s
+}
+
+func clfBytes(n int64) string {
+	if n == 0 {
+		return _EMPTY_FIELD
+	}
+
+	return strconv.FormatInt(n, 10)
+}
+
+func escapeQuote(s string) string {
+	if s == "" {
+		return _EMPTY_FIELD
+	}
+
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
@@ -0,0 +1,135 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 20:41:27
+ */
+
+package gologging
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newAccessLogServer(format AccessLogFormat) (http.Handler, *Logger, func() string) {
+	logger, out := newBufferLogger("${levelname} ${request_id} ${message}")
+	config := AccessLogConfig{
+		Format:       format,
+		SkipPaths:    []string{"/healthz", "/static/*"},
+		NewRequestID: func() string { return "generated" },
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			io.WriteString(w, "hello "+RequestIDFromContext(r.Context()))
+		}
+	})
+
+	readLog := func() string {
+		s := out.String()
+		out.Reset()
+		return s
+	}
+
+	return AccessLog(logger, config)(h), logger, readLog
+}
+
+func TestAccessLog(t *testing.T) {
+	handler, logger, readLog := newAccessLogServer(ACCESS_LOG_COMBINED)
+	defer logger.Close()
+
+	req := httptest.NewRequest("GET", "/hello?a=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set(DEFAULT_REQUEST_ID_HEADER, "req-1")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if body := resp.Body.String(); body != "hello req-1" {
+		t.Fatalf("request ID isn't in the context, body: %s", body)
+	}
+	if id := resp.Header().Get(DEFAULT_REQUEST_ID_HEADER); id != "req-1" {
+		t.Fatalf("request ID isn't in the response, id: %s", id)
+	}
+
+	combined := regexp.MustCompile(`^INFO req-1 192\.0\.2\.1 - - \[[^\]]+\] "GET /hello\?a=1 HTTP/1\.1" 200 11 "-" "test-agent" \d+\n$`)
+	if line := readLog(); !combined.MatchString(line) {
+		t.Fatalf("unexpected combined log: %q", line)
+	}
+
+	cases := []struct {
+		path   string
+		prefix string
+	}{
+		{"/missing", "WARN generated "},
+		{"/fail", "ERROR generated "},
+		{"/healthz", ""},
+		{"/static/app.js", ""},
+	}
+	for _, c := range cases {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", c.path, nil))
+		line := readLog()
+		if c.prefix == "" {
+			if line != "" {
+				t.Fatalf("skipped path is logged, path: %s, log: %q", c.path, line)
+			}
+		} else if !strings.HasPrefix(line, c.prefix) {
+			t.Fatalf("unexpected level, path: %s, log: %q", c.path, line)
+		}
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	handler, logger, readLog := newAccessLogServer(ACCESS_LOG_JSON)
+	defer logger.Close()
+
+	req := httptest.NewRequest("POST", "/fail", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := strings.TrimPrefix(readLog(), "ERROR generated ")
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatalf("invalid JSON log: %q, err: %v", line, err)
+	}
+
+	expected := map[string]interface{}{
+		"method":      "POST",
+		"uri":         "/fail",
+		"status":      float64(500),
+		"bytes":       float64(0),
+		"remote_addr": "192.0.2.1",
+		"user_agent":  "test-agent",
+		"request_id":  "generated",
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Fatalf("unexpected field, key: %s, expected: %v, got: %v", k, v, record[k])
+		}
+	}
+	if _, ok := record["duration_ms"]; !ok {
+		t.Fatalf("no duration in log: %q", line)
+	}
+}
+
+// The caller of access logs is in net/http, which is left out.
+func TestAccessLogCaller(t *testing.T) {
+	logger, out := newBufferLogger("[${filename}:${funcname}] ${message}")
+	defer logger.Close()
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	AccessLog(logger, AccessLogConfig{})(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if line := out.String(); !strings.HasPrefix(line, "[:] ") {
+		t.Fatalf("caller info isn't left out: %q", line)
+	}
+}
@@ -0,0 +1,402 @@
+/**
+ * Tamper-evident audit logs by hash chaining.
+ *
+ * Each line of an audit log ends with a hash over the hash of the previous
+ * line and the record of the line:
+ *	${record} #chain=hex(sha256(prev || record))
+ * The hash of the line before the first one is 32 zero bytes. The chain
+ * continues across rotations, and the head is persisted in '${file}.chain'
+ * when the file is rotated or closed, so that a restarted process continues
+ * the chain of an empty file.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 17:20:36
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	_CHAIN_SEP       = " #chain="
+	_CHAIN_SUFFIX    = ".chain"
+	_CHAIN_HASH_SIZE = sha256.Size
+	_TAIL_CHUNK_SIZE = 4096
+)
+
+// hashChain writes each log as a line ending with the chained hash. It's
+// the output of a FileHandler, and writes to 'w', which is the file or the
+// buffer of the handler.
+type hashChain struct {
+	w        io.Writer
+	headPath string
+	head     [_CHAIN_HASH_SIZE]byte
+	h        hash.Hash
+}
+
+// newHashChain continues the chain of the file, 'ring' is used to read the
+// file if it's encrypted.
+func newHashChain(fileName string, ring *KeyRing) (*hashChain, error) {
+	chain := &hashChain{headPath: fileName + _CHAIN_SUFFIX, h: sha256.New()}
+	if err := chain.loadHead(fileName, ring); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to load chain head, file: %s", fileName)
+	}
+
+	return chain, nil
+}
+
+// Write writes 'p' as one line with the hash, newlines inside 'p' are
+// escaped as '\n'. The head is only advanced if the line is written.
+func (chain *hashChain) Write(p []byte) (int, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	record := bytes.TrimSuffix(p, []byte{_NEWLINE})
+	line := *buf
+	for _, c := range record {
+		if c == _NEWLINE {
+			line = append(line, '\\', 'n')
+		} else {
+			line = append(line, c)
+		}
+	}
+
+	sum := chainHash(chain.h, chain.head[:], line)
+	var sumHex [_CHAIN_HASH_SIZE * 2]byte
+	hex.Encode(sumHex[:], sum[:])
+	line = append(line, _CHAIN_SEP...)
+	line = append(line, sumHex[:]...)
+	line = append(line, _NEWLINE)
+	*buf = line
+
+	if n, err := chain.w.Write(line); err != nil {
+		// A partly written line is told by a positive count, so that it
+		// isn't written again.
+		return partlyWritten(n, len(p)), goutils.WrapErrorf(err, "failed to write chained line")
+	}
+	chain.head = sum
+
+	return len(p), nil
+}
+
+// Head returns the hash of the last written line in hex.
+func (chain *hashChain) Head() string {
+	return hex.EncodeToString(chain.head[:])
+}
+
+// saveHead persists the head, which is called when the file is rotated or
+// closed. It's written to a temporary file and renamed, so it's never
+// partially written.
+func (chain *hashChain) saveHead(perm *FilePerm) error {
+	tmp := chain.headPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(chain.Head()+"\n"), perm.fileMode()); err != nil {
+		return goutils.WrapErrorf(err, "failed to write chain head, file: %s", tmp)
+	}
+
+	if err := os.Rename(tmp, chain.headPath); err != nil {
+		return goutils.WrapErrorf(err, "failed to rename file, src: %s, dist: %s", tmp, chain.headPath)
+	}
+
+	return nil
+}
+
+// loadHead recovers the head from the last line of the file, or from the
+// persisted head if the file is empty. A new chain starts if neither exists.
+func (chain *hashChain) loadHead(fileName string, ring *KeyRing) error {
+	var line []byte
+	var err error
+	if ring != nil {
+		line, err = lastDecryptedLine(fileName, ring)
+	} else {
+		line, err = lastLine(fileName)
+	}
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to read last line")
+	}
+
+	if len(line) != 0 {
+		_, sum, ok := splitChainedLine(line)
+		if !ok {
+			return goutils.NewErr("last line isn't hash chained, file: %s", fileName)
+		}
+		chain.head = sum
+		return nil
+	}
+
+	data, err := os.ReadFile(chain.headPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "failed to read chain head, file: %s", chain.headPath)
+	}
+
+	if err := decodeChainHash(strings.TrimSpace(string(data)), &chain.head); err != nil {
+		return goutils.WrapErrorf(err, "invalid chain head, file: %s", chain.headPath)
+	}
+
+	return nil
+}
+
+// AuditReport is the result of Verify.
+type AuditReport struct {
+	Files int
+	Lines int
+	// Hash of the last line. Truncation at the end of the chain can be
+	// detected by comparing it with a head recorded elsewhere, see
+	// VerifyWithHead.
+	Head string
+	// Number of lines after the head passed to VerifyWithHead, which can be
+	// truncated without being detected. They are the lines written after
+	// the head is persisted, e.g. since the last rotation of a running
+	// logger.
+	AfterHead int
+	// Whether the first line is chained from the start of the chain. If not,
+	// earlier logs have been removed, e.g. by the retention of backups, and
+	// the first line is trusted, so an edit of the first line can't be
+	// distinguished from the removal. Callers which keep all the logs should
+	// fail if it's false.
+	FromStart bool
+}
+
+func (report *AuditReport) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "AuditReport{files: %d, lines: %d, head: %s, fromStart: %t, afterHead: %d}",
+		report.Files, report.Lines, report.Head, report.FromStart, report.AfterHead)
+
+	return string(b.Bytes())
+}
+
+// Verify checks the hash chain of audit logs in 'paths', which are ordered
+// from the oldest, e.g. the backups and then the active file. Edits or
+// removal of lines, and removal of files in the middle are reported with
+// the position where the chain breaks.
+//
+// A successful Verify alone guarantees little: an edit of the first line,
+// or the removal of the oldest files, is only reported by FromStart, and
+// truncation at the end isn't detected at all. Only when all the files are
+// present and FromStart is required, e.g. by 'gologging verify -strict',
+// and the head is checked by VerifyWithHead, the logs up to the head are
+// guaranteed to be intact.
+func Verify(paths ...string) (*AuditReport, error) {
+	return VerifyWithKeys(nil, paths...)
+}
+
+// VerifyWithKeys verifies audit logs which are encrypted by keys in 'ring',
+// nil 'ring' means the files aren't encrypted.
+func VerifyWithKeys(ring *KeyRing, paths ...string) (*AuditReport, error) {
+	return verifyChain(ring, nil, paths)
+}
+
+// VerifyWithHead verifies as VerifyWithKeys, and detects truncation at the
+// end by 'head', the hash of a line recorded elsewhere, e.g. by
+// ReadChainHead. It fails if no line of the chain has the hash. The lines
+// after it are counted in AfterHead.
+func VerifyWithHead(ring *KeyRing, head string, paths ...string) (*AuditReport, error) {
+	var anchor [_CHAIN_HASH_SIZE]byte
+	if err := decodeChainHash(head, &anchor); err != nil {
+		return nil, goutils.WrapErrorf(err, "invalid head")
+	}
+
+	return verifyChain(ring, &anchor, paths)
+}
+
+// ReadChainHead returns the head of the chain of the log file, which is
+// persisted in '${file}.chain' when the file is rotated or closed. It's
+// empty if the head hasn't been persisted.
+func ReadChainHead(fileName string) (string, error) {
+	headPath := fileName + _CHAIN_SUFFIX
+	data, err := os.ReadFile(headPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", goutils.WrapErrorf(err, "failed to read chain head, file: %s", headPath)
+	}
+
+	var head [_CHAIN_HASH_SIZE]byte
+	if err := decodeChainHash(strings.TrimSpace(string(data)), &head); err != nil {
+		return "", goutils.WrapErrorf(err, "invalid chain head, file: %s", headPath)
+	}
+
+	return hex.EncodeToString(head[:]), nil
+}
+
+// verifyChain verifies the files, and checks that a line hashes to 'anchor'
+// if it isn't nil. The hash of 32 zero bytes is the start of the chain.
+func verifyChain(ring *KeyRing, anchor *[_CHAIN_HASH_SIZE]byte, paths []string) (*AuditReport, error) {
+	if len(paths) == 0 {
+		return nil, goutils.NewErr("no file to verify")
+	}
+
+	report := &AuditReport{}
+	var head [_CHAIN_HASH_SIZE]byte
+	h := sha256.New()
+	anchored := anchor != nil && *anchor == head
+
+	for _, path := range paths {
+		if err := verifyFile(path, ring, h, &head, anchor, &anchored, report); err != nil {
+			return report, err
+		}
+		report.Files++
+	}
+	report.Head = hex.EncodeToString(head[:])
+
+	if anchor != nil && !anchored {
+		return report, goutils.NewErr("head isn't found in the chain, it's truncated at the end, head: %s",
+			hex.EncodeToString(anchor[:]))
+	}
+
+	return report, nil
+}
+
+func verifyFile(
+	path string,
+	ring *KeyRing,
+	h hash.Hash,
+	head *[_CHAIN_HASH_SIZE]byte,
+	anchor *[_CHAIN_HASH_SIZE]byte,
+	anchored *bool,
+	report *AuditReport) error {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open file, file: %s", path)
+	}
+	defer f.Close()
+
+	var in io.Reader = f
+	if ring != nil {
+		in = NewDecryptReader(f, ring)
+	}
+	r := bufio.NewReader(in)
+	for lineNo := 1; ; lineNo++ {
+		line, err := r.ReadBytes(_NEWLINE)
+		if err == io.EOF {
+			if len(line) != 0 {
+				return goutils.NewErr("last line is truncated, file: %s, line: %d", path, lineNo)
+			}
+			return nil
+		} else if err != nil {
+			return goutils.WrapErrorf(err, "failed to read file, file: %s", path)
+		}
+
+		record, sum, ok := splitChainedLine(line[:len(line)-1])
+		if !ok {
+			return goutils.NewErr("line isn't hash chained, file: %s, line: %d", path, lineNo)
+		}
+
+		expected := chainHash(h, head[:], record)
+		if expected != sum {
+			if report.Lines != 0 {
+				return goutils.NewErr("hash chain is broken, file: %s, line: %d", path, lineNo)
+			}
+			// The first line is chained from a removed file, or it's
+			// edited, which can't be told apart, so it's trusted as the
+			// start and reported by FromStart.
+		} else if report.Lines == 0 {
+			report.FromStart = true
+		}
+
+		*head = sum
+		report.Lines++
+		if anchor != nil && sum == *anchor {
+			*anchored = true
+			report.AfterHead = 0
+		} else if *anchored {
+			report.AfterHead++
+		}
+	}
+}
+
+func chainHash(h hash.Hash, prev, record []byte) [_CHAIN_HASH_SIZE]byte {
+	var sum [_CHAIN_HASH_SIZE]byte
+
+	h.Reset()
+	h.Write(prev)
+	h.Write(record)
+	h.Sum(sum[:0])
+
+	return sum
+}
+
+// splitChainedLine splits a line without the newline into the record and
+// the hash.
+func splitChainedLine(line []byte) ([]byte, [_CHAIN_HASH_SIZE]byte, bool) {
+	var sum [_CHAIN_HASH_SIZE]byte
+
+	idx := bytes.LastIndex(line, []byte(_CHAIN_SEP))
+	if idx == -1 {
+		return nil, sum, false
+	}
+
+	if err := decodeChainHash(string(line[idx+len(_CHAIN_SEP):]), &sum); err != nil {
+		return nil, sum, false
+	}
+
+	return line[:idx], sum, true
+}
+
+func decodeChainHash(s string, sum *[_CHAIN_HASH_SIZE]byte) error {
+	if hex.DecodedLen(len(s)) != _CHAIN_HASH_SIZE {
+		return goutils.NewErr("invalid length of hash: %s", s)
+	}
+
+	if _, err := hex.Decode(sum[:], []byte(s)); err != nil {
+		return goutils.WrapErrorf(err, "invalid hash: %s", s)
+	}
+
+	return nil
+}
+
+// lastLine returns the last line of the file without the newline, or nil
+// if the file is empty or doesn't exist. The file is read backwards by
+// chunks, so large files are not read entirely.
+func lastLine(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to open file, file: %s", path)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to stat file, file: %s", path)
+	}
+
+	end := info.Size()
+	tail := make([]byte, 0)
+	for off := end; off > 0; {
+		n := int64(_TAIL_CHUNK_SIZE)
+		if n > off {
+			n = off
+		}
+		off -= n
+
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, off); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to read file, file: %s", path)
+		}
+		tail = append(chunk, tail...)
+
+		// Skip the newline at the end of the file
+		if idx := bytes.LastIndexByte(bytes.TrimSuffix(tail, []byte{_NEWLINE}), _NEWLINE); idx != -1 {
+			tail = tail[idx+1:]
+			break
+		}
+	}
+
+	return bytes.TrimSuffix(tail, []byte{_NEWLINE}), nil
+}
@@ -0,0 +1,126 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 18:02:51
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newAuditHandler(t *testing.T, fileName string) *SizeRotateFileHandler {
+	handler, err := NewSizeRotateFileHandler(fileName, MB, 10)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+
+	if err := handler.EnableHashChain(); err != nil {
+		t.Fatalf("failed to enable hash chain, err: %s", err.Error())
+	}
+
+	return handler
+}
+
+func TestHashChain(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "audit.log")
+	handler := newAuditHandler(t, fileName)
+
+	msg := newTestMsg()
+	msg.message = []byte("multi\nline")
+	for i := 0; i < 3; i++ {
+		if err := handler.Handle(msg); err != nil {
+			t.Fatalf("failed to handle, err: %s", err.Error())
+		}
+		if _, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE); err != nil {
+			t.Fatalf("failed to rotate, err: %s", err.Error())
+		}
+	}
+	handler.Close()
+
+	// The chain continues from the persisted head as the file is empty
+	handler = newAuditHandler(t, fileName)
+	handler.Handle(newTestMsg())
+	handler.Close()
+
+	paths, err := LogFiles(fileName, DEFAULT_SIZE_BACKUP_PATTERN)
+	if err != nil || len(paths) != 4 {
+		t.Fatalf("failed to list log files, paths: %v, err: %v", paths, err)
+	}
+
+	report, err := Verify(paths...)
+	if err != nil {
+		t.Fatalf("failed to verify, err: %s", err.Error())
+	}
+	if report.Files != 4 || report.Lines != 4 || !report.FromStart {
+		t.Errorf("unexpected report: %s", report.String())
+	}
+
+	// The oldest file is removed by retention
+	if report, err := Verify(paths[1:]...); err != nil || report.FromStart {
+		t.Errorf("removal of the oldest file should be tolerated, report: %v, err: %v", report, err)
+	}
+
+	// A file in the middle is removed
+	if _, err := Verify(paths[0], paths[2], paths[3]); err == nil {
+		t.Errorf("removal of a file should be detected")
+	}
+
+	// The first line is edited, which is only detected by FromStart
+	first, _ := os.ReadFile(paths[0])
+	os.WriteFile(paths[0], bytes.Replace(first, []byte("multi"), []byte("mUlti"), 1), _OPEN_FILE_MODE)
+	if report, err := Verify(paths...); err != nil || report.FromStart || report.Lines != 4 {
+		t.Errorf("edit of the first line should be reported by FromStart, report: %v, err: %v", report, err)
+	}
+	os.WriteFile(paths[0], first, _OPEN_FILE_MODE)
+
+	// A line is edited
+	data, _ := os.ReadFile(paths[1])
+	os.WriteFile(paths[1], bytes.Replace(data, []byte("multi"), []byte("mUlti"), 1), _OPEN_FILE_MODE)
+	if _, err := Verify(paths...); err == nil {
+		t.Errorf("edit of a line should be detected")
+	}
+}
+
+func TestVerifyHead(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "audit.log")
+	handler := newAuditHandler(t, fileName)
+	for i := 0; i < 3; i++ {
+		handler.Handle(newTestMsg())
+	}
+	handler.Close()
+
+	head, err := ReadChainHead(fileName)
+	if err != nil || head == "" {
+		t.Fatalf("failed to read chain head, head: %s, err: %v", head, err)
+	}
+	report, err := VerifyWithHead(nil, head, fileName)
+	if err != nil || report.Head != head || report.AfterHead != 0 {
+		t.Fatalf("failed to verify with head, report: %v, err: %v", report, err)
+	}
+
+	// Lines written after the head is persisted are counted
+	handler = newAuditHandler(t, fileName)
+	handler.Handle(newTestMsg())
+	if report, err := VerifyWithHead(nil, head, fileName); err != nil || report.AfterHead != 1 {
+		t.Errorf("unexpected report: %v, err: %v", report, err)
+	}
+	handler.Close()
+
+	// The last lines are truncated
+	head, _ = ReadChainHead(fileName)
+	data, _ := os.ReadFile(fileName)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	os.WriteFile(fileName, bytes.Join(lines[:len(lines)-2], nil), _OPEN_FILE_MODE)
+	if _, err := Verify(fileName); err != nil {
+		t.Errorf("truncation isn't detected without head, err: %v", err)
+	}
+	if _, err := VerifyWithHead(nil, head, fileName); err == nil {
+		t.Errorf("truncation at the end should be detected")
+	}
+}
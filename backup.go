@@ -0,0 +1,413 @@
+/**
+ * Naming and discovery of the backups of rotated log files.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 13:48:06
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Placeholders supported in the backup name pattern:
+//	{file}: base name of the log file, e.g. 'app.log'
+//	{name}: base name of the log file without extension, e.g. 'app'
+//	{ext}: extension of the log file, e.g. '.log'
+//	{date:LAYOUT}: rotation time formatted by the layout of time.Format,
+//				   e.g. {date:20060102}
+//	{seq} or {seq:WIDTH}: sequence number padded with zeros to WIDTH digits,
+//				   4 by default
+// A pattern must include {date:LAYOUT} or {seq}. If a pattern without {seq}
+// names a backup which already exists, e.g. the file is rotated twice in the
+// same minute by {date:200601021504}, the backup is suffixed by '.N' from 2,
+// e.g. 'app.log_202610191504.2', so that the existing one isn't overwritten.
+const (
+	DEFAULT_TIME_BACKUP_PATTERN = "{file}_{date:200601021504}"
+	DEFAULT_SIZE_BACKUP_PATTERN = "{file}_{seq}"
+
+	_PLACEHOLDER_LEFT  = '{'
+	_PLACEHOLDER_RIGHT = '}'
+	_FILE_PLACEHOLDER  = "file"
+	_NAME_PLACEHOLDER  = "name"
+	_EXT_PLACEHOLDER   = "ext"
+	_DATE_PLACEHOLDER  = "date"
+	_SEQ_PLACEHOLDER   = "seq"
+	_DEFAULT_SEQ_WIDTH = 4
+	_COLLISION_SEP     = "."
+)
+
+type _NameSegment struct {
+	literal string
+	layout  string // layout of {date}
+	width   int    // width of {seq}
+	isDate  bool
+	isSeq   bool
+}
+
+// backupNamer generates names of backups by the pattern, and finds the
+// backups of a log file by matching names with the pattern.
+type backupNamer struct {
+	pattern  string
+	dir      string
+	segments []_NameSegment
+	re       *regexp.Regexp
+	hasDate  bool
+	hasSeq   bool
+}
+
+// A backup of the log file.
+type backupFile struct {
+	path string
+	date string // formatted rotation time, empty if pattern has no {date}
+	time time.Time
+	seq  int
+}
+
+func newBackupNamer(pattern, fileName string) (*backupNamer, error) {
+	if strings.ContainsRune(pattern, filepath.Separator) {
+		return nil, goutils.NewErr("backup pattern can't include path separator, pattern: %s", pattern)
+	}
+
+	base := filepath.Base(fileName)
+	ext := filepath.Ext(base)
+	namer := &backupNamer{pattern: pattern, dir: filepath.Dir(fileName)}
+	reBuf := bytes.Buffer{}
+	reBuf.WriteString("^")
+
+	rest := pattern
+	for len(rest) != 0 {
+		left := strings.IndexByte(rest, _PLACEHOLDER_LEFT)
+		if left == -1 {
+			namer.addLiteral(rest, &reBuf)
+			break
+		}
+		namer.addLiteral(rest[:left], &reBuf)
+
+		right := strings.IndexByte(rest[left:], _PLACEHOLDER_RIGHT)
+		if right == -1 {
+			return nil, goutils.NewErr("placeholder isn't closed by '}', pattern: %s", pattern)
+		}
+		placeholder := rest[left+1 : left+right]
+		rest = rest[left+right+1:]
+
+		name, arg := placeholder, ""
+		if idx := strings.IndexByte(placeholder, ':'); idx != -1 {
+			name, arg = placeholder[:idx], placeholder[idx+1:]
+		}
+
+		switch name {
+		case _FILE_PLACEHOLDER:
+			namer.addLiteral(base, &reBuf)
+		case _NAME_PLACEHOLDER:
+			namer.addLiteral(strings.TrimSuffix(base, ext), &reBuf)
+		case _EXT_PLACEHOLDER:
+			namer.addLiteral(ext, &reBuf)
+		case _DATE_PLACEHOLDER:
+			if arg == "" || namer.hasDate {
+				return nil, goutils.NewErr("{date} needs a layout and can only be used once, pattern: %s",
+					pattern)
+			}
+			namer.segments = append(namer.segments, _NameSegment{layout: arg, isDate: true})
+			namer.hasDate = true
+			reBuf.WriteString("(" + layoutRegexp(arg) + ")")
+		case _SEQ_PLACEHOLDER:
+			width := _DEFAULT_SEQ_WIDTH
+			if arg != "" {
+				var err error
+				if width, err = strconv.Atoi(arg); err != nil || width <= 0 {
+					return nil, goutils.NewErr("invalid width of {seq}, pattern: %s", pattern)
+				}
+			}
+			if namer.hasSeq {
+				return nil, goutils.NewErr("{seq} can only be used once, pattern: %s", pattern)
+			}
+			namer.segments = append(namer.segments, _NameSegment{width: width, isSeq: true})
+			namer.hasSeq = true
+			reBuf.WriteString(`(\d+)`)
+		default:
+			return nil, goutils.NewErr("unknown placeholder: {%s}, pattern: %s", placeholder, pattern)
+		}
+	}
+	if !namer.hasSeq {
+		reBuf.WriteString(`(?:` + regexp.QuoteMeta(_COLLISION_SEP) + `(\d+))?`)
+	}
+	reBuf.WriteString("$")
+
+	if !namer.hasDate && !namer.hasSeq {
+		return nil, goutils.NewErr("backup pattern must include {date:LAYOUT} or {seq}, pattern: %s",
+			pattern)
+	}
+	if namer.Name(time.Now(), 1) == base {
+		return nil, goutils.NewErr("backup name is the same as the log file, pattern: %s", pattern)
+	}
+
+	re, err := regexp.Compile(string(reBuf.Bytes()))
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to compile backup pattern, pattern: %s", pattern)
+	}
+	namer.re = re
+
+	return namer, nil
+}
+
+func (namer *backupNamer) addLiteral(literal string, reBuf *bytes.Buffer) {
+	if literal == "" {
+		return
+	}
+
+	namer.segments = append(namer.segments, _NameSegment{literal: literal})
+	reBuf.WriteString(regexp.QuoteMeta(literal))
+}
+
+// Name returns the base name of the backup rotated at 't' with sequence
+// number 'seq'.
+func (namer *backupNamer) Name(t time.Time, seq int) string {
+	b := bytes.Buffer{}
+	for _, seg := range namer.segments {
+		switch {
+		case seg.isDate:
+			b.WriteString(t.Format(seg.layout))
+		case seg.isSeq:
+			fmt.Fprintf(&b, "%0*d", seg.width, seq)
+		default:
+			b.WriteString(seg.literal)
+		}
+	}
+	if !namer.hasSeq && seq > 1 {
+		fmt.Fprintf(&b, "%s%d", _COLLISION_SEP, seq)
+	}
+
+	return string(b.Bytes())
+}
+
+// Path returns the full path of the backup.
+func (namer *backupNamer) Path(t time.Time, seq int) string {
+	return filepath.Join(namer.dir, namer.Name(t, seq))
+}
+
+// parse returns the backup if 'name' matches the pattern.
+func (namer *backupNamer) parse(name string) (backupFile, bool) {
+	matches := namer.re.FindStringSubmatch(name)
+	if matches == nil {
+		return backupFile{}, false
+	}
+
+	backup := backupFile{path: filepath.Join(namer.dir, name)}
+	group := 1
+	for _, seg := range namer.segments {
+		switch {
+		case seg.isDate:
+			t, err := time.ParseInLocation(seg.layout, matches[group], time.Local)
+			if err != nil {
+				return backupFile{}, false
+			}
+			backup.date, backup.time = matches[group], t
+			group++
+		case seg.isSeq:
+			seq, err := strconv.Atoi(matches[group])
+			if err != nil {
+				return backupFile{}, false
+			}
+			backup.seq = seq
+			group++
+		}
+	}
+	if !namer.hasSeq {
+		// Backups without the collision suffix are the first ones
+		backup.seq = 1
+		if matches[group] != "" {
+			seq, err := strconv.Atoi(matches[group])
+			if err != nil {
+				return backupFile{}, false
+			}
+			backup.seq = seq
+		}
+	}
+
+	return backup, true
+}
+
+// List returns all the backups in the directory of the log file, the oldest
+// first.
+func (namer *backupNamer) List() ([]backupFile, error) {
+	return namer.list()
+}
+
+// list returns the backups whose names match the pattern, or match it
+// followed by one of 'suffixes', e.g. '.gz' of compressed backups.
+func (namer *backupNamer) list(suffixes ...string) ([]backupFile, error) {
+	entries, err := os.ReadDir(namer.dir)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to read dir, dir: %s", namer.dir)
+	}
+
+	backups := make([]backupFile, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(name, suffix) {
+				name = strings.TrimSuffix(name, suffix)
+				break
+			}
+		}
+		if backup, ok := namer.parse(name); ok {
+			backup.path = filepath.Join(namer.dir, entry.Name())
+			backups = append(backups, backup)
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.Before(backups[j].time)
+		}
+		return backups[i].seq < backups[j].seq
+	})
+
+	return backups, nil
+}
+
+// LogFiles returns the backups of the log file named by 'backupPattern' from
+// the oldest, followed by the log file if it exists. Empty pattern means
+// DEFAULT_TIME_BACKUP_PATTERN.
+func LogFiles(fileName, backupPattern string) ([]string, error) {
+	if backupPattern == "" {
+		backupPattern = DEFAULT_TIME_BACKUP_PATTERN
+	}
+
+	namer, err := newBackupNamer(backupPattern, fileName)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse backup pattern")
+	}
+
+	backups, err := namer.List()
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to list backups")
+	}
+
+	paths := make([]string, 0, len(backups)+1)
+	for _, backup := range backups {
+		paths = append(paths, backup.path)
+	}
+
+	if _, err := os.Stat(fileName); err == nil {
+		paths = append(paths, fileName)
+	} else if !os.IsNotExist(err) {
+		return nil, goutils.WrapErrorf(err, "failed to stat file, file: %s", fileName)
+	}
+
+	return paths, nil
+}
+
+// Elements of the layouts of time.Format and the regexps matching them.
+// Longer elements are put before their prefixes, e.g. '2006' before '2'.
+var layoutElements = []struct {
+	element string
+	re      string
+}{
+	{"January", `[A-Za-z]+`},
+	{"Jan", `[A-Za-z]{3}`},
+	{"Monday", `[A-Za-z]+`},
+	{"Mon", `[A-Za-z]{3}`},
+	{"MST", `(?:[A-Za-z]{3,5}|[+-]\d{2,4})`},
+	{"2006", `\d{4}`},
+	{"__2", `[ \d]{2}\d`},
+	{"_2", `[ \d]\d`},
+	{"002", `\d{3}`},
+	{"01", `\d{2}`},
+	{"02", `\d{2}`},
+	{"03", `\d{2}`},
+	{"04", `\d{2}`},
+	{"05", `\d{2}`},
+	{"06", `\d{2}`},
+	{"15", `\d{2}`},
+	{"1", `\d{1,2}`},
+	{"2", `\d{1,2}`},
+	{"3", `\d{1,2}`},
+	{"4", `\d{1,2}`},
+	{"5", `\d{1,2}`},
+	{"PM", `(?:AM|PM)`},
+	{"pm", `(?:am|pm)`},
+	{"Z07:00:00", `(?:Z|[+-]\d{2}:\d{2}:\d{2})`},
+	{"-07:00:00", `[+-]\d{2}:\d{2}:\d{2}`},
+	{"Z070000", `(?:Z|[+-]\d{6})`},
+	{"-070000", `[+-]\d{6}`},
+	{"Z07:00", `(?:Z|[+-]\d{2}:\d{2})`},
+	{"-07:00", `[+-]\d{2}:\d{2}`},
+	{"Z0700", `(?:Z|[+-]\d{4})`},
+	{"-0700", `[+-]\d{4}`},
+	{"Z07", `(?:Z|[+-]\d{2})`},
+	{"-07", `[+-]\d{2}`},
+}
+
+// layoutRegexp converts a layout of time.Format to a regexp, each element
+// of the layout matches the values it's formatted to, e.g. '2' matches
+// '2' and '12', and others match themselves.
+func layoutRegexp(layout string) string {
+	b := bytes.Buffer{}
+	for len(layout) != 0 {
+		if n := fractionLen(layout); n != 0 {
+			// '.000' is formatted to exactly 3 digits, '.999' to at most 3
+			// digits without trailing zeros, and nothing if it's zero.
+			if layout[1] == '0' {
+				fmt.Fprintf(&b, `[.,]\d{%d}`, n-1)
+			} else {
+				fmt.Fprintf(&b, `(?:[.,]\d{1,%d})?`, n-1)
+			}
+			layout = layout[n:]
+			continue
+		}
+
+		matched := false
+		for _, elem := range layoutElements {
+			if strings.HasPrefix(layout, elem.element) {
+				b.WriteString(elem.re)
+				layout = layout[len(elem.element):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			_, size := utf8.DecodeRuneInString(layout)
+			b.WriteString(regexp.QuoteMeta(layout[:size]))
+			layout = layout[size:]
+		}
+	}
+
+	return string(b.Bytes())
+}
+
+// fractionLen returns the length of the fractional second at the start of
+// the layout, e.g. '.000' or ',999', or 0 if there isn't one. As
+// time.Format, it isn't followed by a digit.
+func fractionLen(layout string) int {
+	if len(layout) < 2 || (layout[0] != '.' && layout[0] != ',') ||
+		(layout[1] != '0' && layout[1] != '9') {
+		return 0
+	}
+
+	n := 2
+	for n < len(layout) && layout[n] == layout[1] {
+		n++
+	}
+	if n < len(layout) && layout[n] >= '0' && layout[n] <= '9' {
+		return 0
+	}
+
+	return n
+}
@@ -0,0 +1,211 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 14:20:33
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupNamer(t *testing.T) {
+	namer, err := newBackupNamer("{name}-{date:20060102}-{seq:2}{ext}", "/var/log/app.log")
+	if err != nil {
+		t.Fatalf("failed to create namer, err: %s", err.Error())
+	}
+
+	now := time.Date(2026, 10, 18, 14, 20, 33, 0, time.Local)
+	if name := namer.Name(now, 3); name != "app-20261018-03.log" {
+		t.Errorf("unexpected backup name: %s", name)
+	}
+
+	backup, ok := namer.parse("app-20261018-03.log")
+	if !ok || backup.seq != 3 || backup.date != "20261018" {
+		t.Errorf("failed to parse backup name, backup: %+v", backup)
+	}
+
+	for _, name := range []string{"app.log", "app-2026101-03.log", "app-20261018-03.log.gz"} {
+		if _, ok := namer.parse(name); ok {
+			t.Errorf("name shouldn't match the pattern, name: %s", name)
+		}
+	}
+
+	for _, pattern := range []string{"{file}.bak", "{file}_{unknown}", "{file}_{seq", "{file}"} {
+		if _, err := newBackupNamer(pattern, "app.log"); err == nil {
+			t.Errorf("pattern should be rejected, pattern: %s", pattern)
+		}
+	}
+}
+
+// Unpadded and word elements of layouts match values of any width.
+func TestBackupNamerLayout(t *testing.T) {
+	times := []time.Time{
+		time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local),
+		time.Date(2026, 10, 18, 14, 20, 33, 120000000, time.Local),
+	}
+	layouts := []string{"2006-1-2_3-4-5PM", "Jan_2.15", "Monday-January-2", "20060102.150405.000",
+		"060102T150405.999", "2006.002"}
+	for _, layout := range layouts {
+		namer, err := newBackupNamer("{file}_{date:"+layout+"}", "app.log")
+		if err != nil {
+			t.Fatalf("failed to create namer, layout: %s, err: %s", layout, err.Error())
+		}
+
+		for _, now := range times {
+			name := namer.Name(now, 0)
+			backup, ok := namer.parse(name)
+			if !ok {
+				t.Errorf("backup name doesn't match the pattern, layout: %s, name: %s", layout, name)
+				continue
+			}
+			if backup.date != now.Format(layout) {
+				t.Errorf("unexpected date of backup, layout: %s, backup: %+v", layout, backup)
+			}
+		}
+	}
+}
+
+func TestRotateFile(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewSizeRotateFileHandler(filepath.Join(dir, "app.log"), MB, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	if err := handler.SetSymlink("current"); err != nil {
+		t.Fatalf("failed to set symlink, err: %s", err.Error())
+	}
+
+	events := make(chan RotateEvent, 3)
+	handler.OnRotate(func(event RotateEvent) {
+		events <- event
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE); err != nil {
+			t.Fatalf("failed to rotate, err: %s", err.Error())
+		}
+	}
+
+	event := <-events
+	if event.Reason != ROTATE_BY_SIZE || event.Path != filepath.Join(dir, "app.log") ||
+		filepath.Base(event.BackupPath) != "app.log_0001" {
+		t.Errorf("unexpected rotate event: %s", event.String())
+	}
+
+	backups, err := handler.namer.List()
+	if err != nil {
+		t.Fatalf("failed to list backups, err: %s", err.Error())
+	}
+	if len(backups) != 2 || filepath.Base(backups[0].path) != "app.log_0001" ||
+		filepath.Base(backups[1].path) != "app.log_0002" {
+		t.Errorf("unexpected backups: %+v", backups)
+	}
+
+	if target, err := os.Readlink(filepath.Join(dir, "current")); err != nil || target != "app.log" {
+		t.Errorf("unexpected symlink target: %s, err: %v", target, err)
+	}
+}
+
+func TestRotateOnRestart(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(fileName, []byte("yesterday\n"), _OPEN_FILE_MODE); err != nil {
+		t.Fatalf("failed to write file, err: %s", err.Error())
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(fileName, yesterday, yesterday); err != nil {
+		t.Fatalf("failed to change mtime, err: %s", err.Error())
+	}
+
+	handler, err := NewTimeRotateFileHandler(fileName, DAY, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	if !handler.shouldRotate(time.Now()) {
+		t.Errorf("file written before the last boundary should be rotated, next rotate: %s",
+			handler.nextRotate)
+	}
+	if handler.shouldRotate(time.Now()) {
+		t.Errorf("file shouldn't be rotated twice, next rotate: %s", handler.nextRotate)
+	}
+}
+
+func TestRotateOnStart(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+
+	handler, err := NewSizeRotateFileHandler(fileName, MB, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	// An empty file isn't archived
+	if err := handler.RotateOnStart(); err != nil {
+		t.Fatalf("failed to rotate on start, err: %s", err.Error())
+	}
+	if backups, _ := handler.namer.List(); len(backups) != 0 {
+		t.Errorf("empty file shouldn't be archived, backups: %+v", backups)
+	}
+
+	if _, err := handler.file.WriteString("last run\n"); err != nil {
+		t.Fatalf("failed to write file, err: %s", err.Error())
+	}
+	if err := handler.RotateOnStart(); err != nil {
+		t.Fatalf("failed to rotate on start, err: %s", err.Error())
+	}
+	if backups, _ := handler.namer.List(); len(backups) != 1 {
+		t.Errorf("existing file should be archived, backups: %+v", backups)
+	}
+	if handler.curBytes != 0 {
+		t.Errorf("size should be reset after rotation, size: %d", handler.curBytes)
+	}
+}
+
+func TestRotateInSameMinute(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewTimeRotateFileHandler(filepath.Join(dir, "app.log"), MINUTE, 5)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	now := time.Date(2026, 10, 19, 15, 4, 5, 0, time.Local)
+	for _, content := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := handler.file.WriteString(content); err != nil {
+			t.Fatalf("failed to write file, err: %s", err.Error())
+		}
+		if _, err := handler.rotateFile(now, handler.backupCount, ROTATE_BY_TIME); err != nil {
+			t.Fatalf("failed to rotate, err: %s", err.Error())
+		}
+	}
+
+	// Backups of the same minute are suffixed instead of overwritten
+	for name, content := range map[string]string{
+		"app.log_202610191504":   "first\n",
+		"app.log_202610191504.2": "second\n",
+		"app.log_202610191504.3": "third\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("unexpected backup %s: %q, err: %v", name, data, err)
+		}
+	}
+
+	backups, err := handler.namer.List()
+	if err != nil {
+		t.Fatalf("failed to list backups, err: %s", err.Error())
+	}
+	if len(backups) != 3 || backups[2].seq != 3 {
+		t.Errorf("unexpected backups: %+v", backups)
+	}
+}
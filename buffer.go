@@ -0,0 +1,277 @@
+/**
+ * Buffered writer used by file handlers to batch log writes.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 10:12:40
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy of calling fsync on a buffered file.
+type FsyncPolicy int
+
+const (
+	FSYNC_NEVER     FsyncPolicy = iota // never fsync, leave it to the OS
+	FSYNC_PER_FLUSH                    // fsync after each flush of the buffer
+	FSYNC_INTERVAL                     // fsync every 'FsyncInterval'
+	FSYNC_ON_ERROR                     // flush and fsync when a log of ERROR or above is written
+)
+
+const (
+	_DEFAULT_BUFFER_SIZE    = 64 * 1024
+	_DEFAULT_FLUSH_INTERVAL = time.Second
+	_DEFAULT_FSYNC_INTERVAL = time.Second
+)
+
+var fsyncPolicyNames = []string{
+	"never",
+	"flush",
+	"interval",
+	"error",
+}
+
+func NewFsyncPolicyString(name string) (FsyncPolicy, error) {
+	for idx, policyName := range fsyncPolicyNames {
+		if policyName == strings.ToLower(name) {
+			return FsyncPolicy(idx), nil
+		}
+	}
+
+	return FSYNC_NEVER, goutils.NewErr("unknown fsync policy: %s", name)
+}
+
+func (policy FsyncPolicy) Name() string {
+	if policy >= FSYNC_NEVER && int(policy) < len(fsyncPolicyNames) {
+		return fsyncPolicyNames[policy]
+	}
+
+	return "UNKNOWN_FSYNC_POLICY"
+}
+
+// Config of the buffer of a file handler.
+type BufferConfig struct {
+	// Size of the buffer in bytes. The buffer is flushed when it's full.
+	Size int
+	// The buffer is flushed by a background goroutine every 'FlushInterval'.
+	FlushInterval time.Duration
+	Fsync         FsyncPolicy
+	// Only used by FSYNC_INTERVAL.
+	FsyncInterval time.Duration
+}
+
+func (config *BufferConfig) setDefault() {
+	if config.Size <= 0 {
+		config.Size = _DEFAULT_BUFFER_SIZE
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = _DEFAULT_FLUSH_INTERVAL
+	}
+	if config.Fsync == FSYNC_INTERVAL && config.FsyncInterval <= 0 {
+		config.FsyncInterval = _DEFAULT_FSYNC_INTERVAL
+	}
+}
+
+// bufferedWriter buffers the writes to a file. As the buffer is flushed both
+// by the goroutine of handlerLoop and the background flush goroutine, all the
+// operations are protected by 'mu'.
+type bufferedWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	out  io.Writer
+	// Data not flushed yet, whose capacity is the size of the buffer. The
+	// data failed to be written is kept and flushed again by the next
+	// flush, so a transient error, e.g. ENOSPC, doesn't fail the following
+	// writes as bufio.Writer does.
+	buf      []byte
+	config   BufferConfig
+	lastSync time.Time
+	stop     chan byte
+	done     chan byte
+}
+
+// newBufferedWriter creates a buffer which is flushed to 'out', which is
+// the file or a writer of the file, e.g. encryptedWriter. 'file' is used
+// to fsync.
+func newBufferedWriter(file *os.File, out io.Writer, config BufferConfig) *bufferedWriter {
+	config.setDefault()
+
+	w := &bufferedWriter{
+		file:     file,
+		out:      out,
+		buf:      make([]byte, 0, config.Size),
+		config:   config,
+		lastSync: time.Now(),
+		stop:     make(chan byte),
+		done:     make(chan byte),
+	}
+	go w.flushLoop()
+
+	return w
+}
+
+// Write writes a log to the buffer. The buffer is flushed before the log
+// if there isn't enough space, so that a log is never split into two writes
+// of the file unless it's larger than the buffer.
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(p) > cap(w.buf)-len(w.buf) && len(w.buf) > 0 {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	// A log larger than the buffer is written directly
+	if len(p) > cap(w.buf) {
+		return w.out.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+
+	return len(p), nil
+}
+
+func (w *bufferedWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.buf)
+}
+
+// Flush writes the buffered data to the file, and fsync the file if
+// the policy is FSYNC_PER_FLUSH.
+func (w *bufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.flush()
+}
+
+// Sync flushes the buffer and fsync the file.
+func (w *bufferedWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.writeBuffer(); err != nil {
+		return err
+	}
+
+	return w.sync()
+}
+
+// Reset flushes the buffered data to the current file, and make the
+// following writes go to 'out' of 'file'. It's used when the file is
+// rotated. The data failed to be flushed is kept and written to the new
+// file, instead of being lost.
+func (w *bufferedWriter) Reset(file *os.File, out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.flush()
+	w.file = file
+	w.out = out
+
+	return err
+}
+
+// Close stops the background goroutine and flushes the buffer.
+// The file is not closed, which is owned by the handler.
+func (w *bufferedWriter) Close() error {
+	close(w.stop)
+	<-w.done
+
+	return w.Flush()
+}
+
+func (w *bufferedWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	if err := w.writeBuffer(); err != nil {
+		return err
+	}
+
+	if w.config.Fsync == FSYNC_PER_FLUSH {
+		return w.sync()
+	}
+
+	return nil
+}
+
+// writeBuffer writes the buffered data to 'out', the data not written is
+// kept in the buffer.
+func (w *bufferedWriter) writeBuffer() error {
+	n, err := w.out.Write(w.buf)
+	w.buf = w.buf[:copy(w.buf, w.buf[n:])]
+	if err == nil && len(w.buf) > 0 {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to flush buffer")
+	}
+
+	return nil
+}
+
+func (w *bufferedWriter) sync() error {
+	w.lastSync = time.Now()
+	if err := w.file.Sync(); err != nil {
+		return goutils.WrapErrorf(err, "failed to sync file")
+	}
+
+	return nil
+}
+
+func (w *bufferedWriter) flushLoop() {
+	defer close(w.done)
+
+	flushTicker := time.NewTicker(w.config.FlushInterval)
+	defer flushTicker.Stop()
+
+	// A nil channel blocks forever, so the case is disabled when
+	// fsync is not done periodically.
+	var syncC <-chan time.Time
+	if w.config.Fsync == FSYNC_INTERVAL {
+		syncTicker := time.NewTicker(w.config.FsyncInterval)
+		defer syncTicker.Stop()
+		syncC = syncTicker.C
+	}
+
+	for {
+		select {
+		case <-w.stop:
+			return
+
+		case <-flushTicker.C:
+			if err := w.Flush(); err != nil {
+				reportErr("failed to flush", err)
+			}
+
+		case <-syncC:
+			if err := w.Sync(); err != nil {
+				reportErr("failed to sync", err)
+			}
+		}
+	}
+}
+
+func (w *bufferedWriter) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "Buffer{size: %d, flushInterval: %s, fsync: %s, fsyncInterval: %s}",
+		w.config.Size, w.config.FlushInterval, w.config.Fsync.Name(), w.config.FsyncInterval)
+
+	return string(b.Bytes())
+}
@@ -0,0 +1,245 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 18:20:36
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file, err: %v", err)
+	}
+
+	return string(data)
+}
+
+// waitFile waits for the content of the file to be 'expected'.
+func waitFile(t *testing.T, path, expected string) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		content := readFile(t, path)
+		if content == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected file content: %q, expected: %q", content, expected)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newBufferedFile(t *testing.T, config BufferConfig) (*bufferedWriter, string) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file, err: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	w := newBufferedWriter(file, file, config)
+	t.Cleanup(func() { w.Close() })
+
+	return w, path
+}
+
+func (w *bufferedWriter) lastSyncTime() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lastSync
+}
+
+func TestBufferFlushBySize(t *testing.T) {
+	w, path := newBufferedFile(t, BufferConfig{Size: 16, FlushInterval: time.Hour})
+
+	w.Write([]byte("012345678\n"))
+	if content := readFile(t, path); content != "" {
+		t.Fatalf("log is written before the buffer is full: %q", content)
+	}
+
+	// The buffered log is flushed before a log which doesn't fit, so that
+	// it's never split.
+	w.Write([]byte("abcdefghi\n"))
+	if content := readFile(t, path); content != "012345678\n" || w.Buffered() != 10 {
+		t.Fatalf("unexpected file content: %q, buffered: %d", content, w.Buffered())
+	}
+
+	// A log larger than the buffer is written directly
+	w.Write([]byte(strings.Repeat("x", 32) + "\n"))
+	if content := readFile(t, path); content != "012345678\nabcdefghi\n"+strings.Repeat("x", 32)+"\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+}
+
+func TestBufferFlushByInterval(t *testing.T) {
+	w, path := newBufferedFile(t, BufferConfig{Size: 1024, FlushInterval: 10 * time.Millisecond})
+
+	w.Write([]byte("flushed by timer\n"))
+	waitFile(t, path, "flushed by timer\n")
+}
+
+func TestFsyncPolicy(t *testing.T) {
+	cases := []struct {
+		policy FsyncPolicy
+		synced bool
+	}{
+		{FSYNC_NEVER, false},
+		{FSYNC_PER_FLUSH, true},
+	}
+	for _, c := range cases {
+		w, _ := newBufferedFile(t, BufferConfig{Size: 1024, FlushInterval: time.Hour, Fsync: c.policy})
+		before := w.lastSyncTime()
+		time.Sleep(time.Millisecond)
+
+		w.Write([]byte("log\n"))
+		if err := w.Flush(); err != nil {
+			t.Fatalf("failed to flush, err: %v", err)
+		}
+		if synced := w.lastSyncTime().After(before); synced != c.synced {
+			t.Fatalf("unexpected fsync of policy %s: %t", c.policy.Name(), synced)
+		}
+	}
+
+	// Fsync by the background goroutine
+	w, _ := newBufferedFile(t, BufferConfig{Size: 1024, FlushInterval: time.Hour, Fsync: FSYNC_INTERVAL,
+		FsyncInterval: 5 * time.Millisecond})
+	before := w.lastSyncTime()
+	deadline := time.Now().Add(2 * time.Second)
+	for !w.lastSyncTime().After(before) {
+		if time.Now().After(deadline) {
+			t.Fatalf("file isn't synced by interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Logs of ERROR flush the buffer
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, err := NewFileHandler(path)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %v", err)
+	}
+	defer handler.Close()
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+	handler.EnableBuffer(BufferConfig{Size: 1024, FlushInterval: time.Hour, Fsync: FSYNC_ON_ERROR})
+
+	handler.Handle(&_Msg{level: INFO, message: []byte("info")})
+	if content := readFile(t, path); content != "" {
+		t.Fatalf("log of INFO is flushed: %q", content)
+	}
+	handler.Handle(&_Msg{level: ERROR, message: []byte("error")})
+	if content := readFile(t, path); content != "info\nerror\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+}
+
+func TestBufferRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	handler, err := NewSizeRotateFileHandler(path, 20, 5)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %v", err)
+	}
+	defer handler.Close()
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+	handler.EnableBuffer(BufferConfig{Size: 1024, FlushInterval: time.Hour})
+
+	for _, msg := range []string{"aaaaaaaa", "bbbbbbbb", "cccccccc"} {
+		handler.Handle(&_Msg{level: INFO, message: []byte(msg)})
+	}
+
+	// The buffer is flushed to the file before it's renamed
+	backups, _ := filepath.Glob(path + "_*")
+	if len(backups) != 1 {
+		t.Fatalf("unexpected backups: %v", backups)
+	}
+	if content := readFile(t, backups[0]); content != "aaaaaaaa\nbbbbbbbb\n" {
+		t.Fatalf("unexpected backup content: %q", content)
+	}
+	if content := readFile(t, path); content != "" {
+		t.Fatalf("log is flushed before the buffer is full: %q", content)
+	}
+}
+
+func TestBufferClose(t *testing.T) {
+	dir := t.TempDir()
+	newBufferedLogger := func(name string) *Logger {
+		handler, err := NewFileHandler(filepath.Join(dir, name+".log"))
+		if err != nil {
+			t.Fatalf("failed to create handler, err: %v", err)
+		}
+		formatter, _ := NewFormatter("${message}")
+		handler.SetFormatter(formatter)
+		handler.SetSyncMode(true)
+		handler.EnableBuffer(BufferConfig{Size: 1024, FlushInterval: time.Hour})
+
+		logger := newLogger(name, false)
+		logger.AddHandler(handler)
+		return logger
+	}
+
+	// Close of the logger
+	logger := newBufferedLogger("a")
+	logger.Info("closed")
+	logger.Close()
+	if content := readFile(t, filepath.Join(dir, "a.log")); content != "closed\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+
+	// Loggers replaced by Load with 'overwrite', and closed by Shutdown
+	mgr := &_LogMgr{logCache: make(map[string]*Logger), rootLogger: newBufferedLogger("root")}
+	replaced := newBufferedLogger("b")
+	mgr.AddOrUpdateLogger("b", replaced)
+	replaced.Info("replaced")
+	mgr.AddOrUpdateLogger("b", newBufferedLogger("c"))
+	if content := readFile(t, filepath.Join(dir, "b.log")); content != "replaced\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+
+	mgr.GetLogger("b").Info("shutdown")
+	mgr.rootLogger.Info("root")
+	mgr.CloseAll()
+	if content := readFile(t, filepath.Join(dir, "c.log")); content != "shutdown\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+	if content := readFile(t, filepath.Join(dir, "root.log")); content != "root\n" {
+		t.Fatalf("unexpected file content: %q", content)
+	}
+}
+
+// The data failed to be flushed is kept and written by the next flush, and
+// the following writes aren't failed.
+func TestBufferFlushFailure(t *testing.T) {
+	out := &failingWriter{failures: 1, partial: true}
+	w := newBufferedWriter(nil, out, BufferConfig{Size: 16, FlushInterval: time.Hour})
+	defer w.Close()
+
+	w.Write([]byte("012345678\n"))
+	if err := w.Flush(); err == nil {
+		t.Fatalf("flush should fail")
+	}
+	if out.String() != "01234" || w.Buffered() != 5 {
+		t.Fatalf("unexpected output: %q, buffered: %d", out.String(), w.Buffered())
+	}
+
+	if _, err := w.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("failed to write after the failed flush, err: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("failed to flush, err: %v", err)
+	}
+	if out.String() != "012345678\nabc\n" || w.Buffered() != 0 {
+		t.Fatalf("unexpected output: %q, buffered: %d", out.String(), w.Buffered())
+	}
+}
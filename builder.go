@@ -14,6 +14,11 @@
 
 package gologging
 
+import (
+	"os"
+	"time"
+)
+
 type loggerBuilder struct {
 	config LoggerConfig
 	// When multi-logger is configured together by Config(), 'useSameFile'
@@ -36,17 +41,17 @@ func builder(handlerType HandlerType) *loggerBuilder {
 	}
 
 	conf := LoggerConfig{
-		INFO,
-		defautlFormatStr,
-		handlerType,
-		DAY,
-		10,
-		100 * MB,
-		"",
-		true,
-		"./",
-		false,
-		false,
+		LevelVal:         INFO,
+		Format:           defautlFormatStr,
+		Handler:          handlerType,
+		Interval:         DAY,
+		BackupCount:      10,
+		MaxBytes:         100 * MB,
+		EnableConsoleLog: true,
+		LogPath:          "./",
+		FsyncPolicy:      FSYNC_NEVER,
+		Multiline:        MULTILINE_KEEP,
+		OnFailure:        FAILURE_IGNORE,
 	}
 
 	return &loggerBuilder{conf, false}
@@ -62,6 +67,43 @@ func (b *loggerBuilder) Format(f string) *loggerBuilder {
 	return b
 }
 
+// MessageLimit limits the size of a message and a whole log in bytes, 0 means
+// unlimited.
+func (b *loggerBuilder) MessageLimit(maxMessageBytes, maxRecordBytes int) *loggerBuilder {
+	b.config.MaxMessageBytes = maxMessageBytes
+	b.config.MaxRecordBytes = maxRecordBytes
+	return b
+}
+
+// OnFailure sets what to do when the handler fails to handle a log. The
+// fallback handler of FAILURE_FALLBACK is configured by 'fallback', which
+// may be nil for the other policies.
+func (b *loggerBuilder) OnFailure(policy FailurePolicy, fallback *LoggerConfig) *loggerBuilder {
+	b.config.OnFailure = policy
+	b.config.Fallback = fallback
+	return b
+}
+
+// Retry sets the times to retry and the initial backoff of FAILURE_RETRY.
+func (b *loggerBuilder) Retry(retries int, backoff time.Duration) *loggerBuilder {
+	b.config.Retries = retries
+	b.config.RetryBackoff = backoff
+	return b
+}
+
+// DisableAfter sets the consecutive failures to disable the handler by
+// FAILURE_DISABLE.
+func (b *loggerBuilder) DisableAfter(failures int) *loggerBuilder {
+	b.config.DisableAfter = failures
+	return b
+}
+
+// Multiline sets the policy of the newlines in messages.
+func (b *loggerBuilder) Multiline(policy MultilinePolicy) *loggerBuilder {
+	b.config.Multiline = policy
+	return b
+}
+
 func (b *loggerBuilder) Interval(i RotateInterval) *loggerBuilder {
 	if b.config.Handler != TIME_ROTATE_HANDLER {
 		panic("'Interval' is only used by time rotated handler")
@@ -70,6 +112,83 @@ func (b *loggerBuilder) Interval(i RotateInterval) *loggerBuilder {
 	return b
 }
 
+// Schedule specifies a cron expression to rotate, e.g. '0 3 * * *'.
+func (b *loggerBuilder) Schedule(expr string) *loggerBuilder {
+	if b.config.Handler != TIME_ROTATE_HANDLER {
+		panic("'Schedule' is only used by time rotated handler")
+	}
+	b.config.Schedule = expr
+	return b
+}
+
+// TimeZone specifies the timezone which rotation times are aligned to.
+func (b *loggerBuilder) TimeZone(name string) *loggerBuilder {
+	b.config.TimeZone = name
+	return b
+}
+
+// RotateTimer makes the file be rotated on schedule even if no log is written.
+func (b *loggerBuilder) RotateTimer(enable bool) *loggerBuilder {
+	if b.config.Handler != TIME_ROTATE_HANDLER {
+		panic("'RotateTimer' is only used by time rotated handler")
+	}
+	b.config.RotateTimer = enable
+	return b
+}
+
+// RotateOnStart archives the existing log file when the logger is configured.
+func (b *loggerBuilder) RotateOnStart(enable bool) *loggerBuilder {
+	b.config.RotateOnStart = enable
+	return b
+}
+
+// FileMode specifies the mode of log files and the directories created
+// for them, e.g. 0600 and 0700.
+func (b *loggerBuilder) FileMode(fileMode, dirMode os.FileMode) *loggerBuilder {
+	b.config.FileMode = fileMode
+	b.config.DirMode = dirMode
+	return b
+}
+
+// Owner specifies the owner and group of log files, by name or id.
+func (b *loggerBuilder) Owner(owner, group string) *loggerBuilder {
+	b.config.Owner = owner
+	b.config.Group = group
+	return b
+}
+
+// Audit makes the log file a tamper-evident audit log, which can be checked
+// by Verify.
+func (b *loggerBuilder) Audit(enable bool) *loggerBuilder {
+	b.config.Audit = enable
+	return b
+}
+
+// EncryptKeyFile encrypts log files by the keys in 'path', see ParseKeyRing
+// for the format. Empty 'activeID' means the last key.
+func (b *loggerBuilder) EncryptKeyFile(path, activeID string) *loggerBuilder {
+	b.config.EncryptKeyFile = path
+	b.config.EncryptKeyID = activeID
+	return b
+}
+
+// EncryptKeyEnv encrypts log files by the keys in the environment variable
+// 'name'. Empty 'activeID' means the last key.
+func (b *loggerBuilder) EncryptKeyEnv(name, activeID string) *loggerBuilder {
+	b.config.EncryptKeyEnv = name
+	b.config.EncryptKeyID = activeID
+	return b
+}
+
+// Redact masks the values of 'keys', the text matching 'patterns' and the
+// text found by 'detectors' in messages, see Redactor.
+func (b *loggerBuilder) Redact(keys, patterns, detectors []string) *loggerBuilder {
+	b.config.RedactKeys = keys
+	b.config.RedactPatterns = patterns
+	b.config.RedactDetectors = detectors
+	return b
+}
+
 func (b *loggerBuilder) BackupCount(count uint16) *loggerBuilder {
 	b.config.BackupCount = count
 	return b
@@ -108,6 +227,55 @@ func (b *loggerBuilder) SyncMode(syncMode bool) *loggerBuilder {
 	return b
 }
 
+// Buffer enables the write buffer of file handlers with 'size' bytes,
+// which is flushed every 'flushInterval'.
+func (b *loggerBuilder) Buffer(size int, flushInterval time.Duration) *loggerBuilder {
+	b.config.BufferSize = size
+	b.config.FlushInterval = flushInterval
+	return b
+}
+
+// Fsync specifies when to fsync the buffered file. 'interval' is only
+// used by FSYNC_INTERVAL.
+func (b *loggerBuilder) Fsync(policy FsyncPolicy, interval time.Duration) *loggerBuilder {
+	b.config.FsyncPolicy = policy
+	b.config.FsyncInterval = interval
+	return b
+}
+
+// CallerSkip specifies the extra stack frames to skip when resolving the
+// caller info, which is used when the logger is wrapped by other functions.
+func (b *loggerBuilder) CallerSkip(n int) *loggerBuilder {
+	b.config.CallerSkip = n
+	return b
+}
+
+// MultiProcess specifies whether the log file is written by multiple
+// processes, in which case rotation is coordinated among them.
+func (b *loggerBuilder) MultiProcess(enable bool) *loggerBuilder {
+	b.config.MultiProcess = enable
+	return b
+}
+
+// BackupPattern specifies the names of backups, e.g. 'app-{date:20060102}-{seq}.log'.
+func (b *loggerBuilder) BackupPattern(pattern string) *loggerBuilder {
+	b.config.BackupPattern = pattern
+	return b
+}
+
+// Symlink specifies a symbolic link always pointing to the active file.
+func (b *loggerBuilder) Symlink(link string) *loggerBuilder {
+	b.config.Symlink = link
+	return b
+}
+
+// PostRotate specifies the names of hooks registered by RegisterRotateHook,
+// which are called after rotation.
+func (b *loggerBuilder) PostRotate(hookNames ...string) *loggerBuilder {
+	b.config.PostRotate = hookNames
+	return b
+}
+
 // Config will conifgure the logger by previous config.
 // And it can be used to configure mulitiple loggers.
 func (b *loggerBuilder) Config(names ...string) {
@@ -0,0 +1,48 @@
+/**
+ * Resolve and cache the caller info of logs.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 11:45:12
+ */
+
+package gologging
+
+import (
+	"runtime"
+	"sync"
+)
+
+type _Caller struct {
+	funcName string
+	fileName string
+	lineNo   int
+}
+
+// As the number of log statements is limited, caller info resolved by PC is
+// cached to avoid resolving it for each log.
+type _CallerCache struct {
+	mu      sync.RWMutex
+	callers map[uintptr]*_Caller
+}
+
+var callerCache = &_CallerCache{callers: make(map[uintptr]*_Caller)}
+
+func lookupCaller(pc uintptr) *_Caller {
+	callerCache.mu.RLock()
+	c, ok := callerCache.callers[pc]
+	callerCache.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	// CallersFrames takes inlined functions into account, which
+	// runtime.FuncForPC doesn't.
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	c = &_Caller{funcName: frame.Function, fileName: frame.File, lineNo: frame.Line}
+
+	callerCache.mu.Lock()
+	callerCache.callers[pc] = c
+	callerCache.mu.Unlock()
+
+	return c
+}
@@ -0,0 +1,67 @@
+/**
+ * convert: translate config files to YAML or JSON.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 01:03:42
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	format := flags.String("to", "", "format to convert to, 'yaml' or 'json'. "+
+		"Detected by the extension of -o by default")
+	output := flags.String("o", "", "output file, stdout by default")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging convert [-to yaml|json] [-o OUTPUT] CONFIG\n\n"+
+			"Convert a config file to YAML or JSON. Comments are dropped, placeholders\n"+
+			"are kept. YAML needs gologging built with '-tags yaml'.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("one config must be specified")
+	}
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*output)) {
+		case ".json":
+			*format = gologging.CONFIG_FORMAT_JSON
+		default:
+			*format = gologging.CONFIG_FORMAT_YAML
+		}
+	}
+
+	data, err := gologging.ConvertConfig(flags.Arg(0), strings.ToLower(*format))
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,97 @@
+/**
+ * decrypt: print the plaintext of encrypted log files.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 19:31:50
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"io"
+	"os"
+)
+
+// keyFlags are the options to load encryption keys, shared by commands.
+type keyFlags struct {
+	file *string
+	env  *string
+}
+
+func addKeyFlags(flags *flag.FlagSet) *keyFlags {
+	return &keyFlags{
+		file: flags.String("key-file", "", "file of the encryption keys"),
+		env:  flags.String("key-env", "", "environment variable of the encryption keys"),
+	}
+}
+
+// load returns nil if no key is specified.
+func (kf *keyFlags) load() (*gologging.KeyRing, error) {
+	switch {
+	case *kf.file != "" && *kf.env != "":
+		return nil, errors.New("only one of -key-file and -key-env can be specified")
+	case *kf.file != "":
+		return gologging.LoadKeyFile(*kf.file)
+	case *kf.env != "":
+		return gologging.LoadKeyEnv(*kf.env)
+	}
+
+	return nil, nil
+}
+
+func runDecrypt(args []string) error {
+	flags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	keys := addKeyFlags(flags)
+	output := flags.String("o", "", "output file, stdout by default")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging decrypt -key-file FILE|-key-env VAR [-o OUTPUT] FILE...\n\n"+
+			"Decrypt log files, which are written to the output in order.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	ring, err := keys.load()
+	if err != nil {
+		return err
+	}
+	if ring == nil || flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("keys and files must be specified")
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	for _, path := range flags.Args() {
+		if err := decryptFile(path, ring, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decryptFile(path string, ring *gologging.KeyRing, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(out, gologging.NewDecryptReader(f, ring)); err != nil {
+		return fmt.Errorf("failed to decrypt %s: %s", path, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+/**
+ * explain: print the effective configs of loggers.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 01:14:05
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Names of handler types in config files.
+var handlerTypeNames = map[gologging.HandlerType]string{
+	gologging.CONSOLE_HANDLER:     "console",
+	gologging.TIME_ROTATE_HANDLER: "time-rotate",
+	gologging.SIZE_ROTATE_HANDLER: "size-rotate",
+}
+
+func runExplain(args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	loggerName := flags.String("logger", "", "only explain the logger")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging explain [-logger NAME] CONFIG\n\n"+
+			"Print the effective configs of the loggers, which are merged with the configs\n"+
+			"they extend, with the placeholders interpolated and the defaults set.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("one config must be specified")
+	}
+
+	loggers, err := gologging.Explain(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, logger := range loggers {
+		if *loggerName != "" && logger.Name != *loggerName {
+			continue
+		}
+		found = true
+		explainLogger(os.Stdout, logger)
+	}
+
+	if !found {
+		return fmt.Errorf("no logger named '%s'", *loggerName)
+	}
+
+	return nil
+}
+
+func explainLogger(out io.Writer, logger *gologging.EffectiveLogger) {
+	fmt.Fprintf(out, "logger %s:\n", logger.Name)
+	fmt.Fprintf(out, "    level: %s\n", logger.Level.Name())
+	fmt.Fprintf(out, "    caller-skip: %d\n", logger.CallerSkip)
+	fmt.Fprintf(out, "    overwrite: %t\n", logger.Overwrite)
+	printList(out, "    ", "redact-keys", logger.RedactKeys)
+	printList(out, "    ", "redact-patterns", logger.RedactPatterns)
+	printList(out, "    ", "redact-detectors", logger.RedactDetectors)
+
+	for _, handler := range logger.Handlers {
+		fmt.Fprintf(out, "    handler %s:\n", handler.Name)
+		explainHandler(out, "        ", handler.Config)
+	}
+	fmt.Fprintln(out)
+}
+
+func explainFailure(out io.Writer, indent string, config *gologging.LoggerConfig) {
+	if config.OnFailure == gologging.FAILURE_IGNORE {
+		return
+	}
+
+	fmt.Fprintf(out, "%son-failure: %s\n", indent, config.OnFailure.Name())
+	switch config.OnFailure {
+	case gologging.FAILURE_RETRY:
+		fmt.Fprintf(out, "%sretries: %d\n", indent, config.Retries)
+		fmt.Fprintf(out, "%sretry-backoff: %s\n", indent, config.RetryBackoff)
+
+	case gologging.FAILURE_FALLBACK:
+		if config.Fallback != nil {
+			fmt.Fprintf(out, "%sfallback:\n", indent)
+			explainHandler(out, indent+"    ", config.Fallback)
+		}
+
+	case gologging.FAILURE_DISABLE:
+		fmt.Fprintf(out, "%sdisable-after: %d\n", indent, config.DisableAfter)
+	}
+}
+
+func explainHandler(out io.Writer, indent string, config *gologging.LoggerConfig) {
+	fmt.Fprintf(out, "%stype: %s\n", indent, handlerTypeNames[config.Handler])
+	fmt.Fprintf(out, "%sformat: %s\n", indent, config.Format)
+	if config.Multiline != gologging.MULTILINE_KEEP {
+		fmt.Fprintf(out, "%smultiline: %s\n", indent, config.Multiline.Name())
+	}
+	if config.MaxMessageBytes > 0 {
+		fmt.Fprintf(out, "%smax-message-bytes: %s\n", indent, formatSize(int64(config.MaxMessageBytes)))
+	}
+	if config.MaxRecordBytes > 0 {
+		fmt.Fprintf(out, "%smax-record-bytes: %s\n", indent, formatSize(int64(config.MaxRecordBytes)))
+	}
+	explainFailure(out, indent, config)
+	if config.Handler == gologging.CONSOLE_HANDLER {
+		// Console handlers are always synchronous
+		fmt.Fprintf(out, "%ssync: true\n", indent)
+		return
+	}
+	fmt.Fprintf(out, "%ssync: %t\n", indent, config.SyncMode)
+
+	file := filepath.Join(config.LogPath, config.FileName)
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	fmt.Fprintf(out, "%sfile: %s\n", indent, file)
+
+	backupName := config.BackupPattern
+	if config.Handler == gologging.TIME_ROTATE_HANDLER {
+		if config.Schedule != "" {
+			fmt.Fprintf(out, "%sschedule: %s\n", indent, config.Schedule)
+		} else {
+			fmt.Fprintf(out, "%sinterval: %s\n", indent, time.Duration(config.Interval)*time.Second)
+		}
+		timezone := config.TimeZone
+		if timezone == "" {
+			timezone = "Local"
+		}
+		fmt.Fprintf(out, "%stimezone: %s\n", indent, timezone)
+		fmt.Fprintf(out, "%srotate-timer: %t\n", indent, config.RotateTimer)
+		if backupName == "" {
+			backupName = gologging.DEFAULT_TIME_BACKUP_PATTERN
+		}
+	} else {
+		fmt.Fprintf(out, "%smax-size: %s\n", indent, formatSize(config.MaxBytes))
+		if backupName == "" {
+			backupName = gologging.DEFAULT_SIZE_BACKUP_PATTERN
+		}
+	}
+	fmt.Fprintf(out, "%srotate-on-start: %t\n", indent, config.RotateOnStart)
+	fmt.Fprintf(out, "%sbackup-count: %d\n", indent, config.BackupCount)
+	fmt.Fprintf(out, "%sbackup-name: %s\n", indent, backupName)
+
+	if config.BufferSize > 0 {
+		fmt.Fprintf(out, "%sbuffer-size: %s\n", indent, formatSize(int64(config.BufferSize)))
+		fmt.Fprintf(out, "%sflush-interval: %s\n", indent, config.FlushInterval)
+		fmt.Fprintf(out, "%sfsync: %s\n", indent, config.FsyncPolicy.Name())
+		if config.FsyncPolicy == gologging.FSYNC_INTERVAL {
+			fmt.Fprintf(out, "%sfsync-interval: %s\n", indent, config.FsyncInterval)
+		}
+	} else {
+		fmt.Fprintf(out, "%sbuffer-size: 0\n", indent)
+	}
+
+	fileMode, dirMode := config.FileMode, config.DirMode
+	if fileMode == 0 {
+		fileMode = 0644
+	}
+	if dirMode == 0 {
+		dirMode = 0755
+	}
+	fmt.Fprintf(out, "%sfile-mode: %#o\n", indent, fileMode)
+	fmt.Fprintf(out, "%sdir-mode: %#o\n", indent, dirMode)
+	printValue(out, indent, "owner", config.Owner)
+	printValue(out, indent, "group", config.Group)
+
+	fmt.Fprintf(out, "%smulti-process: %t\n", indent, config.MultiProcess)
+	fmt.Fprintf(out, "%saudit: %t\n", indent, config.Audit)
+	printValue(out, indent, "symlink", config.Symlink)
+	printList(out, indent, "post-rotate", config.PostRotate)
+	printValue(out, indent, "encrypt-key-file", config.EncryptKeyFile)
+	printValue(out, indent, "encrypt-key-env", config.EncryptKeyEnv)
+	printValue(out, indent, "encrypt-key-id", config.EncryptKeyID)
+}
+
+// printValue skips empty values.
+func printValue(out io.Writer, indent, name, val string) {
+	if val != "" {
+		fmt.Fprintf(out, "%s%s: %s\n", indent, name, val)
+	}
+}
+
+func printList(out io.Writer, indent, name string, vals []string) {
+	if len(vals) > 0 {
+		fmt.Fprintf(out, "%s%s: [%s]\n", indent, name, strings.Join(vals, ", "))
+	}
+}
+
+// formatSize formats the size in the largest unit dividing it, as the
+// config, e.g. 1GB.
+func formatSize(size int64) string {
+	units := []struct {
+		name string
+		size int64
+	}{
+		{"GB", gologging.GB},
+		{"MB", gologging.MB},
+		{"KB", gologging.KB},
+	}
+	for _, unit := range units {
+		if size != 0 && size%unit.size == 0 {
+			return fmt.Sprintf("%d%s", size/unit.size, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
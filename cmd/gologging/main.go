@@ -0,0 +1,64 @@
+/**
+ * Command line tool of gologging.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 17:48:25
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"verify", "verify the hash chain of audit logs", runVerify},
+	{"decrypt", "decrypt encrypted log files", runDecrypt},
+	{"validate", "check config files", runValidate},
+	{"explain", "print the effective configs of loggers", runExplain},
+	{"convert", "convert config files to YAML or JSON", runConvert},
+	{"sample", "emit test logs to preview formats", runSample},
+	{"cat", "print the logs of a log file and its backups", runCat},
+	{"tail", "print the last logs of a log file, and follow it", runTail},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: gologging <command> [options]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "    %-10s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'gologging <command> -h' for the options of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gologging %s: %s\n", name, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if name != "-h" && name != "help" {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,100 @@
+/**
+ * sample: emit test logs to preview formats.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 01:27:33
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"os"
+)
+
+const _SAMPLE_LOGGER = "gologging-sample"
+
+func runSample(args []string) error {
+	flags := flag.NewFlagSet("sample", flag.ExitOnError)
+	config := flags.String("config", "", "config file to load")
+	loggerName := flags.String("logger", "", "logger in the config to emit logs through")
+	format := flags.String("format", "", "format to print logs to stdout, instead of -config")
+	multiline := flags.String("multiline", "keep", "policy of newlines in messages with -format, "+
+		"'keep', 'escape', 'indent' or 'prefix'")
+	message := flags.String("message", "sample log", "message of the logs")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging sample -config CONFIG -logger NAME [-message MSG]\n"+
+			"       gologging sample -format FORMAT [-message MSG]\n\n"+
+			"Emit a log of each level from DEBUG to ERROR, with a trace ID, a span ID and a\n"+
+			"request ID, through a logger of the config or to stdout by a format.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var logger *gologging.Logger
+	switch {
+	case *format != "" && *config == "":
+		policy, err := gologging.NewMultilinePolicyString(*multiline)
+		if err != nil {
+			return err
+		}
+		err = gologging.ConfigLogger(_SAMPLE_LOGGER, &gologging.LoggerConfig{
+			LevelVal:  gologging.DEBUG,
+			Format:    *format,
+			Handler:   gologging.CONSOLE_HANDLER,
+			Multiline: policy,
+		})
+		if err != nil {
+			return err
+		}
+		logger = gologging.GetLogger(_SAMPLE_LOGGER)
+		logger.SetLevel(gologging.DEBUG)
+
+	case *config != "" && *loggerName != "" && *format == "":
+		if err := checkLogger(*config, *loggerName); err != nil {
+			return err
+		}
+		if err := gologging.Load(*config); err != nil {
+			return err
+		}
+		logger = gologging.GetLogger(*loggerName)
+
+	default:
+		flags.Usage()
+		return errors.New("either -config with -logger, or -format must be specified")
+	}
+	defer gologging.Shutdown()
+
+	ctx := gologging.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = gologging.WithSpanID(ctx, "00f067aa0ba902b7")
+	ctx = gologging.WithRequestID(ctx, "sample-request")
+
+	logger.DebugCtx(ctx, "%s", *message)
+	logger.TraceCtx(ctx, "%s", *message)
+	logger.InfoCtx(ctx, "%s", *message)
+	logger.WarnCtx(ctx, "%s", *message)
+	logger.ErrorCtx(ctx, "%s", *message)
+
+	return nil
+}
+
+// checkLogger checks the logger is in the config, otherwise GetLogger would
+// return a new logger.
+func checkLogger(config, loggerName string) error {
+	loggers, err := gologging.Explain(config)
+	if err != nil {
+		return err
+	}
+
+	for _, logger := range loggers {
+		if logger.Name == loggerName {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no logger named '%s' in %s", loggerName, config)
+}
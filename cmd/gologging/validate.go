@@ -0,0 +1,55 @@
+/**
+ * validate: check config files without creating any logger.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 00:52:16
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"os"
+)
+
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	strict := flags.Bool("strict", false, "fail on warnings as well")
+	quiet := flags.Bool("q", false, "print errors only")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging validate [options] CONFIG...\n\n"+
+			"Check config files in the format of goconf, YAML or JSON, and print all the\n"+
+			"problems with the locations. Env vars are interpolated as they are loaded.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no config to validate")
+	}
+
+	var errCount, warnCount int
+	for _, path := range flags.Args() {
+		for _, diag := range gologging.Validate(path) {
+			if diag.Severity == gologging.SEVERITY_ERROR {
+				errCount++
+			} else if warnCount++; *quiet {
+				continue
+			}
+			fmt.Println(diag.String())
+		}
+	}
+
+	if errCount > 0 || (*strict && warnCount > 0) {
+		return fmt.Errorf("%d errors, %d warnings", errCount, warnCount)
+	}
+
+	if !*quiet {
+		fmt.Printf("OK, %d warnings\n", warnCount)
+	}
+	return nil
+}
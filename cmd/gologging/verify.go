@@ -0,0 +1,96 @@
+/**
+ * verify: check the hash chain of audit logs.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 17:52:09
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"os"
+)
+
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	file := flags.String("file", "", "the active audit log, whose backups are verified as well")
+	backupName := flags.String("backup-name", gologging.DEFAULT_TIME_BACKUP_PATTERN,
+		"pattern of the backup names, used with -file")
+	head := flags.String("head", "",
+		"expected hash of the last line, to detect truncation at the end, the head persisted in\n"+
+			"'${file}.chain' by default, which may be followed by lines since the last rotation")
+	strict := flags.Bool("strict", false,
+		"fail if the first line isn't the start of the chain, which is the case if the first line is edited,\n"+
+			"or if there's no head to detect truncation. Only verification with -strict of all the files\n"+
+			"gives the guarantee that the logs are intact")
+	keys := addKeyFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gologging verify [options] [FILE...]\n\n"+
+			"Verify the hash chain of audit logs. FILEs are ordered from the oldest,\n"+
+			"or found by -file and -backup-name.\n\noptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if *file != "" {
+		files, err := gologging.LogFiles(*file, *backupName)
+		if err != nil {
+			return err
+		}
+		paths = append(files, paths...)
+	}
+	if len(paths) == 0 {
+		flags.Usage()
+		return errors.New("no file to verify")
+	}
+
+	ring, err := keys.load()
+	if err != nil {
+		return err
+	}
+
+	expected := *head
+	if expected == "" && *file != "" {
+		if expected, err = gologging.ReadChainHead(*file); err != nil {
+			return err
+		}
+	}
+
+	var report *gologging.AuditReport
+	if expected != "" {
+		report, err = gologging.VerifyWithHead(ring, expected, paths...)
+	} else {
+		report, err = gologging.VerifyWithKeys(ring, paths...)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("files: %d, lines: %d, head: %s\n", report.Files, report.Lines, report.Head)
+	if !report.FromStart {
+		if *strict {
+			return errors.New("the first line isn't the start of the chain, it's edited or earlier logs have been removed")
+		}
+		fmt.Println("warning: the first line isn't the start of the chain, it's edited or earlier logs have been removed")
+	}
+
+	switch {
+	case *head != "" && report.AfterHead > 0:
+		return fmt.Errorf("head mismatch, %d lines follow the expected head: %s", report.AfterHead, *head)
+	case expected == "":
+		if *strict {
+			return errors.New("no head to detect truncation at the end, specify -head, or -file whose head is persisted in '${file}.chain'")
+		}
+		fmt.Println("warning: no head to detect truncation at the end")
+	case report.AfterHead > 0:
+		fmt.Printf("warning: %d lines after the persisted head can't be checked for truncation\n", report.AfterHead)
+	}
+
+	fmt.Println("OK")
+	return nil
+}
@@ -0,0 +1,387 @@
+/**
+ * cat and tail: view log files and their backups.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 10:38:05
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/chosen0ne/gologging"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	_COLOR_RESET = "\033[0m"
+	_COLOR_RED   = "\033[31m"
+	_COLOR_YELL  = "\033[33m"
+	_COLOR_GRAY  = "\033[90m"
+)
+
+var levelColors = map[gologging.Level]string{
+	gologging.DEBUG: _COLOR_GRAY,
+	gologging.TRACE: _COLOR_GRAY,
+	gologging.WARN:  _COLOR_YELL,
+	gologging.ERROR: _COLOR_RED,
+	gologging.FATAL: _COLOR_RED,
+}
+
+// Layouts of -since and -until, besides durations before now.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC3339,
+}
+
+// viewFlags are the options of cat and tail.
+type viewFlags struct {
+	config     *string
+	logger     *string
+	handler    *string
+	format     *string
+	multiline  *string
+	backupName *string
+	keys       *keyFlags
+	level      *string
+	since      *string
+	until      *string
+	name       *string
+	grep       *string
+	jsonOutput *bool
+	color      *string
+}
+
+func addViewFlags(flags *flag.FlagSet) *viewFlags {
+	return &viewFlags{
+		config:     flags.String("config", "", "config file, the file and format of the logger are used"),
+		logger:     flags.String("logger", "", "logger in the config"),
+		handler:    flags.String("handler", "", "file handler of the logger, the first one by default"),
+		format:     flags.String("format", "", "format of FILE, instead of -config"),
+		multiline:  flags.String("multiline", "keep", "policy of newlines in messages with -format"),
+		backupName: flags.String("backup-name", "", "pattern of the backup names of FILE"),
+		keys:       addKeyFlags(flags),
+		level:      flags.String("level", "", "minimal level of the logs"),
+		since:      flags.String("since", "", "logs at or after the time, e.g. '2026-10-18 15:00' or '1h' ago"),
+		until:      flags.String("until", "", "logs before the time"),
+		name:       flags.String("name", "", "name of the logger of the logs, for files shared by loggers"),
+		grep:       flags.String("grep", "", "regexp which the logs must match"),
+		jsonOutput: flags.Bool("json", false, "print the logs as JSON, one per line"),
+		color:      flags.String("color", "auto", "color the logs by level, 'auto', 'always' or 'never'"),
+	}
+}
+
+const _VIEW_USAGE = "usage: gologging %s [options] -config CONFIG -logger NAME\n" +
+	"       gologging %s [options] -format FORMAT FILE\n\n%s\n\noptions:\n"
+
+func runCat(args []string) error {
+	flags := flag.NewFlagSet("cat", flag.ExitOnError)
+	vf := addViewFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, _VIEW_USAGE, "cat", "cat", "Print the logs of the backups from the oldest, "+
+			"followed by the log file. Backups\ncompressed by gzip are read as well.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	view, printer, err := vf.newView(flags)
+	if err != nil {
+		return err
+	}
+	defer printer.out.Flush()
+
+	return view.Cat(printer.print)
+}
+
+func runTail(args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	vf := addViewFlags(flags)
+	n := flags.Int("n", 10, "number of the last logs to print")
+	follow := flags.Bool("f", false, "print the logs appended, across rotations, until interrupted")
+	interval := flags.Duration("interval", gologging.DEFAULT_FOLLOW_INTERVAL, "interval to check the file with -f")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, _VIEW_USAGE, "tail", "tail", "Print the last logs of the log file, "+
+			"and the backups if needed.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	view, printer, err := vf.newView(flags)
+	if err != nil {
+		return err
+	}
+	defer printer.out.Flush()
+
+	records, err := view.Tail(*n)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := printer.print(record); err != nil {
+			return err
+		}
+	}
+	if !*follow {
+		return nil
+	}
+
+	printer.out.Flush()
+	printer.flushEach = true
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return view.Follow(ctx, *interval, printer.print)
+}
+
+// newView creates the view of the file of the logger in the config, or the
+// file given with the format.
+func (vf *viewFlags) newView(flags *flag.FlagSet) (*gologging.LogView, *recordPrinter, error) {
+	var fileName, backupName, format string
+	var policy gologging.MultilinePolicy
+	var ring *gologging.KeyRing
+	var err error
+
+	switch {
+	case *vf.config != "" && *vf.logger != "" && *vf.format == "" && flags.NArg() == 0:
+		config, err := vf.handlerConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+		fileName = filepath.Join(config.LogPath, config.FileName)
+		format, backupName, policy = config.Format, config.BackupPattern, config.Multiline
+		if backupName == "" && config.Handler == gologging.SIZE_ROTATE_HANDLER {
+			backupName = gologging.DEFAULT_SIZE_BACKUP_PATTERN
+		}
+		if config.EncryptKeyFile != "" {
+			ring, err = gologging.LoadKeyFile(config.EncryptKeyFile)
+		} else if config.EncryptKeyEnv != "" {
+			ring, err = gologging.LoadKeyEnv(config.EncryptKeyEnv)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+	case *vf.format != "" && *vf.config == "" && flags.NArg() == 1:
+		fileName, format = flags.Arg(0), *vf.format
+		if policy, err = gologging.NewMultilinePolicyString(*vf.multiline); err != nil {
+			return nil, nil, err
+		}
+
+	default:
+		flags.Usage()
+		return nil, nil, errors.New("either -config with -logger, or -format with a file must be specified")
+	}
+
+	if *vf.backupName != "" {
+		backupName = *vf.backupName
+	}
+	if keys, err := vf.keys.load(); err != nil {
+		return nil, nil, err
+	} else if keys != nil {
+		ring = keys
+	}
+
+	formatter, err := gologging.NewFormatter(format)
+	if err != nil {
+		return nil, nil, err
+	}
+	view, err := gologging.NewLogView(fileName, backupName, formatter.WithMultiline(policy))
+	if err != nil {
+		return nil, nil, err
+	}
+	view.SetKeys(ring)
+
+	filter, err := vf.filter()
+	if err != nil {
+		return nil, nil, err
+	}
+	view.SetFilter(filter)
+
+	printer := &recordPrinter{out: bufio.NewWriter(os.Stdout), json: *vf.jsonOutput}
+	switch *vf.color {
+	case "always":
+		printer.color = true
+	case "auto":
+		printer.color = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	case "never":
+	default:
+		return nil, nil, fmt.Errorf("invalid -color: %s", *vf.color)
+	}
+
+	return view, printer, nil
+}
+
+// handlerConfig returns the effective config of the file handler of the
+// logger.
+func (vf *viewFlags) handlerConfig() (*gologging.LoggerConfig, error) {
+	loggers, err := gologging.Explain(*vf.config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, logger := range loggers {
+		if logger.Name != *vf.logger {
+			continue
+		}
+
+		for _, handler := range logger.Handlers {
+			if handler.Config.Handler == gologging.CONSOLE_HANDLER {
+				continue
+			}
+			if *vf.handler == "" || handler.Name == *vf.handler {
+				return handler.Config, nil
+			}
+		}
+		return nil, fmt.Errorf("no file handler of logger '%s'", *vf.logger)
+	}
+
+	return nil, fmt.Errorf("no logger named '%s' in %s", *vf.logger, *vf.config)
+}
+
+func (vf *viewFlags) filter() (gologging.ViewFilter, error) {
+	var filter gologging.ViewFilter
+	var err error
+
+	if *vf.level != "" {
+		if filter.MinLevel = gologging.NewLevelString(*vf.level); !filter.MinLevel.IsValid() {
+			return filter, fmt.Errorf("invalid level: %s", *vf.level)
+		}
+	}
+	if filter.Since, err = parseTime(*vf.since); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTime(*vf.until); err != nil {
+		return filter, err
+	}
+	if *vf.grep != "" {
+		if filter.Pattern, err = regexp.Compile(*vf.grep); err != nil {
+			return filter, err
+		}
+	}
+	filter.Logger = *vf.name
+
+	return filter, nil
+}
+
+// parseTime parses the time in the local timezone, or a duration before now.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type recordPrinter struct {
+	out   *bufio.Writer
+	json  bool
+	color bool
+	// Flush after each record when following.
+	flushEach bool
+}
+
+// A record printed as JSON, the other attributes are added as well.
+type _JSONRecord struct {
+	Time    string `json:"time,omitempty"`
+	Level   string `json:"level,omitempty"`
+	Logger  string `json:"logger,omitempty"`
+	Message string `json:"message"`
+}
+
+func (printer *recordPrinter) print(record *gologging.Record) error {
+	var err error
+	if printer.json {
+		err = printer.printJSON(record)
+	} else {
+		err = printer.printText(record)
+	}
+	if err == nil && printer.flushEach {
+		err = printer.out.Flush()
+	}
+
+	return err
+}
+
+func (printer *recordPrinter) printText(record *gologging.Record) error {
+	color := ""
+	if _, ok := record.Attrs["levelname"]; ok && printer.color {
+		color = levelColors[record.Level]
+	}
+	if color == "" {
+		_, err := fmt.Fprintln(printer.out, record.Raw)
+		return err
+	}
+
+	_, err := fmt.Fprintf(printer.out, "%s%s%s\n", color, record.Raw, _COLOR_RESET)
+	return err
+}
+
+func (printer *recordPrinter) printJSON(record *gologging.Record) error {
+	obj := _JSONRecord{Logger: record.Logger, Message: record.Message}
+	if !record.Time.IsZero() {
+		obj.Time = record.Time.Format(time.RFC3339)
+	}
+	if _, ok := record.Attrs["levelname"]; ok {
+		obj.Level = record.Level.Name()
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	// Other attributes are appended to the object
+	var b strings.Builder
+	b.Write(data[:len(data)-1])
+	names := make([]string, 0, len(record.Attrs))
+	for name := range record.Attrs {
+		switch name {
+		case "datetime", "date", "time", "levelname", "name", "message":
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeJSONField(&b, name, record.Attrs[name])
+	}
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(printer.out, b.String())
+	return err
+}
+
+func writeJSONField(b *strings.Builder, name, val string) {
+	key, _ := json.Marshal(name)
+	data, _ := json.Marshal(val)
+	fmt.Fprintf(b, ",%s:%s", key, data)
+}
@@ -8,10 +8,13 @@ package gologging
 
 import (
 	"errors"
+	"fmt"
 	"github.com/chosen0ne/goutils"
+	"io"
 	"os"
 	"path"
 	"strings"
+	"time"
 )
 
 type HandlerType string
@@ -50,22 +53,79 @@ type LoggerConfig struct {
 	SyncWrite bool
 	// Wheather log 'Emit' and 'Hanle' are synchronous.
 	SyncMode bool
+	// Size of the write buffer of file handlers, 0 means unbuffered.
+	BufferSize int
+	// Interval to flush the write buffer, only used when BufferSize > 0.
+	FlushInterval time.Duration
+	// When to fsync the buffered file, only used when BufferSize > 0.
+	FsyncPolicy   FsyncPolicy
+	FsyncInterval time.Duration
+	// Extra stack frames to skip when resolving the caller info.
+	CallerSkip int
+	// Whether the file is written by multiple processes, in which case
+	// rotation is coordinated by a lock file.
+	MultiProcess bool
+	// Pattern of backup names, see backupNamer for the placeholders.
+	// Default is DEFAULT_TIME_BACKUP_PATTERN or DEFAULT_SIZE_BACKUP_PATTERN.
+	BackupPattern string
+	// A symbolic link pointing to the active file, which is relative to
+	// LogPath. Empty means no link.
+	Symlink string
+	// A cron expression which replaces Interval for time rotation,
+	// e.g. '0 3 * * *'.
+	Schedule string
+	// Timezone which rotation times are aligned to, e.g. 'Asia/Shanghai'.
+	// Default is the local timezone.
+	TimeZone string
+	// Whether time rotation is triggered by a timer even if no log is written.
+	RotateTimer bool
+	// Names of hooks registered by RegisterRotateHook, which are called
+	// after rotation.
+	PostRotate []string
+	// Whether the existing file is archived when the handler is created.
+	RotateOnStart bool
+	// Mode of log files and the directories created for them, 0 means
+	// 0644 and 0755.
+	FileMode os.FileMode
+	DirMode  os.FileMode
+	// Owner and group of log files, by name or id. Empty means unchanged.
+	Owner string
+	Group string
+	// Whether the file is a tamper-evident audit log by hash chaining.
+	Audit bool
+	// Redaction of messages, which applies to the logger. Values of
+	// RedactKeys are masked, as well as text matching RedactPatterns and
+	// RedactDetectors, see Redactor.
+	RedactKeys      []string
+	RedactPatterns  []string
+	RedactDetectors []string
+	// Keys to encrypt log files, which are loaded from a file or an
+	// environment variable in the format of ParseKeyRing. EncryptKeyID
+	// specifies the active key, the last key by default.
+	EncryptKeyFile string
+	EncryptKeyEnv  string
+	EncryptKeyID   string
+	// Policy of the newlines in messages, see MultilinePolicy.
+	Multiline MultilinePolicy
+	// Limits of the size of a message and a whole log in bytes, 0 means
+	// unlimited. See StreamHandler.SetMessageLimit.
+	MaxMessageBytes int
+	MaxRecordBytes  int
+	// What to do when the handler fails to handle a log, see FailureConfig.
+	// 0 of the others means the defaults.
+	OnFailure    FailurePolicy
+	Retries      int
+	RetryBackoff time.Duration
+	DisableAfter int
+	// Config of the handler which logs are written to by FAILURE_FALLBACK.
+	Fallback *LoggerConfig
 }
 
+// newLoggerConfig returns a copy of the config, which shares the lists
+// and the fallback config.
 func newLoggerConfig(conf *LoggerConfig) *LoggerConfig {
-	loggerConf := &LoggerConfig{}
-	loggerConf.LevelVal = conf.LevelVal
-	loggerConf.Format = conf.Format
-	loggerConf.Handler = conf.Handler
-	loggerConf.Interval = conf.Interval
-	loggerConf.BackupCount = conf.BackupCount
-	loggerConf.MaxBytes = conf.MaxBytes
-	loggerConf.FileName = conf.FileName
-	loggerConf.EnableConsoleLog = conf.EnableConsoleLog
-	loggerConf.LogPath = conf.LogPath
-	loggerConf.SyncMode = conf.SyncMode
-
-	return loggerConf
+	loggerConf := *conf
+	return &loggerConf
 }
 
 func ConfigLogger(name string, config *LoggerConfig) error {
@@ -83,11 +143,28 @@ func ConfigLogger(name string, config *LoggerConfig) error {
 		return errors.New("logger named '" + name + "' already exists!")
 	}
 
+	redactor, err := newConfigRedactor(config.RedactKeys, config.RedactPatterns, config.RedactDetectors)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to create redactor for logger, name: %s", name)
+	}
+
+	if config.Fallback != nil {
+		// The defaults are set on a copy, as it may be shared by loggers.
+		config.Fallback = newLoggerConfig(config.Fallback)
+		setDefaultConfig(name, config.Fallback)
+	}
+
 	handler, err := createHandler(config)
 	if err != nil {
 		return goutils.WrapErrorf(err, "failed to create handler for logger, name: %s", name)
 	}
 
+	failure, err := createFailureConfig(config)
+	if err != nil {
+		closeHandler(handler)
+		return goutils.WrapErrorf(err, "failed to create fallback handler for logger, name: %s", name)
+	}
+
 	// Create Logger
 	logger, ok := loggerMgr.logCache[name]
 	if !ok {
@@ -95,7 +172,17 @@ func ConfigLogger(name string, config *LoggerConfig) error {
 		loggerMgr.logCache[name] = logger
 	}
 	logger.SetLevel(config.LevelVal)
-	logger.AddHandler(handler)
+	logger.CallerSkip(config.CallerSkip)
+	if redactor != nil {
+		logger.SetRedactor(redactor)
+	}
+	handlerName := fmt.Sprintf("handler-%d", len(logger.handlers))
+	logger.AddNamedHandler(handlerName, handler)
+	if failure != nil {
+		if err := logger.SetFailureConfig(handlerName, *failure); err != nil {
+			return goutils.WrapErrorf(err, "failed to set failure config for logger, name: %s", name)
+		}
+	}
 
 	return nil
 }
@@ -122,15 +209,52 @@ func setDefaultConfig(name string, config *LoggerConfig) {
 	if config.LogPath == "" {
 		config.LogPath = "."
 	}
+	if config.BufferSize > 0 {
+		buffer := BufferConfig{
+			Size:          config.BufferSize,
+			FlushInterval: config.FlushInterval,
+			Fsync:         config.FsyncPolicy,
+			FsyncInterval: config.FsyncInterval,
+		}
+		buffer.setDefault()
+		config.FlushInterval = buffer.FlushInterval
+		config.FsyncInterval = buffer.FsyncInterval
+	}
+	if config.OnFailure != FAILURE_IGNORE {
+		failure := FailureConfig{
+			Retries:      config.Retries,
+			RetryBackoff: config.RetryBackoff,
+			DisableAfter: config.DisableAfter,
+		}
+		failure.setDefault()
+		config.Retries = failure.Retries
+		config.RetryBackoff = failure.RetryBackoff
+		config.DisableAfter = failure.DisableAfter
+	}
 }
 
-func createHandler(config *LoggerConfig) (Handler, error) {
+func createHandler(config *LoggerConfig) (_ Handler, err error) {
 	fpath, err := getAbsPath(config.LogPath, config.FileName)
 	if err != nil {
 		return nil, goutils.WrapErrorf(err, "failed to get absolute path")
 	}
 
+	if config.Handler != CONSOLE_HANDLER {
+		// Create the file with the permissions before it's opened.
+		if err := createLogFile(fpath, config.filePerm()); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to create log file, file: %s", fpath)
+		}
+	}
+
 	var handler Handler
+	defer func() {
+		// The file, the buffer and the timer of the handler are released if
+		// it fails to be configured.
+		if err != nil && handler != nil {
+			closeHandler(handler)
+		}
+	}()
+
 	switch config.Handler {
 	case CONSOLE_HANDLER:
 		handler = NewStreamHandle(os.Stdout)
@@ -142,7 +266,17 @@ func createHandler(config *LoggerConfig) (Handler, error) {
 			return nil, goutils.WrapErrorf(err, "failed to create time rotate handler")
 		}
 		handler = h
-		h.FileHandler.SyncWrite(config.SyncWrite)
+		if err := configFileHandler(&h.FileHandler, config); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to config time rotate handler")
+		}
+		if err := configTimeRotateHandler(h, config); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to config time rotate handler")
+		}
+		if config.RotateOnStart {
+			if err := h.RotateOnStart(); err != nil {
+				return nil, goutils.WrapErrorf(err, "failed to rotate time rotate handler")
+			}
+		}
 
 	case SIZE_ROTATE_HANDLER:
 		h, err := NewSizeRotateFileHandler(fpath, config.MaxBytes, config.BackupCount)
@@ -150,11 +284,21 @@ func createHandler(config *LoggerConfig) (Handler, error) {
 			return nil, goutils.WrapErrorf(err, "failed to create size rotate handler")
 		}
 		handler = h
-		h.FileHandler.SyncWrite(config.SyncWrite)
+		if err := configFileHandler(&h.FileHandler, config); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to config size rotate handler")
+		}
+		if config.RotateOnStart {
+			if err := h.RotateOnStart(); err != nil {
+				return nil, goutils.WrapErrorf(err, "failed to rotate size rotate handler")
+			}
+		}
 	}
 
 	handler.SetLevel(config.LevelVal)
 	handler.SetSyncMode(config.SyncMode)
+	if limiter, ok := handler.(messageLimiter); ok {
+		limiter.SetMessageLimit(config.MaxMessageBytes, config.MaxRecordBytes)
+	}
 	if config.Handler == CONSOLE_HANDLER {
 		// By default, console handler is in synchronized mode.
 		handler.SetSyncMode(true)
@@ -169,11 +313,199 @@ func createHandler(config *LoggerConfig) (Handler, error) {
 	if err != nil {
 		return nil, goutils.WrapErrorf(err, "failed to create formatter")
 	}
-	handler.SetFormatter(formatter)
+	handler.SetFormatter(formatter.WithMultiline(config.Multiline))
 
 	return handler, nil
 }
 
+// createFailureConfig creates the failure config of the handler, and the
+// fallback handler if any. nil is returned if failures are ignored.
+func createFailureConfig(config *LoggerConfig) (*FailureConfig, error) {
+	if config.OnFailure == FAILURE_IGNORE {
+		return nil, nil
+	}
+
+	failure := &FailureConfig{
+		Policy:       config.OnFailure,
+		Retries:      config.Retries,
+		RetryBackoff: config.RetryBackoff,
+		DisableAfter: config.DisableAfter,
+	}
+	if config.OnFailure == FAILURE_FALLBACK {
+		if config.Fallback == nil {
+			return nil, goutils.NewErr("no fallback handler")
+		}
+		if config.Fallback.OnFailure == FAILURE_FALLBACK {
+			return nil, goutils.NewErr("fallback handler can't fall back again")
+		}
+
+		fallback, err := createHandler(config.Fallback)
+		if err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to create fallback handler")
+		}
+		failure.Fallback = fallback
+	}
+
+	return failure, nil
+}
+
+func closeHandler(handler Handler) {
+	if closer, ok := handler.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+func configFileHandler(handler *FileHandler, config *LoggerConfig) error {
+	handler.SyncWrite(config.SyncWrite)
+
+	if perm := config.filePerm(); perm != nil {
+		if err := handler.SetFilePerm(*perm); err != nil {
+			return goutils.WrapErrorf(err, "failed to set file permission")
+		}
+	}
+
+	if config.MultiProcess {
+		if err := handler.EnableMultiProcess(); err != nil {
+			return goutils.WrapErrorf(err, "failed to enable multi-process mode")
+		}
+	}
+
+	if config.EncryptKeyFile != "" || config.EncryptKeyEnv != "" {
+		ring, err := config.keyRing()
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to load encryption keys")
+		}
+		if err := handler.EnableEncryption(ring); err != nil {
+			return goutils.WrapErrorf(err, "failed to enable encryption")
+		}
+	}
+
+	if config.Audit {
+		if err := handler.EnableHashChain(); err != nil {
+			return goutils.WrapErrorf(err, "failed to enable hash chain")
+		}
+	}
+
+	if config.BackupPattern != "" {
+		if err := handler.SetBackupPattern(config.BackupPattern); err != nil {
+			return goutils.WrapErrorf(err, "failed to set backup pattern")
+		}
+	}
+
+	if config.Symlink != "" {
+		if err := handler.SetSymlink(config.Symlink); err != nil {
+			return goutils.WrapErrorf(err, "failed to set symlink")
+		}
+	}
+
+	for _, hookName := range config.PostRotate {
+		hook, err := getRotateHook(hookName)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to get rotate hook")
+		}
+		handler.OnRotate(hook)
+	}
+
+	if config.BufferSize > 0 {
+		handler.EnableBuffer(BufferConfig{
+			Size:          config.BufferSize,
+			FlushInterval: config.FlushInterval,
+			Fsync:         config.FsyncPolicy,
+			FsyncInterval: config.FsyncInterval,
+		})
+	}
+
+	return nil
+}
+
+func configTimeRotateHandler(handler *TimeRotateFileHandler, config *LoggerConfig) error {
+	loc, err := LoadLocation(config.TimeZone)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to load timezone")
+	}
+	handler.SetLocation(loc)
+
+	if config.Schedule != "" {
+		schedule, err := NewCronSchedule(config.Schedule)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to parse schedule")
+		}
+		handler.SetSchedule(schedule)
+	}
+
+	if config.RotateTimer {
+		handler.EnableTimer()
+	}
+
+	return nil
+}
+
+// newConfigRedactor returns nil if no redaction is configured. Patterns are
+// named by their index, e.g. 'pattern-0'.
+func newConfigRedactor(keys, patterns, detectors []string) (*Redactor, error) {
+	if len(keys) == 0 && len(patterns) == 0 && len(detectors) == 0 {
+		return nil, nil
+	}
+
+	redactor := &Redactor{}
+	if err := redactor.MaskKeys(keys...); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to add redact keys")
+	}
+
+	for idx, pattern := range patterns {
+		if err := redactor.AddRule(fmt.Sprintf("pattern-%d", idx), pattern); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to add redact pattern")
+		}
+	}
+
+	for _, detector := range detectors {
+		if err := redactor.AddDetector(detector); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to add redact detector")
+		}
+	}
+
+	return redactor, nil
+}
+
+func (config *LoggerConfig) keyRing() (*KeyRing, error) {
+	if config.EncryptKeyFile != "" && config.EncryptKeyEnv != "" {
+		return nil, goutils.NewErr("only one of key file and key env can be specified")
+	}
+
+	var ring *KeyRing
+	var err error
+	if config.EncryptKeyFile != "" {
+		ring, err = LoadKeyFile(config.EncryptKeyFile)
+	} else {
+		ring, err = LoadKeyEnv(config.EncryptKeyEnv)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.EncryptKeyID != "" {
+		if err := ring.SetActive(config.EncryptKeyID); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to set active key")
+		}
+	}
+
+	return ring, nil
+}
+
+// filePerm returns nil if no permission is configured.
+func (config *LoggerConfig) filePerm() *FilePerm {
+	if config.FileMode == 0 && config.DirMode == 0 && config.Owner == "" && config.Group == "" {
+		return nil
+	}
+
+	return &FilePerm{
+		FileMode: config.FileMode,
+		DirMode:  config.DirMode,
+		Owner:    config.Owner,
+		Group:    config.Group,
+	}
+}
+
 func getAbsPath(fpath, fname string) (string, error) {
 	if path.IsAbs(fpath) {
 		return path.Join(fpath, fname), nil
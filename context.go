@@ -0,0 +1,155 @@
+/**
+ * Integration with context.Context.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 19:58:31
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"sync"
+)
+
+// Name of the built-in extractor, which extracts the IDs set by
+// WithTraceID, WithSpanID and WithRequestID.
+const DEFAULT_CONTEXT_EXTRACTOR = "gologging"
+
+// ContextFields are the fields of a log extracted from its context, which
+// are formatted by ${trace_id}, ${span_id} and ${request_id}.
+type ContextFields struct {
+	TraceID   string
+	SpanID    string
+	RequestID string
+}
+
+func (fields ContextFields) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "ContextFields{traceID: %s, spanID: %s, requestID: %s}",
+		fields.TraceID, fields.SpanID, fields.RequestID)
+
+	return string(b.Bytes())
+}
+
+// ContextExtractor fills the fields from the context, e.g. the IDs of the
+// span of a tracing library. Extractors are called in the order of
+// registration, and a field set by an earlier extractor can be overwritten.
+// It's called for each log with a context, so it should be fast.
+type ContextExtractor func(ctx context.Context, fields *ContextFields)
+
+type _NamedExtractor struct {
+	name      string
+	extractor ContextExtractor
+}
+
+// Keys of the values in contexts, which are unexported to avoid collisions.
+type _ContextKey int
+
+const (
+	_LOGGER_KEY _ContextKey = iota
+	_TRACE_ID_KEY
+	_SPAN_ID_KEY
+	_REQUEST_ID_KEY
+)
+
+var (
+	extractors   []_NamedExtractor
+	extractorsMu sync.RWMutex
+)
+
+// RegisterContextExtractor registers an extractor by name, which is used by
+// all the loggers.
+func RegisterContextExtractor(name string, extractor ContextExtractor) error {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	for _, e := range extractors {
+		if e.name == name {
+			return goutils.NewErr("context extractor named '%s' already exists", name)
+		}
+	}
+	extractors = append(extractors, _NamedExtractor{name, extractor})
+
+	return nil
+}
+
+// UnregisterContextExtractor removes the extractor named 'name'.
+func UnregisterContextExtractor(name string) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	for i, e := range extractors {
+		if e.name == name {
+			extractors = append(extractors[:i:i], extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+func extractContext(ctx context.Context, fields *ContextFields) {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	for _, e := range extractors {
+		e.extractor(ctx, fields)
+	}
+}
+
+// NewContext returns a context carrying the logger, which is returned by
+// FromContext.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, _LOGGER_KEY, logger)
+}
+
+// FromContext returns the logger carried by the context, or the root
+// logger if there isn't one.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(_LOGGER_KEY).(*Logger); ok {
+			return logger
+		}
+	}
+
+	return loggerMgr.rootLogger
+}
+
+// WithTraceID returns a context carrying the trace ID, which is extracted
+// by the built-in extractor.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, _TRACE_ID_KEY, traceID)
+}
+
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, _SPAN_ID_KEY, spanID)
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, _REQUEST_ID_KEY, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(_REQUEST_ID_KEY).(string)
+	return requestID
+}
+
+func extractDefault(ctx context.Context, fields *ContextFields) {
+	if traceID, ok := ctx.Value(_TRACE_ID_KEY).(string); ok {
+		fields.TraceID = traceID
+	}
+	if spanID, ok := ctx.Value(_SPAN_ID_KEY).(string); ok {
+		fields.SpanID = spanID
+	}
+	if requestID, ok := ctx.Value(_REQUEST_ID_KEY).(string); ok {
+		fields.RequestID = requestID
+	}
+}
+
+func init() {
+	RegisterContextExtractor(DEFAULT_CONTEXT_EXTRACTOR, extractDefault)
+}
@@ -0,0 +1,254 @@
+/**
+ * Conversion of config files among the formats.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 00:31:48
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/chosen0ne/goutils"
+	"strconv"
+	"strings"
+)
+
+const (
+	CONFIG_FORMAT_YAML = "yaml"
+	CONFIG_FORMAT_JSON = "json"
+)
+
+// Items which are lists in YAML and JSON, others are strings.
+var listItems = map[string]bool{
+	_HANDLERS_LABEL:    true,
+	_REDACT_KEYS_LABEL: true,
+	_REDACT_PAT_LABEL:  true,
+	_REDACT_DET_LABEL:  true,
+	_POST_ROTATE_LABEL: true,
+	_LOGGERS_LABEL:     true,
+}
+
+// Kinds of the nodes of configs.
+const (
+	_MAPPING_NODE = iota + 1
+	_LIST_NODE
+	_SCALAR_NODE
+)
+
+// Types of scalar values, which are unquoted if they aren't strings.
+const (
+	_STRING_VALUE = iota
+	_BOOL_VALUE
+	_INT_VALUE
+)
+
+// _ConfNode is a node of the config converted to YAML or JSON, which keeps
+// the order of the items of mappings.
+type _ConfNode struct {
+	kind int
+	// Keys and values of a mapping in turn, or items of a list.
+	content []*_ConfNode
+	value   string
+	plain   int
+}
+
+// ConvertConfig converts the config file to 'format', which is
+// CONFIG_FORMAT_YAML or CONFIG_FORMAT_JSON. The format of the file is
+// detected as Load. Sections and items keep the order of the file,
+// placeholders are kept and comments are dropped.
+//
+// Sections of the loggers in 'loggers' are converted to 'loggers', sections
+// with 'format' to 'formatters', and others to 'handlers'. Converting to
+// YAML needs the build tag 'yaml'.
+func ConvertConfig(configPath, format string) ([]byte, error) {
+	if format != CONFIG_FORMAT_YAML && format != CONFIG_FORMAT_JSON {
+		return nil, goutils.NewErr("unknown config format: %s", format)
+	}
+
+	conf, err := parseConfFile(configPath)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse conf, conf: %s", configPath)
+	}
+
+	doc, err := newConfigNode(conf)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to convert conf, conf: %s", configPath)
+	}
+
+	if format == CONFIG_FORMAT_YAML {
+		return encodeYAMLConfig(doc)
+	}
+
+	var b bytes.Buffer
+	if err := writeJSONNode(&b, doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to indent JSON")
+	}
+	out.WriteByte('\n')
+
+	return out.Bytes(), nil
+}
+
+// listValue splits a string by spaces as goconf.
+func listValue(val interface{}) []string {
+	if list, ok := val.([]string); ok {
+		return list
+	}
+
+	return strings.Fields(val.(string))
+}
+
+// newConfigNode returns the mapping of 'loggers', 'handlers' and
+// 'formatters' of the config.
+func newConfigNode(conf *sectionConf) (*_ConfNode, error) {
+	for _, key := range conf.infos[""].keys {
+		if key != _LOGGERS_LABEL {
+			return nil, goutils.NewErr("unknown item out of sections: %s", key)
+		}
+	}
+
+	loggers := newMappingNode()
+	isLogger := make(map[string]bool)
+	if names, ok := conf.sections[""][_LOGGERS_LABEL]; ok {
+		for _, sectionName := range listValue(names) {
+			if !isValidLogger(sectionName) {
+				return nil, goutils.NewErr("invalid section name for logger, name: %s", sectionName)
+			}
+			if !conf.HasSection(sectionName) {
+				return nil, goutils.NewErr("no section named '%s'", sectionName)
+			}
+
+			isLogger[sectionName] = true
+			addMappingItem(loggers, strings.TrimPrefix(sectionName, _LOGGER_PREFIX),
+				newSectionNode(conf, sectionName))
+		}
+	}
+
+	handlers, formatters := newMappingNode(), newMappingNode()
+	for _, name := range conf.names {
+		if isLogger[name] {
+			continue
+		}
+
+		if _, ok := conf.sections[name][_FORMAT_LABEL]; ok {
+			addMappingItem(formatters, name, newSectionNode(conf, name))
+		} else {
+			addMappingItem(handlers, name, newSectionNode(conf, name))
+		}
+	}
+
+	doc := newMappingNode()
+	addMappingItem(doc, _YAML_LOGGERS, loggers)
+	if len(handlers.content) > 0 {
+		addMappingItem(doc, _YAML_HANDLERS, handlers)
+	}
+	if len(formatters.content) > 0 {
+		addMappingItem(doc, _YAML_FORMATTERS, formatters)
+	}
+
+	return doc, nil
+}
+
+func newSectionNode(conf *sectionConf, name string) *_ConfNode {
+	node := newMappingNode()
+	for _, key := range conf.infos[name].keys {
+		val := conf.sections[name][key]
+		if !listItems[key] {
+			if str, ok := val.(string); ok {
+				addMappingItem(node, key, newValueNode(str))
+				continue
+			}
+		}
+
+		list := &_ConfNode{kind: _LIST_NODE}
+		for _, item := range listValue(val) {
+			list.content = append(list.content, newStringNode(item))
+		}
+		addMappingItem(node, key, list)
+	}
+
+	return node
+}
+
+func newMappingNode() *_ConfNode {
+	return &_ConfNode{kind: _MAPPING_NODE}
+}
+
+func newStringNode(val string) *_ConfNode {
+	return &_ConfNode{kind: _SCALAR_NODE, value: val}
+}
+
+// newValueNode returns a node of a value, flags and decimal numbers are
+// unquoted. Numbers starting with '0', e.g. modes, are kept as strings.
+func newValueNode(val string) *_ConfNode {
+	node := newStringNode(val)
+	if val == "true" || val == "false" {
+		node.plain = _BOOL_VALUE
+	} else if n, err := strconv.Atoi(val); err == nil && n > 0 && val[0] != '0' && val[0] != '+' {
+		node.plain = _INT_VALUE
+	}
+
+	return node
+}
+
+func addMappingItem(node *_ConfNode, key string, val *_ConfNode) {
+	node.content = append(node.content, newStringNode(key), val)
+}
+
+// writeJSONNode writes the node of mappings, lists and scalars as
+// compact JSON, keeping the order of the mappings.
+func writeJSONNode(b *bytes.Buffer, node *_ConfNode) error {
+	switch node.kind {
+	case _MAPPING_NODE:
+		b.WriteByte('{')
+		for i := 0; i < len(node.content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONNode(b, node.content[i])
+			b.WriteByte(':')
+			if err := writeJSONNode(b, node.content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+
+	case _LIST_NODE:
+		b.WriteByte('[')
+		for i, item := range node.content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSONNode(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+
+	case _SCALAR_NODE:
+		if node.plain != _STRING_VALUE {
+			b.WriteString(node.value)
+			break
+		}
+
+		// Formats are kept readable, e.g. '<${message}>'
+		var val bytes.Buffer
+		enc := json.NewEncoder(&val)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(node.value); err != nil {
+			return goutils.WrapErrorf(err, "failed to marshal value: %s", node.value)
+		}
+		b.Write(bytes.TrimRight(val.Bytes(), "\n"))
+
+	default:
+		return goutils.NewErr("unexpected kind of node: %d", node.kind)
+	}
+
+	return nil
+}
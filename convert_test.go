@@ -0,0 +1,52 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 01:48:52
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConvertConfig(t *testing.T) {
+	expected, err := Explain("logger-sample.conf")
+	if err != nil {
+		t.Fatalf("failed to explain sample, err: %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{CONFIG_FORMAT_YAML, CONFIG_FORMAT_JSON} {
+		if format == CONFIG_FORMAT_YAML && !_YAML_SUPPORTED {
+			continue
+		}
+		data, err := ConvertConfig("logger-sample.conf", format)
+		if err != nil {
+			t.Fatalf("failed to convert sample, format: %s, err: %v", format, err)
+		}
+
+		configPath := filepath.Join(dir, "logger."+format)
+		if err := os.WriteFile(configPath, data, 0644); err != nil {
+			t.Fatalf("failed to write config, err: %v", err)
+		}
+
+		loggers, err := Explain(configPath)
+		if err != nil {
+			t.Fatalf("failed to explain converted config, format: %s, err: %v, config:\n%s", format, err, data)
+		}
+		for i := range expected {
+			if !reflect.DeepEqual(loggers[i], expected[i]) {
+				t.Fatalf("converted logger differs, format: %s, logger: %s, expected: %s", format,
+					loggers[i], expected[i])
+			}
+		}
+	}
+
+	if _, err := ConvertConfig("logger-sample.conf", "toml"); err == nil {
+		t.Fatalf("unknown format is converted")
+	}
+}
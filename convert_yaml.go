@@ -0,0 +1,57 @@
+//go:build yaml
+
+/**
+ * Encoding of configs in YAML, which needs gopkg.in/yaml.v3.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 19:12:40
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"github.com/chosen0ne/goutils"
+	"gopkg.in/yaml.v3"
+)
+
+// encodeYAMLConfig encodes the config in YAML.
+func encodeYAMLConfig(doc *_ConfNode) ([]byte, error) {
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(newYAMLNode(doc)); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to encode YAML")
+	}
+	enc.Close()
+
+	return out.Bytes(), nil
+}
+
+// newYAMLNode converts the node to a YAML node, lists are in the flow style.
+func newYAMLNode(node *_ConfNode) *yaml.Node {
+	switch node.kind {
+	case _MAPPING_NODE:
+		yamlNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, child := range node.content {
+			yamlNode.Content = append(yamlNode.Content, newYAMLNode(child))
+		}
+		return yamlNode
+
+	case _LIST_NODE:
+		yamlNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, child := range node.content {
+			yamlNode.Content = append(yamlNode.Content, newYAMLNode(child))
+		}
+		return yamlNode
+	}
+
+	tag := "!!str"
+	if node.plain == _BOOL_VALUE {
+		tag = "!!bool"
+	} else if node.plain == _INT_VALUE {
+		tag = "!!int"
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: node.value}
+}
@@ -0,0 +1,381 @@
+/**
+ * Encryption at rest of log files.
+ *
+ * An encrypted log file is a sequence of chunks, each chunk is sealed by
+ * AES-GCM and written by one write:
+ *	length: uint32, big endian, length of the following fields
+ *	version: 1 byte
+ *	key id length: 1 byte
+ *	key id
+ *	nonce: 12 bytes
+ *	ciphertext with the tag, the version and the key id are authenticated
+ * As each chunk names its key, a file can be appended after the key is
+ * rotated, and backups can be read as long as their keys are in the KeyRing.
+ * A chunk torn at the end of the file, e.g. by a crash, is truncated before
+ * the file is appended, so that the following chunks can be read.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 19:02:17
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	_CRYPT_VERSION   = 1
+	_CRYPT_LEN_SIZE  = 4
+	_MAX_KEY_ID_LEN  = 255
+	_MAX_CHUNK_BYTES = 64 * 1024 * 1024
+	_KEY_SEP         = ':'
+)
+
+// KeyRing holds the keys to encrypt and decrypt log files. Logs are
+// encrypted by the active key, and any key in the ring can decrypt.
+type KeyRing struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+}
+
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string]cipher.AEAD)}
+}
+
+// AddKey adds an AES key of 16, 24 or 32 bytes named 'id'. The first key
+// added is the active key.
+func (ring *KeyRing) AddKey(id string, key []byte) error {
+	if id == "" || len(id) > _MAX_KEY_ID_LEN {
+		return goutils.NewErr("invalid key id: '%s'", id)
+	}
+	if _, ok := ring.keys[id]; ok {
+		return goutils.NewErr("duplicated key id: %s", id)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return goutils.WrapErrorf(err, "invalid AES key, id: %s", id)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to create GCM, id: %s", id)
+	}
+
+	ring.keys[id] = aead
+	if ring.activeID == "" {
+		ring.activeID = id
+	}
+
+	return nil
+}
+
+// SetActive makes the key named 'id' encrypt the following logs.
+func (ring *KeyRing) SetActive(id string) error {
+	if _, ok := ring.keys[id]; !ok {
+		return goutils.NewErr("no key named '%s'", id)
+	}
+	ring.activeID = id
+
+	return nil
+}
+
+func (ring *KeyRing) ActiveID() string {
+	return ring.activeID
+}
+
+func (ring *KeyRing) String() string {
+	var b bytes.Buffer
+
+	ids := make([]string, 0, len(ring.keys))
+	for id := range ring.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fmt.Fprintf(&b, "KeyRing{active: %s, keys: %v}", ring.activeID, ids)
+
+	return string(b.Bytes())
+}
+
+// ParseKeyRing parses keys in the form of 'id:base64-key', separated by
+// newlines or ','. Empty lines and lines starting with '#' are ignored.
+// The last key is the active one, so a key is rotated by appending a new
+// key, and the old keys are kept to read the backups.
+func ParseKeyRing(text string) (*KeyRing, error) {
+	ring := NewKeyRing()
+	entries := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == ','
+	})
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry[0] == '#' {
+			continue
+		}
+
+		idx := strings.IndexByte(entry, _KEY_SEP)
+		if idx == -1 {
+			return nil, goutils.NewErr("key isn't in the form of 'id:base64-key'")
+		}
+
+		id := entry[:idx]
+		key, err := base64.StdEncoding.DecodeString(entry[idx+1:])
+		if err != nil {
+			return nil, goutils.WrapErrorf(err, "invalid base64 key, id: %s", id)
+		}
+
+		if err := ring.AddKey(id, key); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to add key")
+		}
+		ring.activeID = id
+	}
+
+	if len(ring.keys) == 0 {
+		return nil, goutils.NewErr("no key found")
+	}
+
+	return ring, nil
+}
+
+// LoadKeyFile loads keys from a file in the format of ParseKeyRing.
+func LoadKeyFile(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to read key file, file: %s", path)
+	}
+
+	ring, err := ParseKeyRing(string(data))
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse key file, file: %s", path)
+	}
+
+	return ring, nil
+}
+
+// LoadKeyEnv loads keys from an environment variable in the format of
+// ParseKeyRing.
+func LoadKeyEnv(name string) (*KeyRing, error) {
+	text, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, goutils.NewErr("environment variable isn't set, name: %s", name)
+	}
+
+	ring, err := ParseKeyRing(text)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse keys, env: %s", name)
+	}
+
+	return ring, nil
+}
+
+// encryptedWriter seals each write as a chunk. It's used under the buffer
+// of a FileHandler, so a chunk is a flush of the buffer.
+type encryptedWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte // version, key id length and key id
+	chunk  []byte // reused for each write
+}
+
+func newEncryptedWriter(w io.Writer, ring *KeyRing) *encryptedWriter {
+	id := ring.activeID
+	header := make([]byte, 0, 2+len(id))
+	header = append(header, _CRYPT_VERSION, byte(len(id)))
+	header = append(header, id...)
+
+	return &encryptedWriter{w: w, aead: ring.keys[id], header: header}
+}
+
+func (w *encryptedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	nonceSize := w.aead.NonceSize()
+	chunk := append(w.chunk[:0], 0, 0, 0, 0)
+	chunk = append(chunk, w.header...)
+	nonceStart := len(chunk)
+	chunk = append(chunk, make([]byte, nonceSize)...)
+	if _, err := io.ReadFull(rand.Reader, chunk[nonceStart:]); err != nil {
+		return 0, goutils.WrapErrorf(err, "failed to generate nonce")
+	}
+
+	chunk = w.aead.Seal(chunk, chunk[nonceStart:], p, w.header)
+	binary.BigEndian.PutUint32(chunk, uint32(len(chunk)-_CRYPT_LEN_SIZE))
+	w.chunk = chunk
+
+	if n, err := w.w.Write(chunk); err != nil {
+		return partlyWritten(n, len(p)), goutils.WrapErrorf(err, "failed to write encrypted chunk")
+	}
+
+	return len(p), nil
+}
+
+// decryptReader reads the plaintext of an encrypted log file.
+type decryptReader struct {
+	r      *bufio.Reader
+	ring   *KeyRing
+	plain  []byte
+	offset int64 // offset of the next chunk in the file
+}
+
+// NewDecryptReader returns a reader of the plaintext of an encrypted log
+// file, which decrypts a chunk at a time. Chunks can be encrypted by any
+// key in 'ring'.
+func NewDecryptReader(r io.Reader, ring *KeyRing) io.Reader {
+	return &decryptReader{r: bufio.NewReader(r), ring: ring}
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+func (r *decryptReader) readChunk() error {
+	var lenBuf [_CRYPT_LEN_SIZE]byte
+	if _, err := io.ReadFull(r.r, lenBuf[:]); err == io.EOF {
+		return io.EOF
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "truncated chunk, offset: %d", r.offset)
+	}
+
+	size := binary.BigEndian.Uint32(lenBuf[:])
+	if size < 2 || size > _MAX_CHUNK_BYTES {
+		return goutils.NewErr("invalid chunk length: %d, offset: %d", size, r.offset)
+	}
+
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(r.r, chunk); err != nil {
+		return goutils.WrapErrorf(err, "truncated chunk, offset: %d", r.offset)
+	}
+
+	if chunk[0] != _CRYPT_VERSION {
+		return goutils.NewErr("unknown chunk version: %d, offset: %d", chunk[0], r.offset)
+	}
+
+	headerLen := 2 + int(chunk[1])
+	if headerLen > len(chunk) {
+		return goutils.NewErr("invalid key id length, offset: %d", r.offset)
+	}
+	id := string(chunk[2:headerLen])
+
+	aead, ok := r.ring.keys[id]
+	if !ok {
+		return goutils.NewErr("no key named '%s', offset: %d", id, r.offset)
+	}
+
+	nonceEnd := headerLen + aead.NonceSize()
+	if nonceEnd > len(chunk) {
+		return goutils.NewErr("truncated nonce, offset: %d", r.offset)
+	}
+
+	plain, err := aead.Open(chunk[nonceEnd:nonceEnd], chunk[headerLen:nonceEnd], chunk[nonceEnd:],
+		chunk[:headerLen])
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to decrypt chunk, key: %s, offset: %d", id, r.offset)
+	}
+
+	r.plain = plain
+	r.offset += int64(_CRYPT_LEN_SIZE) + int64(size)
+
+	return nil
+}
+
+// truncateTornChunk truncates the chunk at the end of the encrypted file
+// which is partially written. Only the lengths of the chunks are read.
+func truncateTornChunk(file *os.File) error {
+	r, err := os.Open(file.Name())
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open file, file: %s", file.Name())
+	}
+	defer r.Close()
+
+	info, err := r.Stat()
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to stat file, file: %s", file.Name())
+	}
+
+	end, err := chunksEnd(r, info.Size())
+	if err != nil {
+		return err
+	}
+	if end == info.Size() {
+		return nil
+	}
+
+	if err := file.Truncate(end); err != nil {
+		return goutils.WrapErrorf(err, "failed to truncate torn chunk, file: %s, offset: %d", file.Name(), end)
+	}
+
+	return nil
+}
+
+// chunksEnd returns the end of the last complete chunk of the file of
+// 'size' bytes. Corrupted lengths aren't taken as a torn chunk, and are left
+// to be reported by the reader.
+func chunksEnd(r io.ReaderAt, size int64) (int64, error) {
+	var lenBuf [_CRYPT_LEN_SIZE]byte
+	offset := int64(0)
+	for size-offset >= _CRYPT_LEN_SIZE {
+		if _, err := r.ReadAt(lenBuf[:], offset); err != nil {
+			return 0, goutils.WrapErrorf(err, "failed to read chunk length, offset: %d", offset)
+		}
+
+		chunkSize := binary.BigEndian.Uint32(lenBuf[:])
+		if chunkSize < 2 || chunkSize > _MAX_CHUNK_BYTES {
+			return size, nil
+		}
+
+		next := offset + _CRYPT_LEN_SIZE + int64(chunkSize)
+		if next > size {
+			break
+		}
+		offset = next
+	}
+
+	return offset, nil
+}
+
+// lastDecryptedLine returns the last line of an encrypted file without the
+// newline, the whole file is decrypted.
+func lastDecryptedLine(path string, ring *KeyRing) ([]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to open file, file: %s", path)
+	}
+	defer f.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(NewDecryptReader(f, ring))
+	scanner.Buffer(make([]byte, 0, 64*1024), _MAX_CHUNK_BYTES)
+	for scanner.Scan() {
+		last = append(last[:0], scanner.Bytes()...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to decrypt file, file: %s", path)
+	}
+
+	return last, nil
+}
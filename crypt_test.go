@@ -0,0 +1,129 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 19:40:26
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKey1 = "k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	testKey2 = "k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func newEncryptedHandler(t *testing.T, fileName, keys string) *SizeRotateFileHandler {
+	ring, err := ParseKeyRing(keys)
+	if err != nil {
+		t.Fatalf("failed to parse keys, err: %s", err.Error())
+	}
+
+	handler, err := NewSizeRotateFileHandler(fileName, MB, 10)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	handler.EnableBuffer(BufferConfig{Size: 4096, FlushInterval: time.Hour})
+	if err := handler.EnableEncryption(ring); err != nil {
+		t.Fatalf("failed to enable encryption, err: %s", err.Error())
+	}
+	if err := handler.EnableHashChain(); err != nil {
+		t.Fatalf("failed to enable hash chain, err: %s", err.Error())
+	}
+
+	return handler
+}
+
+func decryptTestFile(t *testing.T, path string, ring *KeyRing) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file, err: %s", err.Error())
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(NewDecryptReader(f, ring))
+	return string(data), err
+}
+
+func TestEncryption(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "secret.log")
+	msg := newTestMsg()
+
+	handler := newEncryptedHandler(t, fileName, testKey1)
+	handler.Handle(msg)
+	if _, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE); err != nil {
+		t.Fatalf("failed to rotate, err: %s", err.Error())
+	}
+	handler.Handle(msg)
+	handler.Close()
+
+	// Key is rotated, the active file is appended with the new key.
+	handler = newEncryptedHandler(t, fileName, testKey1+"\n"+testKey2)
+	handler.Handle(msg)
+	handler.Close()
+
+	raw, _ := os.ReadFile(fileName)
+	if bytes.Contains(raw, msg.message) {
+		t.Errorf("message is written in plaintext")
+	}
+
+	ring, _ := ParseKeyRing(testKey1 + "," + testKey2)
+	plain, err := decryptTestFile(t, fileName, ring)
+	if err != nil {
+		t.Fatalf("failed to decrypt, err: %s", err.Error())
+	}
+	if strings.Count(plain, string(msg.message)) != 2 {
+		t.Errorf("unexpected plaintext: %q", plain)
+	}
+
+	oldRing, _ := ParseKeyRing(testKey1)
+	if _, err := decryptTestFile(t, fileName, oldRing); err == nil {
+		t.Errorf("chunks of an unknown key shouldn't be decrypted")
+	}
+
+	paths, _ := LogFiles(fileName, DEFAULT_SIZE_BACKUP_PATTERN)
+	if report, err := VerifyWithKeys(ring, paths...); err != nil || report.Lines != 3 {
+		t.Errorf("failed to verify encrypted audit logs, report: %v, err: %v", report, err)
+	}
+}
+
+// A chunk torn by a crash is truncated before the file is appended.
+func TestEncryptionTornChunk(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "secret.log")
+	msg := newTestMsg()
+	for i := 0; i < 2; i++ {
+		handler := newEncryptedHandler(t, fileName, testKey1)
+		handler.Handle(msg)
+		handler.Close()
+	}
+
+	info, _ := os.Stat(fileName)
+	if err := os.Truncate(fileName, info.Size()-5); err != nil {
+		t.Fatalf("failed to truncate file, err: %s", err.Error())
+	}
+
+	handler := newEncryptedHandler(t, fileName, testKey1)
+	handler.Handle(msg)
+	handler.Close()
+
+	ring, _ := ParseKeyRing(testKey1)
+	plain, err := decryptTestFile(t, fileName, ring)
+	if err != nil {
+		t.Fatalf("failed to decrypt, err: %s", err.Error())
+	}
+	if strings.Count(plain, string(msg.message)) != 2 {
+		t.Errorf("unexpected plaintext: %q", plain)
+	}
+
+	if report, err := VerifyWithKeys(ring, fileName); err != nil || report.Lines != 2 {
+		t.Errorf("failed to verify, report: %v, err: %v", report, err)
+	}
+}
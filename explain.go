@@ -0,0 +1,75 @@
+/**
+ * Effective configs of loggers in a config file.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 00:12:37
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"strings"
+)
+
+// EffectiveLogger is the config of a logger which Load creates from a config
+// file.
+type EffectiveLogger struct {
+	Name       string
+	Level      Level
+	CallerSkip int
+	// Whether an existing logger of the name is replaced.
+	Overwrite       bool
+	RedactKeys      []string
+	RedactPatterns  []string
+	RedactDetectors []string
+	Handlers        []EffectiveHandler
+}
+
+// EffectiveHandler is the config of a handler, which is merged with the
+// configs it extends, with the placeholders interpolated and the defaults set.
+type EffectiveHandler struct {
+	Name   string
+	Config *LoggerConfig
+}
+
+// Explain returns the effective configs of the loggers in the config file,
+// in the order of 'loggers'. Nothing is created, but env vars and key files
+// are read as Load.
+func Explain(configPath string) ([]*EffectiveLogger, error) {
+	conf, err := openConf(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var loggers []*EffectiveLogger
+	err = eachLogger(conf, func(loggerName string, conf confSource, ctx loadContext) error {
+		spec, err := resolveLogger(loggerName, conf, ctx)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to resolve logger, name: %s", loggerName)
+		}
+		loggers = append(loggers, spec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loggers, nil
+}
+
+func (spec *EffectiveLogger) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "EffectiveLogger{name: %s, level: %s, callerSkip: %d, overwrite: %t, handlers: [",
+		spec.Name, spec.Level.Name(), spec.CallerSkip, spec.Overwrite)
+	names := make([]string, len(spec.Handlers))
+	for i, handler := range spec.Handlers {
+		names[i] = handler.Name
+	}
+	fmt.Fprintf(&b, "%s]}", strings.Join(names, ", "))
+
+	return string(b.Bytes())
+}
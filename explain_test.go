@@ -0,0 +1,66 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 01:41:08
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	requireYAML(t)
+
+	dir := t.TempDir()
+	config := `
+loggers:
+  explain: {level: WARN, handlers: [h, console], caller-skip: 1, overwrite: false}
+handlers:
+  base: {type: size-rotate, sync: true, formatter: f}
+  mid: {extends: base, backup-count: 3}
+  h: {extends: mid, log-path: "` + dir + `", buffer-size: 4KB}
+  console: {type: console}
+formatters:
+  f: {format: "${levelname} ${message}"}
+`
+	configPath := filepath.Join(dir, "logger.yaml")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	loggers, err := Explain(configPath)
+	if err != nil {
+		t.Fatalf("failed to explain config, err: %v", err)
+	}
+	if len(loggers) != 1 || len(loggers[0].Handlers) != 2 {
+		t.Fatalf("unexpected loggers: %v", loggers)
+	}
+
+	logger := loggers[0]
+	if logger.Name != "explain" || logger.Level != WARN || logger.CallerSkip != 1 || logger.Overwrite {
+		t.Fatalf("unexpected logger: %s", logger)
+	}
+
+	h := logger.Handlers[0].Config
+	if h.Handler != SIZE_ROTATE_HANDLER || !h.SyncMode || !h.SyncWrite || h.Format != "${levelname} ${message}" ||
+		h.BackupCount != 3 || h.LogPath != dir {
+		t.Fatalf("extends aren't merged: %+v", h)
+	}
+	if h.FileName != "explain.log" || h.MaxBytes != 100*MB || int64(h.BufferSize) != 4*KB || h.FlushInterval <= 0 {
+		t.Fatalf("defaults aren't set: %+v", h)
+	}
+	if console := logger.Handlers[1].Config; console.Handler != CONSOLE_HANDLER || console.Format != defautlFormatStr {
+		t.Fatalf("unexpected console handler: %+v", console)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "explain.log")); !os.IsNotExist(err) {
+		t.Fatalf("log file is created by Explain")
+	}
+	if _, ok := loggerMgr.logCache["explain"]; ok {
+		t.Fatalf("logger is created by Explain")
+	}
+}
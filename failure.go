@@ -0,0 +1,399 @@
+/**
+ * Handling of the errors which can't be returned to the caller, as logs are
+ * handled asynchronously, and the policies on failures of handlers.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 17:05:41
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorEvent is an internal error of gologging, e.g. a log failed to be
+// written, or the buffer failed to be flushed in the background.
+type ErrorEvent struct {
+	// Names of the logger and the handler, which are empty if the error
+	// isn't caused by handling a log.
+	Logger  string
+	Handler string
+	// What failed, e.g. 'failed to handle'.
+	Msg  string
+	Err  error
+	Time time.Time
+}
+
+func (event ErrorEvent) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "%s ", event.Time.Format("2006-01-02 15:04:05"))
+	if event.Handler != "" {
+		fmt.Fprintf(&b, "[%s/%s] ", event.Logger, event.Handler)
+	}
+	fmt.Fprintf(&b, "%s err: %s", event.Msg, event.Err.Error())
+
+	return string(b.Bytes())
+}
+
+// ErrorHandler is called with the internal errors. It may be called by
+// multiple goroutines concurrently, and it shouldn't log by gologging, which
+// may fail again.
+type ErrorHandler func(event ErrorEvent)
+
+var errorHandler atomic.Value // ErrorHandler
+
+// SetErrorHandler replaces the handler of internal errors, which prints them
+// to stderr by default. nil restores the default.
+func SetErrorHandler(handler ErrorHandler) {
+	if handler == nil {
+		handler = stdErrLog
+	}
+	errorHandler.Store(handler)
+}
+
+func stdErrLog(event ErrorEvent) {
+	os.Stderr.WriteString(event.String() + "\n")
+}
+
+func reportError(event ErrorEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	handler, _ := errorHandler.Load().(ErrorHandler)
+	if handler == nil {
+		handler = stdErrLog
+	}
+	handler(event)
+}
+
+// reportErr reports an error which isn't caused by handling a log.
+func reportErr(msg string, err error) {
+	reportError(ErrorEvent{Msg: msg, Err: err})
+}
+
+type FailurePolicy int
+
+const (
+	// The failure is reported and the log is lost.
+	FAILURE_IGNORE FailurePolicy = iota
+	// The log is handled again with exponential backoff, if nothing of it
+	// is written. Retries block the loop, and the caller in sync mode.
+	FAILURE_RETRY
+	// The log is handled by the fallback handler.
+	FAILURE_FALLBACK
+	// The handler is disabled after consecutive failures, and logs are
+	// dropped until it's enabled again by Logger.EnableHandler.
+	FAILURE_DISABLE
+)
+
+const (
+	_DEFAULT_RETRIES       = 3
+	_DEFAULT_RETRY_BACKOFF = 100 * time.Millisecond
+	_MAX_RETRY_BACKOFF     = time.Second
+	_DEFAULT_DISABLE_AFTER = 10
+)
+
+var failurePolicyNames = []string{
+	"ignore",
+	"retry",
+	"fallback",
+	"disable",
+}
+
+func NewFailurePolicyString(name string) (FailurePolicy, error) {
+	for idx, policyName := range failurePolicyNames {
+		if policyName == strings.ToLower(name) {
+			return FailurePolicy(idx), nil
+		}
+	}
+
+	return FAILURE_IGNORE, goutils.NewErr("unknown failure policy: %s", name)
+}
+
+func (policy FailurePolicy) Name() string {
+	if policy >= FAILURE_IGNORE && int(policy) < len(failurePolicyNames) {
+		return failurePolicyNames[policy]
+	}
+
+	return "UNKNOWN_FAILURE_POLICY"
+}
+
+// What a handler loop does when its handler fails to handle a log.
+type FailureConfig struct {
+	Policy FailurePolicy
+	// Times to retry by FAILURE_RETRY, and the backoff before the first
+	// retry, which is doubled for each retry up to 1s. 0 means 3 times and
+	// 100ms.
+	Retries      int
+	RetryBackoff time.Duration
+	// The handler of FAILURE_FALLBACK, which is closed with the loop if it
+	// implements io.Closer. It isn't called if the log has been written
+	// entirely, e.g. only the rotation after it failed.
+	Fallback Handler
+	// Consecutive failures to disable the handler by FAILURE_DISABLE, 0
+	// means 10. A failure after the log is written entirely doesn't disable
+	// the handler.
+	DisableAfter int
+}
+
+func (config *FailureConfig) setDefault() {
+	if config.Retries <= 0 {
+		config.Retries = _DEFAULT_RETRIES
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = _DEFAULT_RETRY_BACKOFF
+	} else if config.RetryBackoff > _MAX_RETRY_BACKOFF {
+		config.RetryBackoff = _MAX_RETRY_BACKOFF
+	}
+	if config.DisableAfter <= 0 {
+		config.DisableAfter = _DEFAULT_DISABLE_AFTER
+	}
+}
+
+// _WrittenError is an error of a handler after the log is written partly
+// or entirely, e.g. a short write, or the file failed to be synced or
+// rotated. The log isn't handled again by FAILURE_RETRY, as it would be
+// duplicated or split.
+type _WrittenError struct {
+	err error
+	// Whether the log is written entirely.
+	complete bool
+}
+
+func (err *_WrittenError) Error() string {
+	return err.err.Error()
+}
+
+func (err *_WrittenError) Unwrap() error {
+	return err.err
+}
+
+// wrapHandleErr wraps the error of handling a log, and keeps whether the
+// log is written.
+func wrapHandleErr(err error, format string, vals ...interface{}) error {
+	if written, ok := err.(*_WrittenError); ok {
+		return &_WrittenError{err: goutils.WrapErrorf(written.err, format, vals...), complete: written.complete}
+	}
+
+	return goutils.WrapErrorf(err, format, vals...)
+}
+
+// writtenState returns whether the log is written partly and entirely
+// when handling it fails with 'err'.
+func writtenState(err error) (partly, entirely bool) {
+	if written, ok := err.(*_WrittenError); ok {
+		return true, written.complete
+	}
+
+	return false, false
+}
+
+// partlyWritten returns the count of a writer wrapping another one, when
+// 'n' bytes of the transformed data are written by the inner writer. The
+// bytes of 'p' written are unknown, so 1 is returned if any byte is written.
+func partlyWritten(n, pLen int) int {
+	if n > 0 && pLen > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// asyncErrorReporter is implemented by handlers which fail out of Handle,
+// e.g. the rotation by a timer, so that the failures are reported by the
+// loop with the names and counted toward the health.
+type asyncErrorReporter interface {
+	setErrorReporter(report func(msg string, err error))
+}
+
+type HandlerHealth int
+
+const (
+	HEALTH_OK HandlerHealth = iota
+	// The last log or rotation failed to be handled.
+	HEALTH_FAILING
+	// The handler is disabled by FAILURE_DISABLE.
+	HEALTH_DISABLED
+)
+
+var handlerHealthNames = []string{
+	"ok",
+	"failing",
+	"disabled",
+}
+
+func (health HandlerHealth) Name() string {
+	if health >= HEALTH_OK && int(health) < len(handlerHealthNames) {
+		return handlerHealthNames[health]
+	}
+
+	return "UNKNOWN_HEALTH"
+}
+
+// Health of the handler of a loop. The consecutive failures are counted in
+// loopCounters to be aligned.
+type loopHealth struct {
+	disabled int32
+	mu       sync.Mutex
+	lastErr  error
+	errTime  time.Time
+}
+
+// SetFailureConfig sets what to do when the named handler fails to handle a
+// log, which is FAILURE_IGNORE by default.
+func (logger *Logger) SetFailureConfig(handlerName string, config FailureConfig) error {
+	loop := logger.handlerLoop(handlerName)
+	if loop == nil {
+		return goutils.NewErr("no handler named '%s'", handlerName)
+	}
+	if config.Policy == FAILURE_FALLBACK && config.Fallback == nil {
+		return goutils.NewErr("no fallback handler, handler: %s", handlerName)
+	}
+
+	config.setDefault()
+	loop.failure.Store(&config)
+
+	return nil
+}
+
+// EnableHandler enables the named handler disabled by FAILURE_DISABLE, and
+// resets its health.
+func (logger *Logger) EnableHandler(handlerName string) error {
+	loop := logger.handlerLoop(handlerName)
+	if loop == nil {
+		return goutils.NewErr("no handler named '%s'", handlerName)
+	}
+
+	atomic.StoreUint64(&loop.counters.failures, 0)
+	atomic.StoreInt32(&loop.health.disabled, 0)
+
+	return nil
+}
+
+func (logger *Logger) handlerLoop(name string) *handlerLoop {
+	for _, loop := range logger.handlers {
+		if loop.name == name {
+			return loop
+		}
+	}
+
+	return nil
+}
+
+func (loop *handlerLoop) failureConfig() *FailureConfig {
+	config, _ := loop.failure.Load().(*FailureConfig)
+	return config
+}
+
+// handle handles the log by the handler, and applies the failure policy if
+// it fails.
+func (loop *handlerLoop) handle(msg *_Msg) {
+	if atomic.LoadInt32(&loop.health.disabled) != 0 {
+		atomic.AddUint64(&loop.counters.dropped, 1)
+		return
+	}
+
+	start := time.Now()
+	err := loop.handler.Handle(msg)
+	loop.counters.latency.observe(time.Since(start))
+	atomic.AddUint64(&loop.counters.handled, 1)
+
+	config := loop.failureConfig()
+	if err != nil && config != nil && config.Policy == FAILURE_RETRY {
+		// Only the logs which aren't written at all are retried
+		backoff := config.RetryBackoff
+		for i := 0; i < config.Retries && err != nil; i++ {
+			if partly, _ := writtenState(err); partly {
+				break
+			}
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > _MAX_RETRY_BACKOFF {
+				backoff = _MAX_RETRY_BACKOFF
+			}
+			err = loop.handler.Handle(msg)
+		}
+	}
+
+	if err == nil {
+		atomic.StoreUint64(&loop.counters.failures, 0)
+		return
+	}
+
+	atomic.AddUint64(&loop.counters.handleErrors, 1)
+	failures := loop.recordFailure(err)
+	loop.reportError("failed to handle", err)
+
+	// The log written entirely isn't lost, only the health is affected
+	if _, entirely := writtenState(err); config == nil || entirely {
+		return
+	}
+
+	switch config.Policy {
+	case FAILURE_FALLBACK:
+		if err := config.Fallback.Handle(msg); err != nil {
+			loop.reportError("failed to handle by fallback", err)
+		}
+
+	case FAILURE_DISABLE:
+		if failures >= uint64(config.DisableAfter) &&
+			atomic.CompareAndSwapInt32(&loop.health.disabled, 0, 1) {
+			loop.reportError("handler is disabled",
+				goutils.NewErr("%d consecutive failures", failures))
+		}
+	}
+}
+
+// recordFailure counts the failure toward the health, and returns the
+// number of consecutive failures.
+func (loop *handlerLoop) recordFailure(err error) uint64 {
+	failures := atomic.AddUint64(&loop.counters.failures, 1)
+	loop.health.mu.Lock()
+	loop.health.lastErr, loop.health.errTime = err, time.Now()
+	loop.health.mu.Unlock()
+
+	return failures
+}
+
+// reportAsyncError reports the failure of the handler out of Handle.
+func (loop *handlerLoop) reportAsyncError(msg string, err error) {
+	loop.recordFailure(err)
+	loop.reportError(msg, err)
+}
+
+func (loop *handlerLoop) reportError(msg string, err error) {
+	reportError(ErrorEvent{Logger: loop.loggerName, Handler: loop.name, Msg: msg, Err: err})
+}
+
+// closeFallback closes the fallback handler, which is called after all the
+// logs are handled.
+func (loop *handlerLoop) closeFallback() error {
+	if config := loop.failureConfig(); config != nil && config.Fallback != nil {
+		if closer, ok := config.Fallback.(io.Closer); ok {
+			return closer.Close()
+		}
+	}
+
+	return nil
+}
+
+func (loop *handlerLoop) healthStatus() HandlerHealth {
+	if atomic.LoadInt32(&loop.health.disabled) != 0 {
+		return HEALTH_DISABLED
+	}
+	if atomic.LoadUint64(&loop.counters.failures) > 0 {
+		return HEALTH_FAILING
+	}
+
+	return HEALTH_OK
+}
@@ -0,0 +1,289 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 17:48:10
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A writer which fails the first 'failures' writes. Half of the data is
+// written by a failed write if 'partial' is true.
+type failingWriter struct {
+	mu       sync.Mutex
+	failures int
+	partial  bool
+	out      bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failures > 0 {
+		w.failures--
+		if w.partial {
+			n, _ := w.out.Write(p[:len(p)/2])
+			return n, errors.New("disk is full")
+		}
+		return 0, errors.New("disk is full")
+	}
+
+	return w.out.Write(p)
+}
+
+func (w *failingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.out.String()
+}
+
+func newFailingLogger(failures int) (*Logger, *failingWriter) {
+	w := &failingWriter{failures: failures}
+	handler := NewStreamHandle(w)
+	handler.SetSyncMode(true)
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+
+	logger := newLogger("test", false)
+	logger.AddHandler(handler)
+
+	return logger, w
+}
+
+func collectErrors(t *testing.T) *[]ErrorEvent {
+	var mu sync.Mutex
+	events := &[]ErrorEvent{}
+	SetErrorHandler(func(event ErrorEvent) {
+		mu.Lock()
+		*events = append(*events, event)
+		mu.Unlock()
+	})
+	t.Cleanup(func() { SetErrorHandler(nil) })
+
+	return events
+}
+
+func TestErrorHandler(t *testing.T) {
+	events := collectErrors(t)
+	logger, w := newFailingLogger(1)
+	defer logger.Close()
+
+	logger.Info("lost")
+	if len(*events) != 1 {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+	event := (*events)[0]
+	if event.Logger != "test" || event.Handler != "handler-0" ||
+		!strings.Contains(event.String(), "[test/handler-0] failed to handle err:") ||
+		!strings.Contains(event.Err.Error(), "disk is full") {
+		t.Fatalf("unexpected error event: %s", event)
+	}
+	if s := logger.Stats()[0]; s.Health != HEALTH_FAILING || s.ConsecutiveFailures != 1 ||
+		s.HandleErrors != 1 || s.WriteErrors != 1 || !strings.Contains(s.LastError, "disk is full") {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+
+	logger.Info("written")
+	if s := logger.Stats()[0]; s.Health != HEALTH_OK || s.ConsecutiveFailures != 0 || s.LastError == "" {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+	if w.String() != "written\n" {
+		t.Fatalf("unexpected output: %q", w.String())
+	}
+}
+
+func TestFailurePolicy(t *testing.T) {
+	events := collectErrors(t)
+
+	// retry
+	logger, w := newFailingLogger(2)
+	if err := logger.SetFailureConfig("handler-0",
+		FailureConfig{Policy: FAILURE_RETRY, RetryBackoff: time.Millisecond}); err != nil {
+		t.Fatalf("failed to set failure config, err: %v", err)
+	}
+	logger.Info("retried")
+	logger.Close()
+	if w.String() != "retried\n" || len(*events) != 0 {
+		t.Fatalf("unexpected output: %q, events: %v", w.String(), *events)
+	}
+
+	// A partly written log isn't retried
+	logger, w = newFailingLogger(1)
+	w.partial = true
+	if err := logger.SetFailureConfig("handler-0",
+		FailureConfig{Policy: FAILURE_RETRY, RetryBackoff: time.Millisecond}); err != nil {
+		t.Fatalf("failed to set failure config, err: %v", err)
+	}
+	logger.Info("partial")
+	logger.Close()
+	if w.String() != "part" || len(*events) != 1 {
+		t.Fatalf("unexpected output: %q, events: %v", w.String(), *events)
+	}
+	*events = nil
+
+	// fallback
+	logger, _ = newFailingLogger(1)
+	fallback := &bytes.Buffer{}
+	if err := logger.SetFailureConfig("handler-0",
+		FailureConfig{Policy: FAILURE_FALLBACK, Fallback: NewStreamHandle(fallback)}); err != nil {
+		t.Fatalf("failed to set failure config, err: %v", err)
+	}
+	logger.Info("fallen back")
+	logger.Close()
+	if !strings.HasSuffix(fallback.String(), "fallen back\n") || len(*events) != 1 {
+		t.Fatalf("unexpected fallback output: %q, events: %v", fallback.String(), *events)
+	}
+
+	// disable
+	*events = nil
+	logger, w = newFailingLogger(3)
+	defer logger.Close()
+	if err := logger.SetFailureConfig("handler-0",
+		FailureConfig{Policy: FAILURE_DISABLE, DisableAfter: 2}); err != nil {
+		t.Fatalf("failed to set failure config, err: %v", err)
+	}
+	for _, msg := range []string{"a", "b", "c"} {
+		logger.Info(msg)
+	}
+	if s := logger.Stats()[0]; s.Health != HEALTH_DISABLED || s.Dropped != 1 || s.HandleErrors != 2 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+	if len(*events) != 3 || (*events)[2].Msg != "handler is disabled" {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+
+	if err := logger.EnableHandler("handler-0"); err != nil {
+		t.Fatalf("failed to enable handler, err: %v", err)
+	}
+	logger.Info("d")
+	logger.Info("e")
+	if s := logger.Stats()[0]; s.Health != HEALTH_OK || w.String() != "e\n" {
+		t.Fatalf("unexpected stats: %s, output: %q", s, w.String())
+	}
+
+	if err := logger.SetFailureConfig("handler-1", FailureConfig{}); err == nil {
+		t.Fatalf("failure config of a missing handler should fail")
+	}
+	if err := logger.SetFailureConfig("handler-0", FailureConfig{Policy: FAILURE_FALLBACK}); err == nil {
+		t.Fatalf("fallback policy without a fallback handler should fail")
+	}
+}
+
+func TestRotateFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, err := NewSizeRotateFileHandler(path, 16, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %v", err)
+	}
+	defer handler.Close()
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+
+	if err := handler.Handle(&_Msg{level: INFO, message: []byte("0123456789")}); err != nil {
+		t.Fatalf("failed to handle, err: %v", err)
+	}
+
+	// The file can't be renamed, the log is still written and the error
+	// is returned
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove file, err: %v", err)
+	}
+	err = handler.Handle(&_Msg{level: INFO, message: []byte("abcdefghij")})
+	if _, entirely := writtenState(err); !entirely || !strings.Contains(err.Error(), "failed to rotate") {
+		t.Fatalf("rotation should fail after the log is written, err: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "abcdefghij\n" {
+		t.Fatalf("unexpected file content: %q, err: %v", data, err)
+	}
+
+	// Rotation isn't retried by the next log, but after the interval
+	if err := handler.Handle(&_Msg{level: INFO, message: []byte("klmnopqrst")}); err != nil {
+		t.Fatalf("failed to handle, err: %v", err)
+	}
+	if s := handler.Stats(); s.Rotations != 0 {
+		t.Fatalf("rotation is retried at once: %s", s)
+	}
+	handler.rotateRetry = time.Time{}
+	if err := handler.Handle(&_Msg{level: INFO, message: []byte("uvwxyz")}); err != nil {
+		t.Fatalf("failed to handle, err: %v", err)
+	}
+	if s := handler.Stats(); s.Rotations != 1 || s.Records[INFO] != 4 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+}
+
+// Failures of rotation are counted toward the health of the handler, but
+// the logs written aren't retried or handled by the fallback.
+func TestRotateFailurePolicy(t *testing.T) {
+	events := collectErrors(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, err := NewTimeRotateFileHandler(path, DAY, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %v", err)
+	}
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+	handler.SetSyncMode(true)
+
+	logger := newLogger("test", false)
+	defer logger.Close()
+	logger.AddHandler(handler)
+	if err := logger.SetFailureConfig("handler-0", FailureConfig{Policy: FAILURE_RETRY,
+		RetryBackoff: time.Millisecond}); err != nil {
+		t.Fatalf("failed to set failure config, err: %v", err)
+	}
+
+	// The file can't be renamed as it's removed
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove file, err: %v", err)
+	}
+	handler.mu.Lock()
+	handler.nextRotate = time.Now().Add(-time.Second)
+	handler.mu.Unlock()
+	logger.Info("written once")
+
+	if data := readFile(t, path); data != "written once\n" {
+		t.Fatalf("unexpected file content: %q", data)
+	}
+	if len(*events) != 1 || (*events)[0].Handler != "handler-0" ||
+		!strings.Contains((*events)[0].Err.Error(), "failed to rotate") {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+	if s := logger.Stats()[0]; s.Health != HEALTH_FAILING || s.HandleErrors != 1 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+	handler.mu.Lock()
+	retry := handler.nextRotate
+	handler.mu.Unlock()
+	if time.Until(retry) > _ROTATE_RETRY_INTERVAL {
+		t.Fatalf("failed rotation isn't retried later, next rotate: %s", retry)
+	}
+
+	// Rotation by the timer
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove file, err: %v", err)
+	}
+	handler.mu.Lock()
+	handler.nextRotate = time.Now().Add(-time.Second)
+	handler.mu.Unlock()
+	handler.onTimer()
+	if len(*events) != 2 || (*events)[1].Msg != "failed to rotate on timer" ||
+		(*events)[1].Logger != "test" || (*events)[1].Handler != "handler-0" {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+	if s := logger.Stats()[0]; s.ConsecutiveFailures != 2 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows && (!unix || aix || solaris)
+
+/**
+ * flock(2) isn't provided by syscall on these platforms, so multi-process
+ * mode is unsupported.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 21:40:12
+ */
+
+package gologging
+
+import (
+	"errors"
+	"os"
+	"runtime"
+)
+
+const _FLOCK_SUPPORTED = false
+
+var errFlockNotSupported = errors.New("flock isn't supported on " + runtime.GOOS)
+
+func lockFile(f *os.File) error {
+	return errFlockNotSupported
+}
+
+func unlockFile(f *os.File) error {
+	return errFlockNotSupported
+}
@@ -0,0 +1,29 @@
+//go:build unix && !aix && !solaris
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 13:05:21
+ */
+
+package gologging
+
+import (
+	"os"
+	"syscall"
+)
+
+const _FLOCK_SUPPORTED = true
+
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
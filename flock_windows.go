@@ -0,0 +1,26 @@
+//go:build windows
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 13:05:21
+ */
+
+package gologging
+
+import (
+	"errors"
+	"os"
+)
+
+const _FLOCK_SUPPORTED = false
+
+var errFlockNotSupported = errors.New("flock isn't supported on windows")
+
+func lockFile(f *os.File) error {
+	return errFlockNotSupported
+}
+
+func unlockFile(f *os.File) error {
+	return errFlockNotSupported
+}
@@ -8,13 +8,12 @@ package gologging
 
 import (
 	"bytes"
-	"fmt"
 	"github.com/chosen0ne/goutils"
 	"io"
 	"path"
 	"strconv"
 	"strings"
-	"time"
+	"sync"
 )
 
 const (
@@ -28,22 +27,64 @@ const (
 	_LEVELNAME        = "levelname"
 	_MESSAGE          = "message"
 	_LOGGER_NAME      = "name"
+	_TRACE_ID         = "trace_id"
+	_SPAN_ID          = "span_id"
+	_REQUEST_ID       = "request_id"
 	_ATTR_SEP         = '$'
 	_ATTR_LEFT        = '{'
 	_ATTR_RIGHT       = '}'
-	_STR_PLACE_HOLDER = "%s"
 	_NEWLINE          = '\n'
 	_FUNC_DEPTH       = 3
+	_DATE_LAYOUT      = "2006-01-02"
+	_TIME_LAYOUT      = "15:04:05"
+	_DATETIME_LAYOUT  = "2006-01-02 15:04:05"
+	_MAX_POOLED_BYTES = 64 * 1024
+	// Output of a context field which isn't set.
+	_EMPTY_FIELD = "-"
 )
 
 var (
 	defautlFormatStr string
-	attrs            map[string]interface{}
+	defaultFormatter *Formatter
+	attrs            map[string]appendFunc
+	// Attributes which depend on the caller info.
+	callerAttrs = map[string]bool{
+		_FUNCNAME: true,
+		_FILENAME: true,
+		_FILEPATH: true,
+		_LINENO:   true,
+	}
+	// Pool of buffers used to format log messages, which avoids allocation
+	// for each log.
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			b := make([]byte, 0, 512)
+			return &b
+		},
+	}
 )
 
+// appendFunc appends the value of an attribute of the message to 'dst'.
+type appendFunc func(dst []byte, msg *_Msg) []byte
+
+// A format string is compiled into a list of segments. Each segment is
+// either a literal or an attribute.
+type fmtSegment struct {
+	literal   []byte
+	attr      string // name of the attribute, empty for a literal
+	appendVal appendFunc
+}
+
 type Formatter struct {
 	formatStr string
-	valFunc   []interface{}
+	segments  []fmtSegment
+	// Whether any attribute depends on the caller info.
+	needCaller bool
+	// Fields of a JSON format, nil for a text format, see jsonfmt.go.
+	jsonFields []_JSONField
+	// Index of the segment of ${message}, -1 if there is none.
+	msgIdx    int
+	multiline MultilinePolicy
 }
 
 // New a Formatter to specify the log format.
@@ -58,37 +99,95 @@ type Formatter struct {
 //	${lineno}: The line number of the invokation in the file.
 //	${levelname}: The level of the log.
 //	${message}: The message to log
+//	${trace_id}, ${span_id}, ${request_id}: IDs extracted from the context
+//				 of the log, or '-' if not set. See ContextExtractor.
+// A format string of a JSON object makes a JSON formatter, which escapes the
+// attributes in strings, e.g.
+//	{"time": "${datetime}", "level": "${levelname}", "msg": "${message}"}
 func NewFormatter(formatStr string) (*Formatter, error) {
-	valFuncs, fmtStr, err := parseFmtStr(formatStr)
+	segments, err := parseFmtStr(formatStr)
 	if err != nil {
 		return nil, goutils.WrapErrorf(err, "failed to parse formate string, str: %s", formatStr)
 	}
 
-	formatter := &Formatter{fmtStr, valFuncs}
+	formatter := &Formatter{formatStr: formatStr, segments: segments, msgIdx: -1}
+	for i, seg := range segments {
+		if callerAttrs[seg.attr] {
+			formatter.needCaller = true
+		}
+		if seg.attr == _MESSAGE && formatter.msgIdx == -1 {
+			formatter.msgIdx = i
+		}
+	}
+	formatter.compileJSON()
 
 	return formatter, nil
 }
 
+// Format returns the formatted message in a new allocated slice.
+// AppendFormat should be used to avoid the allocation.
 func (format *Formatter) Format(msg *_Msg) []byte {
-	attrs := make([]interface{}, 0)
-	for _, attrFunc := range format.valFunc {
-		fn := attrFunc.(func(*_Msg) string)
-		attrs = append(attrs, fn(msg))
+	return format.AppendFormat(nil, msg)
+}
+
+// AppendFormat appends the formatted message to 'dst' and returns the
+// extended buffer.
+func (format *Formatter) AppendFormat(dst []byte, msg *_Msg) []byte {
+	start := len(dst)
+	for i := range format.segments {
+		seg := &format.segments[i]
+		if seg.appendVal == nil {
+			dst = append(dst, seg.literal...)
+		} else if i == format.msgIdx && format.multiline != MULTILINE_KEEP {
+			dst = format.appendMultiline(dst, start, msg)
+		} else {
+			dst = seg.appendVal(dst, msg)
+		}
 	}
 
-	outputBuf := bytes.Buffer{}
-	outputBuf.Write([]byte(""))
-	fmt.Fprintf(&outputBuf, format.formatStr, attrs...)
+	return dst
+}
+
+// NeedsCaller reports whether the format uses any of ${funcname},
+// ${filename}, ${filepath} and ${lineno}. If not, resolving of the caller
+// info is skipped.
+func (format *Formatter) NeedsCaller() bool {
+	return format.needCaller
+}
 
-	return outputBuf.Bytes()
+func (format *Formatter) String() string {
+	return format.formatStr
+}
+
+// getBuffer fetches a buffer from the pool, which must be put back by
+// putBuffer when it's no longer used.
+func getBuffer() *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	// Don't keep large buffers in the pool.
+	if cap(*b) > _MAX_POOLED_BYTES {
+		return
+	}
+	bufferPool.Put(b)
 }
 
 // Format: '${datetime} - ${filename}:${lineno} - ${levelname} - ${message}'
-// Parse the format string, to generate a format string for printf and a func to
-// evaluate the attribute value
-func parseFmtStr(fmtStr string) ([]interface{}, string, error) {
-	fmtBuf := bytes.Buffer{}
-	valFuncs := make([]interface{}, 0)
+// Parse the format string into segments of literals and attributes. A newline
+// is appended at the end.
+func parseFmtStr(fmtStr string) ([]fmtSegment, error) {
+	segments := make([]fmtSegment, 0)
+	literal := make([]byte, 0, len(fmtStr)+1)
+
+	flushLiteral := func() {
+		if len(literal) != 0 {
+			segments = append(segments, fmtSegment{literal: literal})
+			literal = make([]byte, 0, len(fmtStr)+1)
+		}
+	}
 
 	buf := bytes.NewBufferString(fmtStr)
 	// Find a attribute each round, attribute is included in '${}'
@@ -96,34 +195,23 @@ func parseFmtStr(fmtStr string) ([]interface{}, string, error) {
 		// Find '$'
 		rbytes, err := buf.ReadBytes(_ATTR_SEP)
 		if err == io.EOF {
-			if len(rbytes) != 0 {
-				wlen, err := fmtBuf.Write(rbytes)
-				if err != nil || wlen != len(rbytes) {
-					return nil, "", err
-				}
-			}
-
+			literal = append(literal, rbytes...)
 			break
 		} else if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 
 		// Find '{'
 		nbyte, err := buf.ReadByte()
 		if err != nil {
-			return nil, "", err
+			return nil, goutils.WrapErrorf(err, "'$' at the end of format")
 		}
 
 		// Output data just read except '$'
-		wlen, err := fmtBuf.Write(rbytes[:len(rbytes)-1])
-		if err != nil || wlen != len(rbytes)-1 {
-			return nil, "", err
-		}
+		literal = append(literal, rbytes[:len(rbytes)-1]...)
 
 		if nbyte != _ATTR_LEFT {
-			if err = fmtBuf.WriteByte(nbyte); err != nil {
-				return nil, "", err
-			}
+			literal = append(literal, nbyte)
 			continue
 		}
 
@@ -131,82 +219,106 @@ func parseFmtStr(fmtStr string) ([]interface{}, string, error) {
 		// Find '}'
 		rbytes, err = buf.ReadBytes(_ATTR_RIGHT)
 		if err != nil {
-			return nil, "", err
+			return nil, goutils.WrapErrorf(err, "attribute isn't closed by '}'")
 		}
 
 		// Found attr
 		attr := string(rbytes[:len(rbytes)-1])
 		valFunc, ok := attrs[attr]
 		if !ok {
-			// Not supported attribute
-			fmt.Println("not support attr:", attr, valFunc, "\n", attrs)
-			return nil, "", err
-		} else {
-			valFuncs = append(valFuncs, valFunc)
-			fmtBuf.WriteString(_STR_PLACE_HOLDER)
+			return nil, goutils.NewErr("not support attr: %s", attr)
 		}
+
+		flushLiteral()
+		segments = append(segments, fmtSegment{attr: attr, appendVal: valFunc})
 	}
-	fmtBuf.WriteByte(_NEWLINE)
+	literal = append(literal, _NEWLINE)
+	flushLiteral()
+
+	return segments, nil
+}
 
-	return valFuncs, string(fmtBuf.Bytes()), nil
+func appendDate(dst []byte, msg *_Msg) []byte {
+	return msg.time.AppendFormat(dst, _DATE_LAYOUT)
 }
 
-func getDate(_ *_Msg) string {
-	return time.Now().Format("2006-01-02")
+func appendTime(dst []byte, msg *_Msg) []byte {
+	return msg.time.AppendFormat(dst, _TIME_LAYOUT)
 }
 
-func getTime(_ *_Msg) string {
-	return time.Now().Format("15:04:05")
+func appendDateTime(dst []byte, msg *_Msg) []byte {
+	return msg.time.AppendFormat(dst, _DATETIME_LAYOUT)
 }
 
-func getDateTime(_ *_Msg) string {
-	return time.Now().Format("2006-01-02 15:04:05")
+func appendFilePath(dst []byte, msg *_Msg) []byte {
+	return append(dst, msg.fileName...)
 }
 
-func getFilePath(msg *_Msg) string {
-	return msg.fileName
+func appendFileName(dst []byte, msg *_Msg) []byte {
+	// Caller info may be left out
+	if msg.fileName == "" {
+		return dst
+	}
+
+	return append(dst, path.Base(msg.fileName)...)
 }
 
-func getFileName(msg *_Msg) string {
-	return path.Base(msg.fileName)
+func appendLineNo(dst []byte, msg *_Msg) []byte {
+	return strconv.AppendInt(dst, int64(msg.lineNo), 10)
 }
 
-func getLineNo(msg *_Msg) string {
-	return strconv.Itoa(msg.lineNo)
+func appendFuncName(dst []byte, msg *_Msg) []byte {
+	return append(dst, msg.funcName[strings.LastIndexByte(msg.funcName, '/')+1:]...)
 }
 
-func getFuncName(msg *_Msg) string {
-	parts := strings.Split(msg.funcName, "/")
-	if len(parts) <= 0 {
-		return ""
+func appendMessage(dst []byte, msg *_Msg) []byte {
+	return append(dst, msg.message...)
+}
+
+func appendLevelName(dst []byte, msg *_Msg) []byte {
+	return append(dst, msg.level.Name()...)
+}
+
+func appendLoggerName(dst []byte, msg *_Msg) []byte {
+	return append(dst, msg.loggerName...)
+}
+
+func appendContextField(dst []byte, field string) []byte {
+	if field == "" {
+		return append(dst, _EMPTY_FIELD...)
 	}
-	return parts[len(parts)-1]
+
+	return append(dst, field...)
 }
 
-func getMessage(msg *_Msg) string {
-	return string(msg.message)
+func appendTraceID(dst []byte, msg *_Msg) []byte {
+	return appendContextField(dst, msg.ctxFields.TraceID)
 }
 
-func getLevelName(msg *_Msg) string {
-	return msg.level.Name()
+func appendSpanID(dst []byte, msg *_Msg) []byte {
+	return appendContextField(dst, msg.ctxFields.SpanID)
 }
 
-func getLoggerName(msg *_Msg) string {
-	return msg.loggerName
+func appendRequestID(dst []byte, msg *_Msg) []byte {
+	return appendContextField(dst, msg.ctxFields.RequestID)
 }
 
 func init() {
-	attrs = make(map[string]interface{})
-	attrs[_DATE] = getDate
-	attrs[_TIME] = getTime
-	attrs[_DATETIME] = getDateTime
-	attrs[_FILENAME] = getFileName
-	attrs[_FILEPATH] = getFilePath
-	attrs[_FUNCNAME] = getFuncName
-	attrs[_LINENO] = getLineNo
-	attrs[_MESSAGE] = getMessage
-	attrs[_LEVELNAME] = getLevelName
-	attrs[_LOGGER_NAME] = getLoggerName
+	attrs = make(map[string]appendFunc)
+	attrs[_DATE] = appendDate
+	attrs[_TIME] = appendTime
+	attrs[_DATETIME] = appendDateTime
+	attrs[_FILENAME] = appendFileName
+	attrs[_FILEPATH] = appendFilePath
+	attrs[_FUNCNAME] = appendFuncName
+	attrs[_LINENO] = appendLineNo
+	attrs[_MESSAGE] = appendMessage
+	attrs[_LEVELNAME] = appendLevelName
+	attrs[_LOGGER_NAME] = appendLoggerName
+	attrs[_TRACE_ID] = appendTraceID
+	attrs[_SPAN_ID] = appendSpanID
+	attrs[_REQUEST_ID] = appendRequestID
 
 	defautlFormatStr = "${datetime} [${name}] ${filename}:${lineno}:${funcname} [${levelname}] ${message}"
+	defaultFormatter, _ = NewFormatter(defautlFormatStr)
 }
@@ -0,0 +1,114 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 11:02:37
+ */
+
+package gologging
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// Set by race_test.go when the race detector is enabled.
+var raceEnabled bool
+
+func newTestMsg() *_Msg {
+	return &_Msg{
+		loggerName: "test",
+		level:      INFO,
+		time:       time.Date(2026, 10, 18, 11, 2, 37, 0, time.Local),
+		message:    []byte("some message"),
+		funcName:   "github.com/chosen0ne/gologging.TestFormat",
+		fileName:   "/src/gologging/formatter_test.go",
+		lineNo:     42,
+	}
+}
+
+func TestFormat(t *testing.T) {
+	formatter, err := NewFormatter("${datetime} [${levelname}][${name}] ${filename}:${lineno}:${funcname} 100% ${message}")
+	if err != nil {
+		t.Fatalf("failed to create formatter, err: %s", err.Error())
+	}
+
+	expected := "2026-10-18 11:02:37 [INFO][test] formatter_test.go:42:gologging.TestFormat 100% some message\n"
+	if out := string(formatter.Format(newTestMsg())); out != expected {
+		t.Errorf("unexpected output, expected: %q, got: %q", expected, out)
+	}
+
+	if _, err := NewFormatter("${datetime} ${unknown}"); err == nil {
+		t.Errorf("unknown attribute should be rejected")
+	}
+}
+
+func TestFormatAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with the race detector")
+	}
+
+	formatter, _ := NewFormatter(defautlFormatStr)
+	msg := newTestMsg()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf := getBuffer()
+		*buf = formatter.AppendFormat(*buf, msg)
+		putBuffer(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendFormat should not allocate, allocs: %.1f", allocs)
+	}
+}
+
+func TestLogAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with the race detector")
+	}
+
+	logger := newDiscardLogger()
+	defer logger.Close()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info("some message")
+	})
+	if allocs != 0 {
+		t.Errorf("Logger.Info should not allocate, allocs: %.1f", allocs)
+	}
+}
+
+func newDiscardLogger() *Logger {
+	logger := newLogger("bench", false)
+	for i := 0; i < 2; i++ {
+		handler := NewStreamHandle(io.Discard)
+		handler.SetSyncMode(true)
+		formatter, _ := NewFormatter(defautlFormatStr)
+		handler.SetFormatter(formatter)
+		logger.AddHandler(handler)
+	}
+
+	return logger
+}
+
+func BenchmarkAppendFormat(b *testing.B) {
+	formatter, _ := NewFormatter(defautlFormatStr)
+	msg := newTestMsg()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := getBuffer()
+		*buf = formatter.AppendFormat(*buf, msg)
+		putBuffer(buf)
+	}
+}
+
+func BenchmarkLoggerInfo(b *testing.B) {
+	logger := newDiscardLogger()
+	defer logger.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("some message")
+	}
+}
@@ -15,30 +15,75 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
-	innerFuncNames map[string]int
+	// Messages are reused to avoid allocation for each log.
+	msgPool = sync.Pool{
+		New: func() interface{} {
+			return &_Msg{}
+		},
+	}
 )
 
 const (
 	_OPEN_FILE_FLAG = os.O_WRONLY | os.O_CREATE | os.O_APPEND
 	_OPEN_FILE_MODE = os.ModePerm & 0644
-	_SUFFIX_SEP     = "_"
-	_CALLER_SKIP    = 3
-	_CALLER_SIZE    = 10
+	_LOCK_SUFFIX    = ".lock"
+
+	// Frames of runtime.Callers, fillCaller, Logger.log and the log method,
+	// such as Logger.Info, are skipped to find the caller.
+	_CALLER_SKIP = 4
 )
 
+// A log record. One record is shared by all the handlers of a logger, so
+// handlers must not modify it or retain it after Handle returns.
 type _Msg struct {
 	loggerName string
 	level      Level
+	time       time.Time
 	message    []byte
 	funcName   string
 	fileName   string
 	lineNo     int
+	// Fields extracted from the context of the log.
+	ctxFields ContextFields
+	// Number of handlers which haven't handled the message. The message
+	// is put back to the pool when it reaches 0.
+	refs int32
+}
+
+func getMsg() *_Msg {
+	return msgPool.Get().(*_Msg)
+}
+
+// release is called by each handler after handling the message.
+func (msg *_Msg) release() {
+	if atomic.AddInt32(&msg.refs, -1) > 0 {
+		return
+	}
+
+	message := msg.message[:0]
+	if cap(message) > _MAX_POOLED_BYTES {
+		message = nil
+	}
+	*msg = _Msg{message: message}
+	msgPool.Put(msg)
+}
+
+// fillCaller fills the caller info of the message. 'skip' is the number
+// of extra frames to skip, which is used by wrappers of Logger.
+func (msg *_Msg) fillCaller(skip int) {
+	var pcs [1]uintptr
+	if runtime.Callers(_CALLER_SKIP+skip, pcs[:]) == 0 {
+		return
+	}
+
+	c := lookupCaller(pcs[0])
+	msg.funcName, msg.fileName, msg.lineNo = c.funcName, c.fileName, c.lineNo
 }
 
 // Interface to handle each log message.
@@ -50,28 +95,49 @@ type Handler interface {
 	IsSync() bool // wheather or not to synchronize log 'Emit' and 'Handle'
 }
 
+// Handlers whose output depends on the caller info implement it, so that
+// the caller info is resolved only when it's used. Handlers which don't
+// implement it are considered to need the caller info.
+type callerNeeder interface {
+	needsCaller() bool
+}
+
 // As some handlers need to do some high-cost things when
 // log is emitted, such as file rotation. So we made handlers
 // run in a goroutine that process things asynchronously,
 // which can not affect the main goroutine.
 type handlerLoop struct {
-	q       chan *_Msg
-	handler Handler
-	w       chan byte // used to make sure 'Emit' and 'Handle' are synchronous.
+	// It's the first field to be 64-bit aligned for atomic operations on
+	// 32-bit platforms.
+	counters   loopCounters
+	name       string
+	loggerName string
+	q          chan *_Msg
+	handler    Handler
+	w          chan byte // used to make sure 'Emit' and 'Handle' are synchronous.
+	done       chan byte // closed when all the messages in 'q' have been handled.
+	mu         sync.RWMutex
+	closed     bool
+	failure    atomic.Value // *FailureConfig
+	health     loopHealth
 }
 
 func NewLoop(size int, handler Handler) *handlerLoop {
 	q := make(chan *_Msg, size)
-	return &handlerLoop{q, handler, make(chan byte)}
+	loop := &handlerLoop{q: q, handler: handler, w: make(chan byte), done: make(chan byte)}
+	if reporter, ok := handler.(asyncErrorReporter); ok {
+		reporter.setErrorReporter(loop.reportAsyncError)
+	}
+
+	return loop
 }
 
 func (loop *handlerLoop) HandleLoop() {
-	for {
-		msg := <-loop.q
+	defer close(loop.done)
 
-		if err := loop.handler.Handle(msg); err != nil {
-			stdErrLog("failed to handle", err)
-		}
+	for msg := range loop.q {
+		loop.handle(msg)
+		msg.release()
 
 		if loop.handler.IsSync() {
 			// notify the goroutine which invoked 'Emit'
@@ -80,50 +146,90 @@ func (loop *handlerLoop) HandleLoop() {
 	}
 }
 
-func (loop *handlerLoop) Emit(msg *_Msg) {
-	stacks := make([]uintptr, _CALLER_SIZE)
-	n := runtime.Callers(_CALLER_SKIP, stacks)
-
-	// Find the first function which is not owned by gologging
-	for i := 0; i < n; i++ {
-		fn := runtime.FuncForPC(stacks[i])
-		funcName := fn.Name()
-		if loop.isExternalFunc(funcName) {
-			fileName, lineNo := fn.FileLine(stacks[i])
-			msg.fileName, msg.lineNo, msg.funcName = fileName, lineNo, funcName
-			break
+// Close waits for all the emitted messages to be handled, and then closes
+// the handler if it implements io.Closer. Messages emitted after Close
+// are dropped.
+func (loop *handlerLoop) Close() error {
+	loop.mu.Lock()
+	if loop.closed {
+		loop.mu.Unlock()
+		return nil
+	}
+	loop.closed = true
+	close(loop.q)
+	loop.mu.Unlock()
+
+	<-loop.done
+
+	if closer, ok := loop.handler.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return goutils.WrapErrorf(err, "failed to close handler")
 		}
 	}
 
-	loop.q <- msg
+	if err := loop.closeFallback(); err != nil {
+		return goutils.WrapErrorf(err, "failed to close fallback handler")
+	}
 
-	if loop.handler.IsSync() {
-		// wait for finish of handle
-		<-loop.w
+	return nil
+}
+
+func (loop *handlerLoop) needsCaller() bool {
+	if config := loop.failureConfig(); config != nil && config.Fallback != nil &&
+		handlerNeedsCaller(config.Fallback) {
+		return true
 	}
+
+	return handlerNeedsCaller(loop.handler)
 }
 
-func (loop *handlerLoop) isExternalFunc(funcName string) bool {
-	parts := strings.Split(funcName, "/")
-	if len(parts) <= 0 {
-		return false
+func handlerNeedsCaller(handler Handler) bool {
+	if needer, ok := handler.(callerNeeder); ok {
+		return needer.needsCaller()
 	}
 
-	_, ok := innerFuncNames[parts[len(parts)-1]]
+	return true
+}
 
-	return !ok
+func (loop *handlerLoop) Emit(msg *_Msg) {
+	loop.mu.RLock()
+	defer loop.mu.RUnlock()
+
+	if loop.closed {
+		atomic.AddUint64(&loop.counters.dropped, 1)
+		msg.release()
+		return
+	}
+
+	// The depth is counted with the log before it's sent, as the log may be
+	// taken by the loop before the depth is read after.
+	depth := len(loop.q) + 1
+	if depth > cap(loop.q) {
+		depth = cap(loop.q)
+	}
+	loop.counters.updateHighWater(depth)
+	loop.q <- msg
+
+	if loop.handler.IsSync() {
+		// wait for finish of handle
+		<-loop.w
+	}
 }
 
 // A log handler used to emit message to stream.
 type StreamHandler struct {
+	// It's the first field to be 64-bit aligned for atomic operations on
+	// 32-bit platforms.
+	counters  handlerCounters
 	output    io.Writer
 	formatter *Formatter
 	level     Level
 	isSync    bool
+	limit     messageLimit
 }
 
 func NewStreamHandle(out io.Writer) *StreamHandler {
-	handler := StreamHandler{out, nil, INFO, false}
+	handler := StreamHandler{output: out, level: INFO}
 
 	return &handler
 }
@@ -136,18 +242,36 @@ func (handler *StreamHandler) SetLevel(level Level) {
 	handler.level = level
 }
 
+// getFormatter returns the default formatter if formatter isn't set.
+// The handler isn't modified, as it's read by the goroutine emitting logs.
+func (handler *StreamHandler) getFormatter() *Formatter {
+	if handler.formatter == nil {
+		return defaultFormatter
+	}
+
+	return handler.formatter
+}
+
+func (handler *StreamHandler) needsCaller() bool {
+	return handler.getFormatter().NeedsCaller()
+}
+
 func (handler *StreamHandler) Handle(msg *_Msg) error {
 	if msg.level < handler.level {
 		return nil
 	}
 
-	if handler.formatter == nil {
-		handler.formatter, _ = NewFormatter(defautlFormatStr)
+	buf := getBuffer()
+	*buf = handler.appendFormat(*buf, msg)
+	n, err := handler.output.Write(*buf)
+	handler.counters.countWrite(msg.level, n, err)
+	putBuffer(buf)
+	if err != nil && n > 0 {
+		return &_WrittenError{err: goutils.WrapErrorf(err, "failed to write, written: %d", n)}
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "failed to write")
 	}
 
-	logMsg := handler.formatter.Format(msg)
-	handler.output.Write(logMsg)
-
 	return nil
 }
 
@@ -178,12 +302,38 @@ type FileHandler struct {
 	fileName    string
 	file        *os.File
 	isSyncWrite bool
+	// If buffer is enabled, writes are buffered and flushed periodically
+	// instead of writing to the file directly.
+	buffer *bufferedWriter
+	// In multi-process mode, rotation is coordinated among processes
+	// writing the same file by flock on 'lockFile'.
+	multiProcess bool
+	lockFile     *os.File
+	// Names of the backups when the file is rotated.
+	namer *backupNamer
+	// A symbolic link always pointing to the active file, empty if not used.
+	symlink string
+	// Hooks called after rotation, nil if there is no hook.
+	hooks *rotateHookRunner
+	// Mode and ownership of the files, nil to use the default mode.
+	perm *FilePerm
+	// If hash chain is enabled, logs are written through it to the file
+	// or the buffer.
+	chain *hashChain
+	// Keys to encrypt the file, nil if encryption isn't enabled.
+	keys *KeyRing
 }
 
+// NewFileHandler opens the file for appending. Missing directories of the
+// file are created.
 func NewFileHandler(fileName string) (*FileHandler, error) {
-	file, err := os.OpenFile(fileName, _OPEN_FILE_FLAG, _OPEN_FILE_MODE)
+	if err := prepareLogDir(fileName, nil); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to prepare log dir")
+	}
+
+	file, err := openLogFile(fileName, nil)
 	if err != nil {
-		return nil, goutils.WrapErrorf(err, "failed to open file, file: %s", fileName)
+		return nil, goutils.WrapErrorf(err, "failed to open log file")
 	}
 
 	streamHandler := NewStreamHandle(file)
@@ -200,25 +350,546 @@ func (handler *FileHandler) SyncWrite(isSync bool) {
 	handler.isSyncWrite = isSync
 }
 
+// EnableBuffer makes the handler write to a buffer, which is flushed to
+// the file when it's full, every 'config.FlushInterval', before rotation
+// and when the handler is closed.
+func (handler *FileHandler) EnableBuffer(config BufferConfig) {
+	if handler.buffer != nil {
+		handler.buffer.Close()
+	}
+
+	handler.buffer = newBufferedWriter(handler.file, handler.fileWriter(handler.file), config)
+	handler.setFileOutput(handler.buffer)
+}
+
+// EnableEncryption encrypts the file by the active key of 'ring', see
+// crypt.go for the format. Each write to the file is sealed as a chunk, so
+// the buffer should be enabled to make chunks larger than a log. The files
+// can be read by NewDecryptReader with the keys.
+func (handler *FileHandler) EnableEncryption(ring *KeyRing) error {
+	if ring == nil || ring.activeID == "" {
+		return goutils.NewErr("no active key to encrypt")
+	}
+	if handler.chain != nil {
+		return goutils.NewErr("encryption must be enabled before hash chain")
+	}
+
+	handler.keys = ring
+	if err := handler.repairEncryptedFile(); err != nil {
+		return err
+	}
+	w := handler.fileWriter(handler.file)
+	if handler.buffer != nil {
+		return handler.buffer.Reset(handler.file, w)
+	}
+	handler.setFileOutput(w)
+
+	return nil
+}
+
+// repairEncryptedFile truncates the chunk torn at the end of the encrypted
+// file before it's appended. It's skipped in multi-process mode, as the
+// chunk may be being written by another process.
+func (handler *FileHandler) repairEncryptedFile() error {
+	if handler.keys == nil || handler.multiProcess {
+		return nil
+	}
+
+	if err := truncateTornChunk(handler.file); err != nil {
+		return goutils.WrapErrorf(err, "failed to repair encrypted file")
+	}
+
+	return nil
+}
+
+// fileWriter returns the writer of 'file', which encrypts the data if
+// encryption is enabled.
+func (handler *FileHandler) fileWriter(file *os.File) io.Writer {
+	if handler.keys != nil {
+		return newEncryptedWriter(file, handler.keys)
+	}
+
+	return file
+}
+
+// EnableHashChain makes the file a tamper-evident audit log. Each line ends
+// with a hash over the hash of the previous line and the line, see audit.go.
+// The chain continues from the existing file or the head persisted at the
+// last rotation, and it can be checked by Verify. It can't be used in
+// multi-process mode, as the chain can't be shared by processes.
+func (handler *FileHandler) EnableHashChain() error {
+	if handler.chain != nil {
+		return nil
+	}
+	if handler.multiProcess {
+		return goutils.NewErr("hash chain can't be used in multi-process mode")
+	}
+
+	chain, err := newHashChain(handler.fileName, handler.keys)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to create hash chain")
+	}
+	chain.w = handler.output
+	handler.chain = chain
+	handler.SetOutput(chain)
+
+	return nil
+}
+
+// setFileOutput makes logs written to 'w', through the hash chain if it's
+// enabled.
+func (handler *FileHandler) setFileOutput(w io.Writer) {
+	if handler.chain != nil {
+		handler.chain.w = w
+		return
+	}
+
+	handler.SetOutput(w)
+}
+
 func (handler *FileHandler) Handle(msg *_Msg) error {
 	if err := handler.StreamHandler.Handle(msg); err != nil {
-		return goutils.WrapErrorf(err, "failed to handle")
+		return wrapHandleErr(err, "failed to handle")
+	}
+
+	return handler.syncAfterWrite(msg)
+}
+
+// syncAfterWrite makes the written message durable according to the
+// sync write mode and the fsync policy of the buffer.
+func (handler *FileHandler) syncAfterWrite(msg *_Msg) error {
+	if handler.buffer != nil {
+		if handler.isSyncWrite ||
+			(handler.buffer.config.Fsync == FSYNC_ON_ERROR && msg.level >= ERROR) {
+			if err := handler.buffer.Sync(); err != nil {
+				return &_WrittenError{err: goutils.WrapErrorf(err, "failed to sync buffer"), complete: true}
+			}
+		}
+
+		return nil
 	}
 
 	if handler.isSyncWrite {
 		if err := handler.file.Sync(); err != nil {
-			return goutils.WrapErrorf(err, "failed to sync")
+			return &_WrittenError{err: goutils.WrapErrorf(err, "failed to sync"), complete: true}
+		}
+	}
+
+	return nil
+}
+
+// EnableMultiProcess makes the handler safe to write the same file with
+// other processes. Rotation is done while holding an advisory lock on the
+// file '${fileName}.lock', and the file is reopened instead of rotated again
+// if it has been rotated by another process. Each log is written to the file
+// by one write with O_APPEND, so logs shorter than PIPE_BUF are never
+// interleaved.
+func (handler *FileHandler) EnableMultiProcess() error {
+	if handler.multiProcess {
+		return nil
+	}
+	if handler.chain != nil {
+		return goutils.NewErr("multi-process mode can't be used with hash chain")
+	}
+	if !_FLOCK_SUPPORTED {
+		return goutils.NewErr("multi-process mode is unsupported on %s", runtime.GOOS)
+	}
+
+	lockName := handler.fileName + _LOCK_SUFFIX
+	lockFile, err := os.OpenFile(lockName, os.O_RDWR|os.O_CREATE, handler.perm.fileMode())
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open lock file, file: %s", lockName)
+	}
+
+	if err := applyFilePerm(lockFile, handler.perm); err != nil {
+		lockFile.Close()
+		return goutils.WrapErrorf(err, "failed to set permission of lock file")
+	}
+
+	handler.lockFile = lockFile
+	handler.multiProcess = true
+
+	return nil
+}
+
+// rotate calls 'doRotate' to rotate the file. In multi-process mode, it's
+// called with the lock held, and the file is reopened without calling
+// 'doRotate' if the file has been rotated by another process.
+func (handler *FileHandler) rotate(doRotate func() error) error {
+	if !handler.multiProcess {
+		return doRotate()
+	}
+
+	if err := lockFile(handler.lockFile); err != nil {
+		return goutils.WrapErrorf(err, "failed to lock, file: %s", handler.lockFile.Name())
+	}
+	defer unlockFile(handler.lockFile)
+
+	rotated, err := handler.rotatedByOthers()
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to check rotation")
+	}
+
+	if !rotated {
+		return doRotate()
+	}
+
+	if err := handler.closeFile(); err != nil {
+		return goutils.WrapErrorf(err, "failed to close file")
+	}
+
+	return handler.reopenFile()
+}
+
+// rotatedByOthers checks whether the opened file is still the file named
+// 'fileName', by comparing the inodes.
+func (handler *FileHandler) rotatedByOthers() (bool, error) {
+	openedInfo, err := handler.file.Stat()
+	if err != nil {
+		return false, goutils.WrapErrorf(err, "failed to stat opened file")
+	}
+
+	info, err := os.Stat(handler.fileName)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, goutils.WrapErrorf(err, "failed to stat file, file: %s", handler.fileName)
+	}
+
+	return !os.SameFile(openedInfo, info), nil
+}
+
+// fileSize returns the size of the opened file, including the buffered
+// bytes.
+func (handler *FileHandler) fileSize() (int64, error) {
+	info, err := handler.file.Stat()
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "failed to stat file")
+	}
+
+	size := info.Size()
+	if handler.buffer != nil {
+		size += int64(handler.buffer.Buffered())
+	}
+
+	return size, nil
+}
+
+// Flush writes the buffered logs to the file. It's a no-op if the buffer
+// isn't enabled.
+func (handler *FileHandler) Flush() error {
+	if handler.buffer == nil {
+		return nil
+	}
+
+	return handler.buffer.Flush()
+}
+
+// Close flushes the buffer and closes the file. It waits for the pending
+// rotate hooks as well.
+func (handler *FileHandler) Close() error {
+	if handler.hooks != nil {
+		handler.hooks.Close()
+	}
+
+	if handler.buffer != nil {
+		if err := handler.buffer.Close(); err != nil {
+			return goutils.WrapErrorf(err, "failed to close buffer")
 		}
 	}
 
+	if handler.chain != nil {
+		if err := handler.chain.saveHead(handler.perm); err != nil {
+			return goutils.WrapErrorf(err, "failed to save chain head")
+		}
+	}
+
+	if err := handler.file.Close(); err != nil {
+		return goutils.WrapErrorf(err, "failed to close file, file: %s", handler.fileName)
+	}
+
+	if handler.lockFile != nil {
+		return handler.lockFile.Close()
+	}
+
+	return nil
+}
+
+// SetFilePerm sets the mode and ownership of the file, which are applied
+// to the opened file and the files created on rotation. To make sure a new
+// file is never more permissive than 'perm', use LoggerConfig instead, which
+// creates the file with 'perm' before it's opened.
+func (handler *FileHandler) SetFilePerm(perm FilePerm) error {
+	if err := applyFilePerm(handler.file, &perm); err != nil {
+		return goutils.WrapErrorf(err, "failed to set permission of file")
+	}
+	handler.perm = &perm
+
+	return nil
+}
+
+// SetBackupPattern sets the pattern of backup names, which is also used to
+// find the backups to remove. See backupNamer for the placeholders.
+func (handler *FileHandler) SetBackupPattern(pattern string) error {
+	namer, err := newBackupNamer(pattern, handler.fileName)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to parse backup pattern")
+	}
+	handler.namer = namer
+
+	return nil
+}
+
+// SetSymlink makes a symbolic link at 'link' always point to the active
+// file, which is useful for tailing tools. A relative 'link' is relative
+// to the directory of the log file.
+func (handler *FileHandler) SetSymlink(link string) error {
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(filepath.Dir(handler.fileName), link)
+	}
+	handler.symlink = link
+
+	return handler.updateSymlink()
+}
+
+// updateSymlink replaces the symbolic link atomically by renaming a new
+// created link.
+func (handler *FileHandler) updateSymlink() error {
+	if handler.symlink == "" {
+		return nil
+	}
+
+	target := handler.fileName
+	if filepath.Dir(target) == filepath.Dir(handler.symlink) {
+		target = filepath.Base(target)
+	}
+
+	tmpLink := fmt.Sprintf("%s.%d.tmp", handler.symlink, os.Getpid())
+	os.Remove(tmpLink)
+	if err := os.Symlink(target, tmpLink); err != nil {
+		return goutils.WrapErrorf(err, "failed to create symlink, link: %s", tmpLink)
+	}
+
+	if err := os.Rename(tmpLink, handler.symlink); err != nil {
+		os.Remove(tmpLink)
+		return goutils.WrapErrorf(err, "failed to rename symlink, link: %s", handler.symlink)
+	}
+
+	return nil
+}
+
+// OnRotate adds a hook which is called after the file is rotated. Hooks are
+// called in a separate goroutine, in the order of rotations.
+func (handler *FileHandler) OnRotate(hook RotateHook) {
+	if handler.hooks == nil {
+		handler.hooks = newRotateHookRunner()
+	}
+	handler.hooks.Add(hook)
+}
+
+func (handler *FileHandler) fireRotateHooks(event RotateEvent) {
+	if handler.hooks != nil {
+		handler.hooks.Fire(event)
+	}
+}
+
+// rotateFile renames the file to a backup named by the backup pattern, and
+// reopens the file. Backups are removed from the oldest to keep at most
+// 'backupCount' backups. The path of the new backup is returned. If it fails
+// after the file is closed, e.g. the file can't be renamed, the file is
+// reopened to keep logging to it.
+func (handler *FileHandler) rotateFile(
+	now time.Time,
+	backupCount uint16,
+	reason RotateReason) (_ string, rotateErr error) {
+
+	if err := handler.closeFile(); err != nil {
+		return "", goutils.WrapErrorf(err, "failed to close file")
+	}
+
+	reopened := false
+	defer func() {
+		if rotateErr != nil && !reopened {
+			if err := handler.reopenFile(); err != nil {
+				rotateErr = goutils.WrapErrorf(rotateErr, "failed to reopen file after: %s", err.Error())
+			}
+		}
+	}()
+
+	backups, err := handler.namer.List()
+	if err != nil {
+		return "", goutils.WrapErrorf(err, "failed to list backups, file: %s", handler.fileName)
+	}
+
+	// Make sure there are 'backupCount' backups at most
+	rmCount := len(backups) - int(backupCount) + 1
+	if rmCount > len(backups) {
+		rmCount = len(backups)
+	}
+	for i := 0; i < rmCount; i++ {
+		if err := os.Remove(backups[i].path); err != nil {
+			return "", goutils.WrapErrorf(err, "failed to remove file, file: %s", backups[i].path)
+		}
+		if err := removeChecksum(backups[i].path); err != nil {
+			reportErr("failed to remove checksum of backup", err)
+		}
+	}
+	if rmCount > 0 {
+		backups = backups[rmCount:]
+	}
+
+	var seq int
+	if handler.namer.hasDate {
+		// Sequence number is used to distinguish backups rotated in
+		// the same time unit of the date layout.
+		date := handler.namer.Name(now, 0)
+		for _, backup := range backups {
+			if handler.namer.Name(backup.time, 0) == date && backup.seq > seq {
+				seq = backup.seq
+			}
+		}
+		seq++
+	} else {
+		// Backups are numbered from 1 and the newest has the max number.
+		for i, backup := range backups {
+			if backup.seq == i+1 {
+				continue
+			}
+
+			dest := handler.namer.Path(now, i+1)
+			if err := os.Rename(backup.path, dest); err != nil {
+				return "", goutils.WrapErrorf(err, "failed to rename file, src: %s, dist: %s",
+					backup.path, dest)
+			}
+			if err := renameChecksum(backup.path, dest); err != nil {
+				reportErr("failed to rename checksum of backup", err)
+			}
+		}
+		seq = len(backups) + 1
+	}
+
+	// Never overwrite a backup, which may be renamed by another process, or
+	// not be listed as it doesn't match the pattern any more.
+	backupPath := handler.namer.Path(now, seq)
+	for {
+		if _, err := os.Lstat(backupPath); os.IsNotExist(err) {
+			break
+		}
+		seq++
+		backupPath = handler.namer.Path(now, seq)
+	}
+	if err := os.Rename(handler.fileName, backupPath); err != nil {
+		return "", goutils.WrapErrorf(err, "failed to rename file, src: %s, dist: %s",
+			handler.fileName, backupPath)
+	}
+
+	if err := handler.reopenFile(); err != nil {
+		return "", goutils.WrapErrorf(err, "failed to reopen file")
+	}
+	reopened = true
+
+	if handler.chain != nil {
+		// The new file is empty, so the head is persisted to continue the
+		// chain after restart.
+		if err := handler.chain.saveHead(handler.perm); err != nil {
+			return "", goutils.WrapErrorf(err, "failed to save chain head")
+		}
+	}
+
+	atomic.AddUint64(&handler.counters.rotations, 1)
+
+	event := RotateEvent{Path: handler.fileName, BackupPath: backupPath, Reason: reason, Time: now}
+	if info, err := os.Stat(backupPath); err == nil {
+		event.Bytes = info.Size()
+	}
+	handler.fireRotateHooks(event)
+
+	return backupPath, nil
+}
+
+// rotateIfNotEmpty rotates the file for 'reason' unless it's empty, and
+// reports whether it's rotated.
+func (handler *FileHandler) rotateIfNotEmpty(
+	now time.Time,
+	backupCount uint16,
+	reason RotateReason) (bool, error) {
+
+	size, err := handler.fileSize()
+	if err != nil {
+		return false, goutils.WrapErrorf(err, "failed to get file size")
+	}
+	if size == 0 {
+		return false, nil
+	}
+
+	err = handler.rotate(func() error {
+		_, err := handler.rotateFile(now, backupCount, reason)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// modTime returns the modification time of a non-empty file, or zero time
+// if the file is empty.
+func (handler *FileHandler) modTime() (time.Time, error) {
+	info, err := handler.file.Stat()
+	if err != nil {
+		return time.Time{}, goutils.WrapErrorf(err, "failed to stat file, file: %s", handler.fileName)
+	}
+
+	if info.Size() == 0 {
+		return time.Time{}, nil
+	}
+
+	return info.ModTime(), nil
+}
+
+// closeFile flushes the buffer and closes the file before it's rotated.
+func (handler *FileHandler) closeFile() error {
+	if err := handler.Flush(); err != nil {
+		return goutils.WrapErrorf(err, "failed to flush")
+	}
+
+	return handler.file.Close()
+}
+
+// reopenFile opens the file by name again after it's rotated.
+func (handler *FileHandler) reopenFile() error {
+	file, err := openLogFile(handler.fileName, handler.perm)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open log file")
+	}
+	handler.file = file
+	if err := handler.repairEncryptedFile(); err != nil {
+		return err
+	}
+
+	if err := handler.updateSymlink(); err != nil {
+		return goutils.WrapErrorf(err, "failed to update symlink")
+	}
+
+	w := handler.fileWriter(file)
+	if handler.buffer != nil {
+		return handler.buffer.Reset(file, w)
+	}
+	handler.setFileOutput(w)
+
 	return nil
 }
 
 func (handler *FileHandler) String() string {
 	var b bytes.Buffer
 
-	fmt.Fprintf(&b, "FileHandler{StreamHandler: %s, fname: %s, syncWrite: %t}",
-		handler.StreamHandler.String(), handler.fileName, handler.isSyncWrite)
+	fmt.Fprintf(&b, "FileHandler{StreamHandler: %s, fname: %s, syncWrite: %t, multiProcess: %t",
+		handler.StreamHandler.String(), handler.fileName, handler.isSyncWrite, handler.multiProcess)
+	if handler.buffer != nil {
+		fmt.Fprintf(&b, ", buffer: %s", handler.buffer.String())
+	}
+	b.WriteString("}")
 
 	return string(b.Bytes())
 }
@@ -231,14 +902,40 @@ const (
 	DAY       RotateInterval = 86400
 	MINUTE    RotateInterval = 60
 	HALF_HOUR RotateInterval = 1800
+	WEEK      RotateInterval = 7 * DAY
+	// Average length of a Gregorian month. Intervals of multiple MONTH are
+	// aligned to calendar months.
+	MONTH RotateInterval = 2629746
 )
 
-// A file handler which supports rotation by time interval.
+// A failed rotation is retried after the interval, instead of by each log
+// or at the next rotation time.
+const _ROTATE_RETRY_INTERVAL = time.Minute
+
+// A file handler which supports rotation by time interval or schedule.
+// Rotation times are aligned to the calendar in 'location', e.g. daily
+// rotation happens at the local midnight.
 type TimeRotateFileHandler struct {
 	FileHandler
 	interval    RotateInterval
-	lastLogTime int64
 	backupCount uint16
+	schedule    RotateSchedule
+	location    *time.Location
+	nextRotate  time.Time
+	// Modification time of the existing file when the handler is created,
+	// which is used to compute the first rotation time. So a boundary
+	// passed while the process was down rotates the file at once.
+	fileTime time.Time
+	// If timer is enabled, the file is rotated on schedule even if no log
+	// is written. As the timer runs in another goroutine, 'mu' protects
+	// the rotation.
+	mu           sync.Mutex
+	timerEnabled bool
+	timer        *time.Timer
+	closed       bool
+	// Reports the failures of the rotation by the timer, which is set by
+	// the loop of the handler.
+	errReporter func(msg string, err error)
 }
 
 func NewTimeRotateFileHandler(
@@ -246,85 +943,210 @@ func NewTimeRotateFileHandler(
 	interval RotateInterval,
 	backupCount uint16) (*TimeRotateFileHandler, error) {
 
+	if interval == 0 {
+		return nil, goutils.NewErr("interval of rotation must be positive")
+	}
+
 	fileHandler, err := NewFileHandler(fileName)
 	if err != nil {
 		return nil, goutils.WrapErrorf(err, "failed to new file handler, file: %s", fileName)
 	}
 
+	if err := fileHandler.SetBackupPattern(DEFAULT_TIME_BACKUP_PATTERN); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to set backup pattern")
+	}
+
+	fileTime, err := fileHandler.modTime()
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to get modification time")
+	}
+
 	rotateHandler := &TimeRotateFileHandler{
 		FileHandler: *fileHandler,
 		interval:    interval,
-		backupCount: backupCount}
+		backupCount: backupCount,
+		schedule:    NewIntervalSchedule(interval),
+		location:    time.Local,
+		fileTime:    fileTime}
+	rotateHandler.resetSchedule()
 
 	return rotateHandler, nil
 }
 
+// SetLocation sets the timezone which rotation times are aligned to, and
+// backup names are formatted in.
+func (handler *TimeRotateFileHandler) SetLocation(loc *time.Location) {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	handler.location = loc
+	handler.resetSchedule()
+}
+
+// SetSchedule replaces the rotation by interval with 'schedule', e.g. a
+// schedule created by NewCronSchedule.
+func (handler *TimeRotateFileHandler) SetSchedule(schedule RotateSchedule) {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	handler.schedule = schedule
+	handler.resetSchedule()
+}
+
+// EnableTimer makes the file be rotated on schedule by a timer, even if no
+// log is written.
+func (handler *TimeRotateFileHandler) EnableTimer() {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	handler.timerEnabled = true
+	handler.resetTimer()
+}
+
+// RotateOnStart archives the existing file if it isn't empty, which is
+// called once when the handler is created.
+func (handler *TimeRotateFileHandler) RotateOnStart() error {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	now := time.Now().In(handler.location)
+	rotated, err := handler.rotateIfNotEmpty(now, handler.backupCount, ROTATE_ON_START)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to rotate on start")
+	}
+
+	if rotated {
+		handler.fileTime = time.Time{}
+		handler.resetSchedule()
+	}
+
+	return nil
+}
+
 func (handler *TimeRotateFileHandler) Handle(msg *_Msg) error {
-	// Rotate file
-	if handler.shouldRotate() {
-		if err := handler.doRotate(); err != nil {
-			return goutils.WrapErrorf(err, "failed to rotate")
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	// Rotate file. If it fails, the log is still written to the current
+	// file, and the error is returned after the log is written. An error of
+	// writing takes precedence, and the rotation is retried later anyway.
+	var rotateErr error
+	if now := time.Now(); handler.shouldRotate(now) {
+		if err := handler.rotate(handler.doRotate); err != nil {
+			rotateErr = goutils.WrapErrorf(err, "failed to rotate")
+			handler.retryRotateLater(now)
 		}
 	}
 
-	return handler.FileHandler.Handle(msg)
+	if err := handler.FileHandler.Handle(msg); err != nil || rotateErr == nil {
+		return err
+	}
+
+	return &_WrittenError{err: rotateErr, complete: true}
 }
 
-func (handler *TimeRotateFileHandler) shouldRotate() bool {
-	now := time.Now().Unix()
-	if handler.lastLogTime == 0 {
-		handler.lastLogTime = now
+// Close stops the timer and closes the file.
+func (handler *TimeRotateFileHandler) Close() error {
+	handler.mu.Lock()
+	handler.closed = true
+	if handler.timer != nil {
+		handler.timer.Stop()
+	}
+	handler.mu.Unlock()
+
+	return handler.FileHandler.Close()
+}
+
+// shouldRotate must be called with 'mu' held.
+func (handler *TimeRotateFileHandler) shouldRotate(now time.Time) bool {
+	now = now.In(handler.location)
+	if handler.nextRotate.IsZero() || now.Before(handler.nextRotate) {
 		return false
 	}
 
-	last := handler.lastLogTime / int64(handler.interval)
-	cur := now / int64(handler.interval)
+	handler.nextRotate = handler.schedule.Next(now)
+	handler.fileTime = time.Time{}
+	handler.resetTimer()
 
-	handler.lastLogTime = now
+	return true
+}
 
-	return cur > last
+// resetSchedule computes the next rotation time from the modification time
+// of the existing file, or from now if the file has been rotated.
+func (handler *TimeRotateFileHandler) resetSchedule() {
+	from := time.Now()
+	if !handler.fileTime.IsZero() && handler.fileTime.Before(from) {
+		from = handler.fileTime
+	}
+
+	handler.nextRotate = handler.schedule.Next(from.In(handler.location))
+	handler.resetTimer()
 }
 
-func (handler *TimeRotateFileHandler) doRotate() error {
-	err := handler.file.Close()
-	if err != nil {
-		return goutils.WrapErrorf(err, "failed to close")
+func (handler *TimeRotateFileHandler) resetTimer() {
+	if !handler.timerEnabled || handler.closed || handler.nextRotate.IsZero() {
+		return
 	}
 
-	// Make sure there are 'backupCount' logs at most
-	files, err := filepath.Glob(handler.fileName + "_*")
-	if err != nil {
-		return goutils.WrapErrorf(err, "failed to glob, file: %s", handler.fileName)
+	d := time.Until(handler.nextRotate)
+	if handler.timer == nil {
+		handler.timer = time.AfterFunc(d, handler.onTimer)
+	} else {
+		handler.timer.Reset(d)
+	}
+}
+
+func (handler *TimeRotateFileHandler) onTimer() {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	if handler.closed {
+		return
 	}
 
-	if len(files) >= int(handler.backupCount) {
-		rmCount := len(files) - int(handler.backupCount)
-		// NOTICE: code below depends on the files order of filepath.Glob
-		for i := 0; i < rmCount; i++ {
-			if err := os.Remove(files[i]); err != nil {
-				return goutils.WrapErrorf(err, "failed to remove file, file: %s", files[i])
+	if now := time.Now(); handler.shouldRotate(now) {
+		if err := handler.rotate(handler.doRotate); err != nil {
+			handler.retryRotateLater(now)
+			if handler.errReporter != nil {
+				handler.errReporter("failed to rotate on timer", err)
+			} else {
+				reportErr("failed to rotate on timer", err)
 			}
 		}
+	} else {
+		// Timer may fire a little earlier
+		handler.resetTimer()
 	}
+}
 
-	// Time suffix
-	suffix := time.Now().Format("200601021504")
-	err = os.Rename(handler.fileName, handler.fileName+"_"+suffix)
-	file, err := os.OpenFile(handler.fileName, _OPEN_FILE_FLAG, _OPEN_FILE_MODE)
-	if err != nil {
-		return goutils.WrapErrorf(err, "failed to open file, file: %s", handler.fileName)
+// retryRotateLater retries the failed rotation after _ROTATE_RETRY_INTERVAL
+// if it's before the next rotation time, which must be called with 'mu'
+// held.
+func (handler *TimeRotateFileHandler) retryRotateLater(now time.Time) {
+	if retry := now.Add(_ROTATE_RETRY_INTERVAL); retry.Before(handler.nextRotate) {
+		handler.nextRotate = retry
+		handler.resetTimer()
 	}
-	handler.file = file
-	handler.SetOutput(file)
+}
 
-	return nil
+func (handler *TimeRotateFileHandler) setErrorReporter(report func(msg string, err error)) {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	handler.errReporter = report
+}
+
+func (handler *TimeRotateFileHandler) doRotate() error {
+	_, err := handler.rotateFile(time.Now().In(handler.location), handler.backupCount, ROTATE_BY_TIME)
+	return err
 }
 
 func (handler *TimeRotateFileHandler) String() string {
 	var b bytes.Buffer
 
-	fmt.Fprintf(&b, "TimeRotateFileHandler{FileHandler: %s, interval: %d, backupCount: %d}",
-		handler.FileHandler.String(), handler.interval, handler.backupCount)
+	fmt.Fprintf(&b, "TimeRotateFileHandler{FileHandler: %s, schedule: %s, location: %s, "+
+		"timer: %t, backupCount: %d}", handler.FileHandler.String(), handler.schedule.String(),
+		handler.location.String(), handler.timerEnabled, handler.backupCount)
 
 	return string(b.Bytes())
 }
@@ -342,7 +1164,8 @@ type SizeRotateFileHandler struct {
 	maxBytes    int64
 	curBytes    int64
 	backupCount uint16
-	suffix      int32
+	// The failed rotation isn't retried before it.
+	rotateRetry time.Time
 }
 
 func NewSizeRotateFileHandler(
@@ -360,112 +1183,96 @@ func NewSizeRotateFileHandler(
 		return nil, goutils.WrapErrorf(err, "failed to seek, file: %s", fileName)
 	}
 
-	// Fetch max log suffix
-	suffix, err := findMaxSuffix(fileName)
-	if err != nil {
-		return nil, goutils.WrapErrorf(err, "failed to find max suffix, file: %s", fileName)
+	if err := fileHandler.SetBackupPattern(DEFAULT_SIZE_BACKUP_PATTERN); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to set backup pattern")
 	}
 
 	rotateHandler := &SizeRotateFileHandler{
 		FileHandler: *fileHandler,
 		maxBytes:    maxBytes,
 		curBytes:    curBytes,
-		backupCount: backupCount,
-		suffix:      suffix}
+		backupCount: backupCount}
 
 	return rotateHandler, nil
 }
 
-func (handler *SizeRotateFileHandler) Handle(msg *_Msg) error {
-	if msg.level < handler.level {
-		return nil
-	}
-
-	if handler.formatter == nil {
-		handler.formatter, _ = NewFormatter(defautlFormatStr)
-	}
-
-	logMsg := handler.formatter.Format(msg)
-	handler.curBytes += int64(len(logMsg))
-	// Need to rotate
-	if handler.curBytes > handler.maxBytes {
-		if err := handler.doRotate(); err != nil {
-			return goutils.WrapErrorf(err, "failed to rotate")
-		}
-
-		handler.curBytes = int64(len(logMsg))
-	}
-
-	wlen, err := handler.output.Write(logMsg)
-	if err != nil || wlen != len(logMsg) {
-		return errors.New("failed to Write logMsg")
+// RotateOnStart archives the existing file if it isn't empty, which is
+// called once when the handler is created.
+func (handler *SizeRotateFileHandler) RotateOnStart() error {
+	rotated, err := handler.rotateIfNotEmpty(time.Now(), handler.backupCount, ROTATE_ON_START)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to rotate on start")
 	}
 
-	if handler.isSyncWrite {
-		if err := handler.file.Sync(); err != nil {
-			return goutils.WrapErrorf(err, "failed to sync file")
+	if rotated {
+		size, err := handler.fileSize()
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to get file size")
 		}
+		handler.curBytes = size
 	}
 
 	return nil
 }
 
-func (handler *SizeRotateFileHandler) doRotate() error {
-	if err := handler.file.Close(); err != nil {
-		return goutils.WrapErrorf(err, "failed to close file")
-	}
-
-	maxSuffix, err := findMaxSuffix(handler.fileName)
-	if err != nil {
-		return goutils.WrapErrorf(err, "failed to find max suffix, file: %s", handler.fileName)
+func (handler *SizeRotateFileHandler) Handle(msg *_Msg) error {
+	if msg.level < handler.level {
+		return nil
 	}
 
-	nameBuf := bytes.Buffer{}
-	if maxSuffix >= int32(handler.backupCount) {
-		// Make sure there are 'backupCount' logs at most
-		sfn, dfn := bytes.Buffer{}, bytes.Buffer{}
-		for i := 1; i < int(maxSuffix); i++ {
-			sfn.Reset()
-			dfn.Reset()
-			fmt.Fprintf(&sfn, "%s_%04d", handler.fileName, i+1)
-			fmt.Fprintf(&dfn, "%s_%04d", handler.fileName, i)
+	buf := getBuffer()
+	defer putBuffer(buf)
 
-			dfnStr, sfnStr := string(dfn.Bytes()), string(sfn.Bytes())
-			if _, err := os.Stat(sfnStr); os.IsNotExist(err) {
-				continue
-			}
-
-			if _, err := os.Stat(dfnStr); err == nil {
-				if err := os.Remove(dfnStr); err != nil {
-					return goutils.WrapErrorf(err, "failed to remove file, file: %s", dfnStr)
-				}
-			}
-
-			if err := os.Rename(sfnStr, dfnStr); err != nil {
-				return goutils.WrapErrorf(err, "failed to rename file, src: %s, dist: %s",
-					sfnStr, dfnStr)
+	*buf = handler.appendFormat(*buf, msg)
+	logMsg := *buf
+	if handler.multiProcess {
+		// Other processes write the same file, so the size is fetched
+		// from the file.
+		size, err := handler.fileSize()
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to get file size")
+		}
+		handler.curBytes = size
+	}
+	handler.curBytes += int64(len(logMsg))
+	// Need to rotate. If it fails, the log is still written to the current
+	// file, and the error is returned after the log is written. The rotation
+	// is retried after _ROTATE_RETRY_INTERVAL.
+	var rotateErr error
+	if now := time.Now(); handler.curBytes > handler.maxBytes && !now.Before(handler.rotateRetry) {
+		if err := handler.rotate(handler.doRotate); err != nil {
+			rotateErr = goutils.WrapErrorf(err, "failed to rotate")
+			handler.rotateRetry = now.Add(_ROTATE_RETRY_INTERVAL)
+		} else {
+			size, err := handler.fileSize()
+			if err != nil {
+				return goutils.WrapErrorf(err, "failed to get file size")
 			}
+			handler.curBytes = size + int64(len(logMsg))
 		}
-
-		fmt.Fprintf(&nameBuf, "%s_%04d", handler.fileName, maxSuffix)
-	} else {
-		fmt.Fprintf(&nameBuf, "%s_%04d", handler.fileName, handler.suffix+1)
-		handler.suffix++
 	}
 
-	if err := os.Rename(handler.fileName, string(nameBuf.Bytes())); err != nil {
-		return goutils.WrapErrorf(err, "failed rename file")
+	wlen, err := handler.output.Write(logMsg)
+	handler.counters.countWrite(msg.level, wlen, err)
+	if err != nil && wlen > 0 {
+		return &_WrittenError{err: goutils.WrapErrorf(err, "failed to write, written: %d", wlen)}
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "failed to write")
+	}
+	if wlen != len(logMsg) {
+		return &_WrittenError{err: errors.New("failed to Write logMsg")}
 	}
 
-	file, err := os.OpenFile(handler.fileName, _OPEN_FILE_FLAG, _OPEN_FILE_MODE)
-	if err != nil {
-		return goutils.WrapErrorf(err, "failed to open file, file: %s", handler.fileName)
+	if err := handler.syncAfterWrite(msg); err != nil || rotateErr == nil {
+		return err
 	}
 
-	handler.file = file
-	handler.SetOutput(file)
+	return &_WrittenError{err: rotateErr, complete: true}
+}
 
-	return nil
+func (handler *SizeRotateFileHandler) doRotate() error {
+	_, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE)
+	return err
 }
 
 func (handler *SizeRotateFileHandler) String() string {
@@ -477,32 +1284,6 @@ func (handler *SizeRotateFileHandler) String() string {
 	return string(b.Bytes())
 }
 
-func findMaxSuffix(fileName string) (int32, error) {
-	var maxSuffix int32 = 0
-	files, err := filepath.Glob(fileName + "_*")
-	if err != nil {
-		return maxSuffix, goutils.WrapErrorf(err, "failed to glob, file: %s", fileName)
-	}
-
-	for _, f := range files {
-		sepIdx := strings.LastIndex(f, _SUFFIX_SEP)
-		if sepIdx == -1 {
-			continue
-		}
-
-		idx, err := strconv.Atoi(f[sepIdx+1:])
-		if err != nil {
-			return maxSuffix, goutils.WrapErrorf(err, "failed to str to int, str: %s", f[sepIdx+1:])
-		}
-
-		if maxSuffix < int32(idx) {
-			maxSuffix = int32(idx)
-		}
-	}
-
-	return maxSuffix, nil
-}
-
 func defaultConsoleHandler() Handler {
 	handler := NewStreamHandle(os.Stdout)
 	handler.SetSyncMode(true)
@@ -510,21 +1291,3 @@ func defaultConsoleHandler() Handler {
 	handler.SetFormatter(formater)
 	return handler
 }
-
-func stdErrLog(msg string, err error) {
-	buff := bytes.Buffer{}
-	ts := time.Now().Format("2006-01-02 15:04:05")
-	fmt.Fprintf(&buff, "%s %s err: %s\n", ts, msg, err.Error())
-	os.Stderr.WriteString(string(buff.Bytes()))
-}
-
-func init() {
-	innerFuncNames = make(map[string]int)
-	loggerPrefix := "gologging.(*Logger)."
-	gologgingPrefix := "gologging."
-
-	for _, lv := range []string{"Debug", "Info", "Error", "Exception", "Fatal"} {
-		innerFuncNames[loggerPrefix+lv] = 1
-		innerFuncNames[gologgingPrefix+lv] = 1
-	}
-}
@@ -0,0 +1,106 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 18:52:14
+ */
+
+package gologging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Handlers writing the same file stand for processes, as flock locks are
+// held by open files.
+func TestMultiProcess(t *testing.T) {
+	if !_FLOCK_SUPPORTED {
+		t.Skip("flock isn't supported")
+	}
+	const writers, logs = 4, 500
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	handlers := make([]*SizeRotateFileHandler, writers)
+	for i := range handlers {
+		handler, err := NewSizeRotateFileHandler(path, 4*KB, 1000)
+		if err != nil {
+			t.Fatalf("failed to create handler, err: %v", err)
+		}
+		if err := handler.EnableMultiProcess(); err != nil {
+			t.Fatalf("failed to enable multi-process mode, err: %v", err)
+		}
+		formatter, _ := NewFormatter("${message}")
+		handler.SetFormatter(formatter)
+		if i%2 == 0 {
+			// The buffer is flushed before a log which doesn't fit, so that
+			// lines aren't split by the writes of others.
+			handler.EnableBuffer(BufferConfig{Size: 100, FlushInterval: time.Millisecond})
+		}
+		handlers[i] = handler
+	}
+
+	var wg sync.WaitGroup
+	for i, handler := range handlers {
+		wg.Add(1)
+		go func(i int, handler *SizeRotateFileHandler) {
+			defer wg.Done()
+			for n := 0; n < logs; n++ {
+				msg := fmt.Sprintf("writer-%d log-%04d %s", i, n, strings.Repeat("x", 20+n%30))
+				if err := handler.Handle(&_Msg{level: INFO, message: []byte(msg)}); err != nil {
+					t.Errorf("failed to handle, err: %v", err)
+					return
+				}
+			}
+		}(i, handler)
+	}
+	wg.Wait()
+	for _, handler := range handlers {
+		if err := handler.Close(); err != nil {
+			t.Fatalf("failed to close handler, err: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(path + "*")
+	seen := make(map[string]bool)
+	rotations := 0
+	linePattern := regexp.MustCompile(`^writer-\d log-\d{4} x+$`)
+	for _, file := range files {
+		if strings.HasSuffix(file, _LOCK_SUFFIX) {
+			continue
+		}
+		if file != path {
+			rotations++
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read file, err: %v", err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if !linePattern.MatchString(line) {
+				t.Fatalf("line is interleaved in %s: %q", file, line)
+			}
+			key := line[:len("writer-0 log-0000")]
+			if seen[key] {
+				t.Fatalf("line is duplicated: %q", line)
+			}
+			seen[key] = true
+		}
+	}
+
+	if len(seen) != writers*logs {
+		t.Fatalf("lines are lost, expected: %d, written: %d", writers*logs, len(seen))
+	}
+	if rotations < 2 {
+		t.Fatalf("file isn't rotated concurrently, backups: %d", rotations)
+	}
+}
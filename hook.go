@@ -0,0 +1,278 @@
+/**
+ * Hooks called after a log file is rotated.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 15:58:44
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RotateReason string
+
+const (
+	ROTATE_BY_TIME RotateReason = "time"
+	ROTATE_BY_SIZE RotateReason = "size"
+	// The existing file is archived when the handler is created.
+	ROTATE_ON_START RotateReason = "start"
+
+	_CHECKSUM_SUFFIX = ".sha256"
+	_HOOK_QUEUE_SIZE = 16
+)
+
+// RotateEvent describes a rotation of a log file.
+type RotateEvent struct {
+	// Path of the log file.
+	Path string
+	// Path of the backup which the log file is renamed to.
+	BackupPath string
+	Reason     RotateReason
+	// Size of the backup.
+	Bytes int64
+	Time  time.Time
+}
+
+func (event RotateEvent) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "RotateEvent{path: %s, backup: %s, reason: %s, bytes: %d}",
+		event.Path, event.BackupPath, event.Reason, event.Bytes)
+
+	return string(b.Bytes())
+}
+
+// RotateHook is called after the log file is rotated. Hooks are called in
+// a separate goroutine, so that they don't block logging. Events are dropped
+// if the hooks fall behind by 16 rotations.
+type RotateHook func(event RotateEvent)
+
+var (
+	rotateHooks   = make(map[string]RotateHook)
+	rotateHooksMu sync.Mutex
+)
+
+// RegisterRotateHook registers a hook by name, which can be referred by the
+// 'post-rotate' option in config files.
+func RegisterRotateHook(name string, hook RotateHook) error {
+	rotateHooksMu.Lock()
+	defer rotateHooksMu.Unlock()
+
+	if _, ok := rotateHooks[name]; ok {
+		return goutils.NewErr("rotate hook named '%s' already exists", name)
+	}
+	rotateHooks[name] = hook
+
+	return nil
+}
+
+func getRotateHook(name string) (RotateHook, error) {
+	rotateHooksMu.Lock()
+	defer rotateHooksMu.Unlock()
+
+	hook, ok := rotateHooks[name]
+	if !ok {
+		return nil, goutils.NewErr("no rotate hook named '%s'", name)
+	}
+
+	return hook, nil
+}
+
+// rotateHookRunner calls the hooks of a handler in a goroutine, so that
+// events are handled in the order of rotations without blocking logging.
+type rotateHookRunner struct {
+	mu    sync.Mutex
+	hooks []RotateHook
+	q     chan RotateEvent
+	done  chan byte
+}
+
+func newRotateHookRunner() *rotateHookRunner {
+	runner := &rotateHookRunner{
+		q:    make(chan RotateEvent, _HOOK_QUEUE_SIZE),
+		done: make(chan byte),
+	}
+	go runner.run()
+
+	return runner
+}
+
+func (runner *rotateHookRunner) Add(hook RotateHook) {
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+
+	runner.hooks = append(runner.hooks, hook)
+}
+
+// Fire queues the event for the hooks. If the hooks are so slow that
+// _HOOK_QUEUE_SIZE events are pending, the event is dropped and reported,
+// rather than blocking the rotation and so logging.
+func (runner *rotateHookRunner) Fire(event RotateEvent) {
+	select {
+	case runner.q <- event:
+	default:
+		reportErr("failed to fire rotate hooks",
+			goutils.NewErr("hook queue is full, event is dropped: %s", event.String()))
+	}
+}
+
+// Close waits for the pending events to be handled.
+func (runner *rotateHookRunner) Close() {
+	close(runner.q)
+	<-runner.done
+}
+
+func (runner *rotateHookRunner) run() {
+	defer close(runner.done)
+
+	for event := range runner.q {
+		runner.mu.Lock()
+		hooks := runner.hooks
+		runner.mu.Unlock()
+
+		for _, hook := range hooks {
+			callHook(hook, event)
+		}
+	}
+}
+
+// callHook calls the hook, and reports the panic of the hook, so that it
+// doesn't crash the process or stop the following hooks.
+func callHook(hook RotateHook, event RotateEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportErr("rotate hook panicked", goutils.NewErr("panic: %v, event: %s", r, event.String()))
+		}
+	}()
+
+	hook(event)
+}
+
+// NewChecksumHook returns a hook which writes the SHA-256 checksum of the
+// backup to '${backup}.sha256', in the format of sha256sum. The checksum is
+// renamed and removed with the backup by the rotation.
+func NewChecksumHook() RotateHook {
+	return func(event RotateEvent) {
+		sum, err := fileChecksum(event.BackupPath)
+		if err != nil {
+			reportErr("failed to checksum backup", err)
+			return
+		}
+
+		if err := writeChecksum(event.BackupPath, sum); err != nil {
+			reportErr("failed to write checksum", err)
+		}
+	}
+}
+
+func writeChecksum(backupPath, sum string) error {
+	line := sum + "  " + filepath.Base(backupPath) + "\n"
+	checksumPath := backupPath + _CHECKSUM_SUFFIX
+	if err := os.WriteFile(checksumPath, []byte(line), _OPEN_FILE_MODE); err != nil {
+		return goutils.WrapErrorf(err, "failed to write file, file: %s", checksumPath)
+	}
+
+	return nil
+}
+
+// renameChecksum moves the checksum of the backup renamed from 'src' to
+// 'dest', and updates the name of the backup in it.
+func renameChecksum(src, dest string) error {
+	srcPath := src + _CHECKSUM_SUFFIX
+	data, err := os.ReadFile(srcPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "failed to read file, file: %s", srcPath)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return goutils.NewErr("invalid checksum file: %s", srcPath)
+	}
+	if err := writeChecksum(dest, fields[0]); err != nil {
+		return err
+	}
+
+	return removeChecksum(src)
+}
+
+// removeChecksum removes the checksum of the backup, if it exists.
+func removeChecksum(backupPath string) error {
+	checksumPath := backupPath + _CHECKSUM_SUFFIX
+	if err := os.Remove(checksumPath); err != nil && !os.IsNotExist(err) {
+		return goutils.WrapErrorf(err, "failed to remove file, file: %s", checksumPath)
+	}
+
+	return nil
+}
+
+// NewCopyHook returns a hook which copies the backup to 'dir', e.g. a
+// directory watched by an uploader to object storage. The copy is written to
+// a temporary file and renamed, so that the watcher never sees a partial file.
+func NewCopyHook(dir string) RotateHook {
+	return func(event RotateEvent) {
+		dest := filepath.Join(dir, filepath.Base(event.BackupPath))
+		if err := copyFile(event.BackupPath, dest); err != nil {
+			reportErr("failed to copy backup", err)
+		}
+	}
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", goutils.WrapErrorf(err, "failed to open file, file: %s", path)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", goutils.WrapErrorf(err, "failed to read file, file: %s", path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open file, file: %s", src)
+	}
+	defer in.Close()
+
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, _OPEN_FILE_MODE)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to create file, file: %s", tmp)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return goutils.WrapErrorf(err, "failed to copy file, src: %s, dist: %s", src, tmp)
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return goutils.WrapErrorf(err, "failed to close file, file: %s", tmp)
+	}
+
+	if err := os.Rename(tmp, dest); err != nil {
+		return goutils.WrapErrorf(err, "failed to rename file, src: %s, dist: %s", tmp, dest)
+	}
+
+	return nil
+}
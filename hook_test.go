@@ -0,0 +1,119 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 19:10:27
+ */
+
+package gologging
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateHookQueueFull(t *testing.T) {
+	events := collectErrors(t)
+
+	started, release := make(chan byte), make(chan byte)
+	var fired []string
+	runner := newRotateHookRunner()
+	runner.Add(func(event RotateEvent) {
+		if len(fired) == 0 {
+			close(started)
+			<-release
+		}
+		fired = append(fired, event.BackupPath)
+	})
+
+	runner.Fire(RotateEvent{BackupPath: "backup-0"})
+	<-started
+
+	// Fire never blocks the rotation, events exceeding the queue are dropped
+	done := make(chan byte)
+	go func() {
+		for i := 1; i <= _HOOK_QUEUE_SIZE+3; i++ {
+			runner.Fire(RotateEvent{BackupPath: fmt.Sprintf("backup-%d", i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Fire is blocked by a slow hook")
+	}
+
+	close(release)
+	runner.Close()
+
+	if len(fired) != _HOOK_QUEUE_SIZE+1 || fired[_HOOK_QUEUE_SIZE] != fmt.Sprintf("backup-%d", _HOOK_QUEUE_SIZE) {
+		t.Fatalf("unexpected fired events: %v", fired)
+	}
+	if len(*events) != 3 || (*events)[0].Msg != "failed to fire rotate hooks" {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+}
+
+// A panicking hook is reported, and the following hooks are still called.
+func TestRotateHookPanic(t *testing.T) {
+	events := collectErrors(t)
+
+	var fired []string
+	runner := newRotateHookRunner()
+	runner.Add(func(event RotateEvent) {
+		panic("hook bug")
+	})
+	runner.Add(func(event RotateEvent) {
+		fired = append(fired, event.BackupPath)
+	})
+	runner.Fire(RotateEvent{BackupPath: "backup-0"})
+	runner.Fire(RotateEvent{BackupPath: "backup-1"})
+	runner.Close()
+
+	if len(fired) != 2 {
+		t.Fatalf("unexpected fired events: %v", fired)
+	}
+	if len(*events) != 2 || (*events)[0].Msg != "rotate hook panicked" ||
+		!strings.Contains((*events)[0].Err.Error(), "hook bug") {
+		t.Fatalf("unexpected error events: %v", *events)
+	}
+}
+
+// Checksums are renamed and removed with the backups.
+func TestChecksumHookPrune(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewSizeRotateFileHandler(filepath.Join(dir, "app.log"), MB, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	fired := make(chan string, 1)
+	handler.OnRotate(NewChecksumHook())
+	handler.OnRotate(func(event RotateEvent) {
+		fired <- event.BackupPath
+	})
+
+	for i := 0; i < 4; i++ {
+		handler.Handle(newTestMsg())
+		if _, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE); err != nil {
+			t.Fatalf("failed to rotate, err: %s", err.Error())
+		}
+		<-fired
+	}
+
+	checksums, _ := filepath.Glob(filepath.Join(dir, "*"+_CHECKSUM_SUFFIX))
+	if len(checksums) != 2 {
+		t.Fatalf("unexpected checksums: %v", checksums)
+	}
+	for i, checksum := range checksums {
+		backup := filepath.Join(dir, fmt.Sprintf("app.log_%04d", i+1))
+		sum, _ := fileChecksum(backup)
+		if content := readFile(t, checksum); checksum != backup+_CHECKSUM_SUFFIX ||
+			content != sum+"  "+filepath.Base(backup)+"\n" {
+			t.Errorf("unexpected checksum: %s, content: %q", checksum, content)
+		}
+	}
+}
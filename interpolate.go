@@ -0,0 +1,182 @@
+/**
+ * Interpolation of placeholders in config values.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 22:31:07
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"github.com/chosen0ne/goutils"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Prefix of the env vars overriding the level of a logger, e.g.
+	// GOLOGGING_LEVEL_ACCESS=DEBUG for the logger named 'access'.
+	ENV_LEVEL_PREFIX = "GOLOGGING_LEVEL_"
+
+	_PLACEHOLDER_START = "${"
+	_PLACEHOLDER_END   = '}'
+	_ENV_PLACEHOLDER   = "env:"
+	_ENV_DEFAULT_SEP   = ":-"
+	_LOGGER_NAME_VAR   = "logger-name"
+	_HOSTNAME_VAR      = "hostname"
+	_PID_VAR           = "pid"
+)
+
+// interpConf interpolates the placeholders in the values of a config:
+//
+//	${env:VAR}: value of the env var, which must be set
+//	${env:VAR:-default}: value of the env var, or 'default' if it's unset or empty
+//	${logger-name}: name of the logger being loaded
+//	${hostname}: host name of the machine
+//	${pid}: pid of the process
+//
+// Other placeholders, such as the attributes of formatters, are kept.
+type interpConf struct {
+	confSource
+	loggerName string
+}
+
+func (conf *interpConf) GetString(name string) (string, error) {
+	val, err := conf.confSource.GetString(name)
+	if err != nil {
+		return "", err
+	}
+
+	return conf.interpolate(name, val)
+}
+
+func (conf *interpConf) GetStringArray(name string) ([]string, error) {
+	vals, err := conf.confSource.GetStringArray(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, val := range vals {
+		if vals[i], err = conf.interpolate(name, val); err != nil {
+			return nil, err
+		}
+	}
+
+	return vals, nil
+}
+
+func (conf *interpConf) GetInt(name string) (int, error) {
+	str, err := conf.GetString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "item isn't an integer, name: %s, value: %s", name, str)
+	}
+
+	return val, nil
+}
+
+func (conf *interpConf) interpolate(name, val string) (string, error) {
+	if !strings.Contains(val, _PLACEHOLDER_START) {
+		return val, nil
+	}
+
+	var b bytes.Buffer
+	for {
+		start := strings.Index(val, _PLACEHOLDER_START)
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(val[start:], _PLACEHOLDER_END)
+		if end == -1 {
+			break
+		}
+		end += start
+
+		b.WriteString(val[:start])
+		if resolved, ok, err := conf.resolve(val[start+len(_PLACEHOLDER_START) : end]); err != nil {
+			return "", goutils.WrapErrorf(err, "failed to interpolate item, name: %s", name)
+		} else if ok {
+			b.WriteString(resolved)
+		} else {
+			b.WriteString(val[start : end+1])
+		}
+		val = val[end+1:]
+	}
+	b.WriteString(val)
+
+	return b.String(), nil
+}
+
+// resolve returns the value of a placeholder, false if it isn't a
+// placeholder of the config.
+func (conf *interpConf) resolve(placeholder string) (string, bool, error) {
+	switch placeholder {
+	case _LOGGER_NAME_VAR:
+		return conf.loggerName, true, nil
+	case _HOSTNAME_VAR:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", false, goutils.WrapErrorf(err, "failed to get hostname")
+		}
+		return hostname, true, nil
+	case _PID_VAR:
+		return strconv.Itoa(os.Getpid()), true, nil
+	}
+
+	if !strings.HasPrefix(placeholder, _ENV_PLACEHOLDER) {
+		return "", false, nil
+	}
+
+	envName := placeholder[len(_ENV_PLACEHOLDER):]
+	if idx := strings.Index(envName, _ENV_DEFAULT_SEP); idx != -1 {
+		if val := os.Getenv(envName[:idx]); val != "" {
+			return val, true, nil
+		}
+		return envName[idx+len(_ENV_DEFAULT_SEP):], true, nil
+	}
+
+	val, ok := os.LookupEnv(envName)
+	if !ok {
+		return "", false, goutils.NewErr("environment variable isn't set, name: %s", envName)
+	}
+
+	return val, true, nil
+}
+
+// envLevel returns the level of the logger overridden by the env var, false
+// if it's not set. The env var is ENV_LEVEL_PREFIX followed by the logger
+// name in upper case, with characters other than letters and digits
+// replaced by '_', e.g. GOLOGGING_LEVEL_HTTP_ACCESS for 'http-access'.
+func envLevel(loggerName string) (Level, bool, error) {
+	envName := EnvLevelName(loggerName)
+	lvStr := os.Getenv(envName)
+	if lvStr == "" {
+		return INFO, false, nil
+	}
+
+	level := NewLevelString(lvStr)
+	if !level.IsValid() {
+		return INFO, false, goutils.NewErr("Unkown logger level, env: %s, level: %s", envName, lvStr)
+	}
+
+	return level, true, nil
+}
+
+// EnvLevelName returns the name of the env var overriding the level of the
+// logger.
+func EnvLevelName(loggerName string) string {
+	name := []byte(strings.ToUpper(loggerName))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+
+	return ENV_LEVEL_PREFIX + string(name)
+}
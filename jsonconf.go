@@ -0,0 +1,195 @@
+/**
+ * Parsing of JSON configs by encoding/json, which keeps the order of the
+ * loggers and the items as the YAML configs.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 21:03:26
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"strconv"
+)
+
+// _JSONDecoder reads the tokens of a JSON config, and tells the lines of
+// them.
+type _JSONDecoder struct {
+	dec  *json.Decoder
+	data []byte
+}
+
+// parseJSONConf converts the JSON config to sections, as a YAML config of
+// the same structure.
+func parseJSONConf(data []byte) (*sectionConf, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	d := &_JSONDecoder{dec: dec, data: data}
+
+	conf := newSectionConf()
+	if err := d.expectDelim('{'); err != nil {
+		return nil, err
+	}
+
+	var loggerNames []string
+	var loggersLine int
+	for dec.More() {
+		key, line, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		if key == _YAML_LOGGERS {
+			loggersLine = line
+		}
+		if key != _YAML_LOGGERS && key != _YAML_HANDLERS && key != _YAML_FORMATTERS {
+			return nil, goutils.NewErr("unknown config '%s', line: %d", key, line)
+		}
+		if err := d.expectDelim('{'); err != nil {
+			return nil, goutils.WrapErrorf(err, "'%s' isn't an object", key)
+		}
+
+		for dec.More() {
+			// Loggers are in the sections named 'logger-${name}' as goconf
+			name, line, err := d.key()
+			if err != nil {
+				return nil, err
+			}
+			sectionName := name
+			if key == _YAML_LOGGERS {
+				sectionName = _LOGGER_PREFIX + name
+				loggerNames = append(loggerNames, sectionName)
+			}
+
+			if err := d.addSection(conf, sectionName, line); err != nil {
+				return nil, goutils.WrapErrorf(err, "failed to convert '%s' of '%s'", name, key)
+			}
+		}
+		if err := d.expectDelim('}'); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.expectDelim('}'); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, goutils.NewErr("invalid JSON, data after the config, line: %d", d.line())
+	}
+
+	if len(loggerNames) == 0 {
+		return nil, goutils.NewErr("no logger is configured")
+	}
+	conf.setItem("", _LOGGERS_LABEL, loggerNames, loggersLine)
+
+	return conf, nil
+}
+
+func (d *_JSONDecoder) addSection(conf *sectionConf, name string, line int) error {
+	if err := d.expectDelim('{'); err != nil {
+		return goutils.WrapErrorf(err, "section isn't an object")
+	}
+
+	if err := conf.addSection(name, line); err != nil {
+		return err
+	}
+
+	for d.dec.More() {
+		key, line, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		token, err := d.token()
+		if err != nil {
+			return err
+		}
+		if token == json.Delim('[') {
+			var items []string
+			for d.dec.More() {
+				token, err := d.token()
+				if err != nil {
+					return err
+				}
+				item, ok := jsonScalar(token)
+				if !ok {
+					return goutils.NewErr("item of '%s' isn't a scalar, line: %d", key, d.line())
+				}
+				items = append(items, item)
+			}
+			if err := d.expectDelim(']'); err != nil {
+				return err
+			}
+			conf.setItem(name, key, items, line)
+			continue
+		}
+
+		if token == nil {
+			continue
+		}
+		val, ok := jsonScalar(token)
+		if !ok {
+			return goutils.NewErr("'%s' isn't a scalar or a list, line: %d", key, line)
+		}
+		conf.setItem(name, key, val, line)
+	}
+
+	return d.expectDelim('}')
+}
+
+// jsonScalar returns the text of a string, number or boolean.
+func jsonScalar(token json.Token) (string, bool) {
+	switch val := token.(type) {
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case bool:
+		return strconv.FormatBool(val), true
+	}
+
+	return "", false
+}
+
+func (d *_JSONDecoder) token() (json.Token, error) {
+	token, err := d.dec.Token()
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "invalid JSON, line: %d", d.line())
+	}
+
+	return token, nil
+}
+
+// key returns the key of an object and its line.
+func (d *_JSONDecoder) key() (string, int, error) {
+	token, err := d.token()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return token.(string), d.line(), nil
+}
+
+func (d *_JSONDecoder) expectDelim(delim json.Delim) error {
+	token, err := d.token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return goutils.NewErr("invalid JSON, expected: '%s', line: %d", delim, d.line())
+	}
+
+	return nil
+}
+
+// line returns the line of the last token.
+func (d *_JSONDecoder) line() int {
+	offset := d.dec.InputOffset()
+	if offset > int64(len(d.data)) {
+		offset = int64(len(d.data))
+	}
+
+	return bytes.Count(d.data[:offset], []byte{_NEWLINE}) + 1
+}
@@ -0,0 +1,230 @@
+/**
+ * JSON formats, whose format string is a JSON object, e.g.
+ *	{"time": "${datetime}", "level": "${levelname}", "line": ${lineno}, "msg": "${message}"}
+ * Attributes in JSON strings are escaped, so each log is a valid JSON object
+ * in one line, and it's parsed by the fields of the format.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 13:05:27
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Placeholders of the attributes to check whether a format is JSON,
+	// U+E000 is a private use character.
+	_JSON_TOKEN_MARK = '\ue000'
+	_JSON_STR_TOKEN  = "\ue000attr-%d\ue000"
+	_JSON_NUM_TOKEN  = "-4242000%d"
+	_HEX_DIGITS      = "0123456789abcdef"
+)
+
+// A field of a JSON format, whose value is formatted from attributes.
+type _JSONField struct {
+	path []string
+	// Matcher of a string value, e.g. '${date} ${time}'
+	segments []fmtSegment
+	// Attribute of a value out of strings, e.g. ${lineno}
+	attr string
+}
+
+// compileJSON makes the formatter a JSON formatter if the format string is
+// a JSON object, the attributes in strings are escaped.
+func (format *Formatter) compileJSON() {
+	if !strings.HasPrefix(strings.TrimSpace(format.formatStr), "{") {
+		return
+	}
+
+	// Replace the attributes by tokens, and find the fields of the tokens
+	var probe bytes.Buffer
+	inString, escaped := false, false
+	tokens := make(map[string]int)
+	for i := range format.segments {
+		seg := &format.segments[i]
+		if seg.appendVal == nil {
+			for _, c := range seg.literal {
+				switch {
+				case escaped:
+					escaped = false
+				case c == '\\' && inString:
+					escaped = true
+				case c == '"':
+					inString = !inString
+				}
+			}
+			probe.Write(seg.literal)
+			continue
+		}
+
+		if inString {
+			fmt.Fprintf(&probe, _JSON_STR_TOKEN, i)
+		} else {
+			token := fmt.Sprintf(_JSON_NUM_TOKEN, i)
+			tokens[token] = i
+			probe.WriteString(token)
+		}
+	}
+
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(probe.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil || dec.More() {
+		return
+	}
+
+	var fields []_JSONField
+	format.collectJSONFields(obj, nil, tokens, &fields)
+	if len(fields) == 0 {
+		return
+	}
+
+	for i := range format.segments {
+		if seg := &format.segments[i]; seg.appendVal != nil {
+			seg.appendVal = escapeJSON(seg.appendVal)
+		}
+	}
+	format.jsonFields = fields
+}
+
+func (format *Formatter) collectJSONFields(obj map[string]interface{}, path []string, tokens map[string]int,
+	fields *[]_JSONField) {
+	for key, val := range obj {
+		fieldPath := append(append([]string{}, path...), key)
+		switch v := val.(type) {
+		case map[string]interface{}:
+			format.collectJSONFields(v, fieldPath, tokens, fields)
+		case json.Number:
+			if idx, ok := tokens[string(v)]; ok {
+				*fields = append(*fields, _JSONField{path: fieldPath, attr: format.segments[idx].attr})
+			}
+		case string:
+			if segments := format.tokenSegments(v); segments != nil {
+				*fields = append(*fields, _JSONField{path: fieldPath, segments: segments})
+			}
+		}
+	}
+}
+
+// tokenSegments returns the segments of a string with the tokens of
+// attributes, nil if there is no token.
+func (format *Formatter) tokenSegments(s string) []fmtSegment {
+	var segments []fmtSegment
+	size := utf8.RuneLen(_JSON_TOKEN_MARK)
+	for {
+		start := strings.IndexRune(s, _JSON_TOKEN_MARK)
+		if start == -1 {
+			break
+		}
+		end := strings.IndexRune(s[start+size:], _JSON_TOKEN_MARK)
+		if end == -1 {
+			break
+		}
+		end += start + 2*size
+
+		var idx int
+		if _, err := fmt.Sscanf(s[start:end], _JSON_STR_TOKEN, &idx); err != nil {
+			break
+		}
+		if start > 0 {
+			segments = append(segments, fmtSegment{literal: []byte(s[:start])})
+		}
+		segments = append(segments, format.segments[idx])
+		s = s[end:]
+	}
+
+	if segments != nil && s != "" {
+		segments = append(segments, fmtSegment{literal: []byte(s)})
+	}
+
+	return segments
+}
+
+// parseJSON parses a line of the JSON format.
+func (format *Formatter) parseJSON(line string) (map[string]string, error) {
+	var obj map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, goutils.WrapErrorf(err, "invalid JSON")
+	}
+
+	attrs := make(map[string]string)
+	for _, field := range format.jsonFields {
+		var val interface{} = obj
+		for _, key := range field.path {
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return nil, goutils.NewErr("no field: %s", strings.Join(field.path, "."))
+			}
+			if val, ok = m[key]; !ok {
+				return nil, goutils.NewErr("no field: %s", strings.Join(field.path, "."))
+			}
+		}
+
+		if field.segments == nil {
+			if !setAttr(attrs, field.attr, fmt.Sprint(val)) {
+				return nil, goutils.NewErr("invalid value of field: %s", strings.Join(field.path, "."))
+			}
+			continue
+		}
+
+		str, ok := val.(string)
+		if !ok || !matchSegments(field.segments, str, attrs) {
+			return nil, goutils.NewErr("field doesn't match the format: %s", strings.Join(field.path, "."))
+		}
+	}
+
+	return attrs, nil
+}
+
+// escapeJSON escapes the value of an attribute as the content of a JSON
+// string.
+func escapeJSON(appendVal appendFunc) appendFunc {
+	return func(dst []byte, msg *_Msg) []byte {
+		start := len(dst)
+		dst = appendVal(dst, msg)
+
+		clean := true
+		for _, c := range dst[start:] {
+			if c < 0x20 || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+				clean = false
+				break
+			}
+		}
+		if clean {
+			return dst
+		}
+
+		val := string(dst[start:])
+		dst = dst[:start]
+		for _, r := range val {
+			switch {
+			case r == '"' || r == '\\':
+				dst = append(dst, '\\', byte(r))
+			case r == '\n':
+				dst = append(dst, '\\', 'n')
+			case r == '\r':
+				dst = append(dst, '\\', 'r')
+			case r == '\t':
+				dst = append(dst, '\\', 't')
+			case r < 0x20:
+				dst = append(dst, '\\', 'u', '0', '0', _HEX_DIGITS[r>>4], _HEX_DIGITS[r&0xf])
+			case r == utf8.RuneError:
+				dst = append(dst, `�`...)
+			default:
+				dst = utf8.AppendRune(dst, r)
+			}
+		}
+
+		return dst
+	}
+}
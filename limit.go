@@ -0,0 +1,146 @@
+/**
+ * Limits of the size of logs, so that an accidental huge message doesn't
+ * flood the log file or trigger rotation early.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 15:02:48
+ */
+
+package gologging
+
+import (
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	// Marker appended to a truncated message, e.g. '...[truncated 1024 bytes]'.
+	_TRUNCATED_PREFIX = "...[truncated "
+	_TRUNCATED_SUFFIX = " bytes]"
+	// Times to shrink the message when the log still exceeds the limit of
+	// the record, as the message may be expanded by the formatter, e.g.
+	// escaped.
+	_MAX_SHRINK_TIMES = 3
+)
+
+// Handlers which limit the size of logs.
+type messageLimiter interface {
+	SetMessageLimit(maxMessageBytes, maxRecordBytes int)
+}
+
+// Limits of the size of logs written by a handler, 0 means unlimited.
+type messageLimit struct {
+	maxMessageBytes int
+	maxRecordBytes  int
+}
+
+// SetMessageLimit limits the size of the logs written by the handler. A
+// message longer than 'maxMessageBytes' is truncated at the boundary of
+// UTF-8 characters, and followed by '...[truncated N bytes]', where N is
+// the number of bytes dropped. A log, including all the attributes, longer
+// than 'maxRecordBytes' has its message truncated to fit. If the log still
+// doesn't fit without the message, it's cut at the limit. As a cut JSON log
+// is invalid, a JSON log has the fields from the context and the caller
+// emptied instead, and it's kept whole even if it still exceeds the limit.
+// 0 means unlimited.
+func (handler *StreamHandler) SetMessageLimit(maxMessageBytes, maxRecordBytes int) {
+	handler.limit = messageLimit{maxMessageBytes, maxRecordBytes}
+}
+
+// appendFormat appends the formatted message to 'dst' within the limits.
+func (handler *StreamHandler) appendFormat(dst []byte, msg *_Msg) []byte {
+	formatter := handler.getFormatter()
+	limit := handler.limit
+	if limit.maxMessageBytes <= 0 && limit.maxRecordBytes <= 0 {
+		return formatter.AppendFormat(dst, msg)
+	}
+
+	start := len(dst)
+	keep := len(msg.message)
+	if limit.maxMessageBytes > 0 && keep > limit.maxMessageBytes {
+		keep = limit.maxMessageBytes
+	}
+	dst = appendTruncated(formatter, dst, msg, keep, false)
+
+	for i := 0; i < _MAX_SHRINK_TIMES && limit.maxRecordBytes > 0 && keep > 0; i++ {
+		excess := len(dst) - start - limit.maxRecordBytes
+		if excess <= 0 {
+			break
+		}
+
+		if keep == len(msg.message) {
+			// The marker is added
+			excess += truncatedMarkLen(len(msg.message))
+		}
+		if keep -= excess; keep < 0 {
+			keep = 0
+		}
+		dst = appendTruncated(formatter, dst[:start], msg, keep, false)
+	}
+
+	if limit.maxRecordBytes > 0 && len(dst)-start > limit.maxRecordBytes {
+		if formatter.jsonFields != nil {
+			dst = appendTruncated(formatter, dst[:start], msg, keep, true)
+		} else {
+			// The attributes exceed the limit, the newline is kept
+			end := start + limit.maxRecordBytes - 1
+			for end > start && !utf8.RuneStart(dst[end]) {
+				end--
+			}
+			dst = append(dst[:end], _NEWLINE)
+		}
+		keep = -1
+	}
+
+	if keep < len(msg.message) {
+		atomic.AddUint64(&handler.counters.truncated, 1)
+	}
+
+	return dst
+}
+
+// appendTruncated formats the message with the first 'keep' bytes of the
+// message, which is cut at the boundary of UTF-8 characters. The fields from
+// the context and the caller are emptied if 'noFields' is true.
+func appendTruncated(formatter *Formatter, dst []byte, msg *_Msg, keep int, noFields bool) []byte {
+	if keep >= len(msg.message) && !noFields {
+		return formatter.AppendFormat(dst, msg)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if keep >= len(msg.message) {
+		*buf = append(*buf, msg.message...)
+	} else {
+		for keep > 0 && !utf8.RuneStart(msg.message[keep]) {
+			keep--
+		}
+		*buf = append(*buf, msg.message[:keep]...)
+		*buf = append(*buf, _TRUNCATED_PREFIX...)
+		*buf = strconv.AppendInt(*buf, int64(len(msg.message)-keep), 10)
+		*buf = append(*buf, _TRUNCATED_SUFFIX...)
+	}
+
+	// The message is shared by handlers, so it's copied. 'refs' isn't copied,
+	// as it's changed by other handlers.
+	truncated := &_Msg{
+		loggerName: msg.loggerName,
+		level:      msg.level,
+		time:       msg.time,
+		message:    *buf,
+		funcName:   msg.funcName,
+		fileName:   msg.fileName,
+		lineNo:     msg.lineNo,
+		ctxFields:  msg.ctxFields,
+	}
+	if noFields {
+		truncated.funcName, truncated.fileName, truncated.ctxFields = "", "", ContextFields{}
+	}
+
+	return formatter.AppendFormat(dst, truncated)
+}
+
+func truncatedMarkLen(msgLen int) int {
+	return len(_TRUNCATED_PREFIX) + len(strconv.Itoa(msgLen)) + len(_TRUNCATED_SUFFIX)
+}
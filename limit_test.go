@@ -0,0 +1,82 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 15:41:07
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMessageLimit(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewStreamHandle(&out)
+	formatter, _ := NewFormatter("[${levelname}] ${message} (${lineno})")
+	handler.SetFormatter(formatter)
+	handler.SetMessageLimit(10, 0)
+
+	msg := &_Msg{level: INFO, lineNo: 7, message: []byte("short")}
+	handler.Handle(msg)
+	if out.String() != "[INFO] short (7)\n" || handler.Stats().Truncated != 0 {
+		t.Fatalf("unexpected log: %q, stats: %s", out.String(), handler.Stats())
+	}
+
+	// '世' is 3 bytes, which isn't split
+	out.Reset()
+	msg.message = []byte("abcdefgh世界")
+	handler.Handle(msg)
+	if out.String() != "[INFO] abcdefgh...[truncated 6 bytes] (7)\n" || handler.Stats().Truncated != 1 {
+		t.Fatalf("unexpected log: %q, stats: %s", out.String(), handler.Stats())
+	}
+	if string(msg.message) != "abcdefgh世界" {
+		t.Fatalf("message is modified: %q", msg.message)
+	}
+
+	// The whole log is limited, the attributes are kept
+	handler.SetMessageLimit(0, 40)
+	out.Reset()
+	msg.message = []byte(strings.Repeat("世", 100))
+	handler.Handle(msg)
+	line := out.String()
+	if len(line) > 40 || !utf8.ValidString(line) || !strings.HasPrefix(line, "[INFO] 世") ||
+		!strings.HasSuffix(line, " bytes] (7)\n") || handler.Stats().Truncated != 2 {
+		t.Fatalf("unexpected log: %q, stats: %s", line, handler.Stats())
+	}
+
+	// The log is cut if the attributes exceed the limit
+	handler.SetMessageLimit(0, 8)
+	out.Reset()
+	msg.message = []byte("x")
+	handler.Handle(msg)
+	if out.String() != "[INFO] \n" || handler.Stats().Truncated != 3 {
+		t.Fatalf("unexpected log: %q, stats: %s", out.String(), handler.Stats())
+	}
+}
+
+// A JSON log isn't cut, the fields exceeding the limit are emptied instead.
+func TestMessageLimitJSON(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewStreamHandle(&out)
+	formatter, _ := NewFormatter(`{"level": "${levelname}", "request_id": "${request_id}", "msg": "${message}"}`)
+	handler.SetFormatter(formatter)
+	handler.SetMessageLimit(0, 60)
+
+	msg := &_Msg{level: INFO, message: []byte("hello"),
+		ctxFields: ContextFields{RequestID: strings.Repeat("r", 100)}}
+	handler.Handle(msg)
+
+	var record map[string]string
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON log: %q, err: %v", out.String(), err)
+	}
+	if record["level"] != "INFO" || record["request_id"] != "-" ||
+		record["msg"] != "...[truncated 5 bytes]" || handler.Stats().Truncated != 1 {
+		t.Fatalf("unexpected log: %q, stats: %s", out.String(), handler.Stats())
+	}
+}
@@ -9,8 +9,12 @@ package gologging
 import (
 	"github.com/chosen0ne/goconf"
 	"github.com/chosen0ne/goutils"
+	"math"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -28,6 +32,38 @@ const (
 	_FILENAME_LABEL     = "file-name"
 	_FORMAT_LABEL       = "format"
 	_OVERWRITE_LABEL    = "overwrite"
+	_BUFFER_SIZE_LABEL  = "buffer-size"
+	_FLUSH_INTVAL_LABEL = "flush-interval"
+	_FSYNC_LABEL        = "fsync"
+	_FSYNC_INTVAL_LABEL = "fsync-interval"
+	_CALLER_SKIP_LABEL  = "caller-skip"
+	_MULTI_PROC_LABEL   = "multi-process"
+	_BACKUP_NAME_LABEL  = "backup-name"
+	_SYMLINK_LABEL      = "symlink"
+	_SCHEDULE_LABEL     = "schedule"
+	_TIMEZONE_LABEL     = "timezone"
+	_ROTATE_TIMER_LABEL = "rotate-timer"
+	_POST_ROTATE_LABEL  = "post-rotate"
+	_ROTATE_START_LABEL = "rotate-on-start"
+	_FILE_MODE_LABEL    = "file-mode"
+	_DIR_MODE_LABEL     = "dir-mode"
+	_OWNER_LABEL        = "owner"
+	_GROUP_LABEL        = "group"
+	_AUDIT_LABEL        = "audit"
+	_REDACT_KEYS_LABEL  = "redact-keys"
+	_REDACT_PAT_LABEL   = "redact-patterns"
+	_REDACT_DET_LABEL   = "redact-detectors"
+	_KEY_FILE_LABEL     = "encrypt-key-file"
+	_KEY_ENV_LABEL      = "encrypt-key-env"
+	_KEY_ID_LABEL       = "encrypt-key-id"
+	_MULTILINE_LABEL    = "multiline"
+	_MAX_MSG_LABEL      = "max-message-bytes"
+	_MAX_RECORD_LABEL   = "max-record-bytes"
+	_ON_FAILURE_LABEL   = "on-failure"
+	_RETRIES_LABEL      = "retries"
+	_RETRY_BACK_LABEL   = "retry-backoff"
+	_DISABLE_AFT_LABEL  = "disable-after"
+	_FALLBACK_LABEL     = "fallback"
 )
 
 var (
@@ -40,33 +76,97 @@ var (
 // Type mappings in the context are:
 //		handler   -> LoggerConfig
 //		extend    -> LoggerConfig
-//		formmater -> string
-type context map[string]interface{}
+//		formmater -> _FormatterConf
+type loadContext map[string]interface{}
 
-func newContext() context {
+// Config of a formatter section.
+type _FormatterConf struct {
+	format    string
+	multiline MultilinePolicy
+}
+
+func newLoadContext() loadContext {
 	return make(map[string]interface{})
 }
 
-// Configure loggers base on file.
+// Marks a handler which is being loaded in the context, so that a cycle of
+// extends is detected.
+type _Loading struct{}
+
+// The config read by the loader, which is a set of sections of items.
+// goconf.Conf implements it, and YAML and JSON configs are converted to a
+// sectionConf, so all the formats share the same options.
+type confSource interface {
+	Section(name string) error
+	HasSection(name string) bool
+	HasItem(name string) bool
+	GetString(name string) (string, error)
+	GetStringArray(name string) ([]string, error)
+	GetInt(name string) (int, error)
+}
+
+// Configure loggers base on file. The format is detected by the extension
+// of the file, '.yaml' and '.yml' for YAML, '.json' for JSON, and the format
+// of goconf for others, see 'logger-sample.conf'.
 func Load(configPath string) error {
+	conf, err := openConf(configPath)
+	if err != nil {
+		return err
+	}
+
+	return loadConf(conf)
+}
+
+// openConf parses the config file by the format detected as Load.
+func openConf(configPath string) (confSource, error) {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return readYAMLConf(configPath, parseYAMLConf)
+	case ".json":
+		return readYAMLConf(configPath, parseJSONConf)
+	}
+
+	return parseGoconfFile(configPath)
+}
+
+func parseGoconfFile(configPath string) (*goconf.Conf, error) {
 	conf := goconf.New(configPath)
 	if err := conf.Parse(); err != nil {
-		return goutils.WrapErrorf(err, "failed to parse conf, conf: %s", configPath)
+		return nil, goutils.WrapErrorf(err, "failed to parse conf, conf: %s", configPath)
 	}
 
+	return conf, nil
+}
+
+func loadConf(source confSource) error {
+	return eachLogger(source, func(loggerName string, conf confSource, ctx loadContext) error {
+		if err := loadLogger(loggerName, conf, ctx); err != nil {
+			return goutils.WrapErrorf(err, "failed to load logger, name: %s", loggerName)
+		}
+		return nil
+	})
+}
+
+// eachLogger calls 'fn' for each logger in the config by order, the config
+// passed to 'fn' is interpolated for the logger.
+func eachLogger(source confSource, fn func(loggerName string, conf confSource, ctx loadContext) error) error {
+	conf := &interpConf{confSource: source}
 	loggerNames, err := conf.GetStringArray(_LOGGERS_LABEL)
 	if err != nil {
 		return goutils.WrapErrorf(err, "failed to get loggers from config")
 	}
 
-	ctx := newContext()
 	for _, loggerName := range loggerNames {
 		if !isValidLogger(loggerName) {
 			return goutils.NewErr("invalid section name for logger, name: %s", loggerName)
 		}
 
-		if err := loadLogger(loggerName, conf, ctx); err != nil {
-			return goutils.WrapErrorf(err, "failed to load logger, name: %s", loggerName)
+		// Configs are interpolated by the logger name, so they aren't
+		// shared among loggers.
+		ctx := newLoadContext()
+		conf.loggerName = strings.SplitN(loggerName, "-", 2)[1]
+		if err := fn(loggerName, conf, ctx); err != nil {
+			return err
 		}
 	}
 
@@ -78,13 +178,63 @@ func isValidLogger(loggerName string) bool {
 	return strings.HasPrefix(loggerName, "logger-")
 }
 
-func loadLogger(loggerName string, conf *goconf.Conf, ctx context) error {
+func loadLogger(loggerName string, conf confSource, ctx loadContext) error {
+	spec, err := resolveLogger(loggerName, conf, ctx)
+	if err != nil {
+		return err
+	}
+
+	redactor, err := newConfigRedactor(spec.RedactKeys, spec.RedactPatterns, spec.RedactDetectors)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to load redaction config")
+	}
+
+	logger := newLogger(spec.Name, false)
+	logger.SetLevel(spec.Level)
+	logger.CallerSkip(spec.CallerSkip)
+	logger.SetRedactor(redactor)
+
+	for _, handlerSpec := range spec.Handlers {
+		handler, err := createHandler(handlerSpec.Config)
+		if err != nil {
+			logger.Close()
+			return goutils.WrapErrorf(err, "failed to create handler, name: %s", handlerSpec.Name)
+		}
+		logger.AddNamedHandler(handlerSpec.Name, handler)
+
+		if failure, err := createFailureConfig(handlerSpec.Config); err != nil {
+			logger.Close()
+			return goutils.WrapErrorf(err, "failed to create fallback handler, name: %s", handlerSpec.Name)
+		} else if failure != nil {
+			if err := logger.SetFailureConfig(handlerSpec.Name, *failure); err != nil {
+				logger.Close()
+				return goutils.WrapErrorf(err, "failed to set failure config, name: %s", handlerSpec.Name)
+			}
+		}
+	}
+
+	if spec.Overwrite {
+		loggerMgr.AddOrUpdateLogger(spec.Name, logger)
+	} else {
+		if err := loggerMgr.AddLogger(spec.Name, logger); err != nil {
+			logger.Close()
+			return goutils.WrapErrorf(err, "failed to add logger to LogMgr, name: %s", spec.Name)
+		}
+	}
+
+	return nil
+}
+
+// resolveLogger reads the config of a logger, with the configs of its
+// handlers merged with the configs they extend and the defaults set.
+// Nothing is created.
+func resolveLogger(loggerName string, conf confSource, ctx loadContext) (*EffectiveLogger, error) {
 	if err := conf.Section(loggerName); err != nil {
-		return goutils.WrapErrorf(err, "failed to go to section, section: %s", loggerName)
+		return nil, goutils.WrapErrorf(err, "failed to go to section, section: %s", loggerName)
 	}
 
 	if !conf.HasItem(_HANDLERS_LABEL) {
-		return goutils.NewErr("logger has no handlers, name: %s", loggerName)
+		return nil, goutils.NewErr("logger has no handlers, name: %s", loggerName)
 	}
 
 	// parse level
@@ -93,64 +243,104 @@ func loadLogger(loggerName string, conf *goconf.Conf, ctx context) error {
 		level = INFO
 	} else {
 		if lvStr, err := conf.GetString(_LEVEL_LABEL); err != nil {
-			return goutils.WrapErrorf(err, "failed to get level config")
+			return nil, goutils.WrapErrorf(err, "failed to get level config")
 		} else {
 			if level = NewLevelString(lvStr); !level.IsValid() {
-				return goutils.NewErr("Unkown logger level, level: %s", lvStr)
+				return nil, goutils.NewErr("Unkown logger level, level: %s", lvStr)
 			}
 		}
 	}
 
 	// config name for logger: logger-${LOGGER-NAME}
 	fields := strings.SplitN(loggerName, "-", 2)
-	logger := newLogger(fields[1], false)
+
+	// level can be overridden by env
+	if envLv, ok, err := envLevel(fields[1]); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to get level from env")
+	} else if ok {
+		level = envLv
+	}
+
+	spec := &EffectiveLogger{Name: fields[1], Level: level, Overwrite: true}
+	if conf.HasItem(_CALLER_SKIP_LABEL) {
+		var err error
+		if spec.CallerSkip, err = conf.GetInt(_CALLER_SKIP_LABEL); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to get caller skip config")
+		}
+	}
+
+	if err := loadRedactConfig(spec, conf); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to load redaction config")
+	}
+
+	// overwrite must be read before the handlers, which switch the section.
+	if conf.HasItem(_OVERWRITE_LABEL) {
+		if overStr, err := conf.GetString(_OVERWRITE_LABEL); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to get overwrite config")
+		} else if spec.Overwrite, err = parseBool(overStr); err != nil {
+			return nil, goutils.WrapErrorf(err, "invalid config value for overwrite")
+		}
+	}
 
 	// load handlers
 	handlerNames, err := conf.GetStringArray(_HANDLERS_LABEL)
 	if err != nil {
-		return goutils.WrapErrorf(err, "failed to get handlers config")
+		return nil, goutils.WrapErrorf(err, "failed to get handlers config")
 	}
 	for _, handlerName := range handlerNames {
 		if handlerConfig, err := loadHandler(handlerName, conf, ctx); err != nil {
-			return goutils.WrapErrorf(err, "failed to load handler, name: %s", handlerName)
+			return nil, goutils.WrapErrorf(err, "failed to load handler, name: %s", handlerName)
+		} else if handlerConfig.Handler == "" {
+			return nil, goutils.NewErr("no type of handler, name: %s", handlerName)
 		} else {
-			if handler, err := createHandler(handlerConfig); err != nil {
-				return goutils.WrapErrorf(err, "failed to create handler, name: %s", handlerName)
-			} else {
-				logger.AddHandler(handler)
+			// The config in the context is shared by the handlers extending
+			// it, so the defaults are set on a copy.
+			config := newLoggerConfig(handlerConfig)
+			setDefaultConfig(spec.Name, config)
+			if config.Fallback != nil {
+				config.Fallback = newLoggerConfig(config.Fallback)
+				setDefaultConfig(spec.Name, config.Fallback)
 			}
+			spec.Handlers = append(spec.Handlers, EffectiveHandler{handlerName, config})
 		}
 	}
 
-	var overwrite bool
-	if !conf.HasItem(_OVERWRITE_LABEL) {
-		overwrite = true
-	} else {
-		if overStr, err := conf.GetString(_OVERWRITE_LABEL); err != nil {
-			return goutils.WrapErrorf(err, "failed to get overwrite config")
-		} else {
-			if overStr != "true" || overStr != "TRUE" || overStr != "false" || overStr != "FALSE" {
-				return goutils.NewErr("invalid config value for overwrite, val: %s", overStr)
-			}
-			overwrite = "true" == overStr || "TRUE" == overStr
+	return spec, nil
+}
+
+// loadRedactConfig loads the redaction config in the section of a logger.
+// The redactor is checked as well.
+func loadRedactConfig(spec *EffectiveLogger, conf confSource) error {
+	var err error
+
+	if conf.HasItem(_REDACT_KEYS_LABEL) {
+		if spec.RedactKeys, err = conf.GetStringArray(_REDACT_KEYS_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get redact keys from config")
 		}
 	}
 
-	if overwrite {
-		loggerMgr.AddOrUpdateLogger(fields[1], logger)
-	} else {
-		if err := loggerMgr.AddLogger(fields[1], logger); err != nil {
-			return goutils.WrapErrorf(err, "failed to add logger to LogMgr, name: %s", fields[1])
+	if conf.HasItem(_REDACT_PAT_LABEL) {
+		if spec.RedactPatterns, err = conf.GetStringArray(_REDACT_PAT_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get redact patterns from config")
 		}
 	}
 
-	return nil
+	if conf.HasItem(_REDACT_DET_LABEL) {
+		if spec.RedactDetectors, err = conf.GetStringArray(_REDACT_DET_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get redact detectors from config")
+		}
+	}
+
+	_, err = newConfigRedactor(spec.RedactKeys, spec.RedactPatterns, spec.RedactDetectors)
+	return err
 }
 
-func loadHandler(handlerName string, conf *goconf.Conf, ctx context) (*LoggerConfig, error) {
+func loadHandler(handlerName string, conf confSource, ctx loadContext) (*LoggerConfig, error) {
 	// fetch object from context at frist
 	if handlerConfObj, ok := ctx[handlerName]; ok {
-		if handlerConf, assertOk := handlerConfObj.(*LoggerConfig); assertOk {
+		if _, loading := handlerConfObj.(_Loading); loading {
+			return nil, goutils.NewErr("cycle of extends, handler: %s", handlerName)
+		} else if handlerConf, assertOk := handlerConfObj.(*LoggerConfig); assertOk {
 			return handlerConf, nil
 		} else {
 			return nil, goutils.NewErr("config for handler named '%s' in context isn't a *LoggerConfig",
@@ -165,6 +355,9 @@ func loadHandler(handlerName string, conf *goconf.Conf, ctx context) (*LoggerCon
 	var handlerConf *LoggerConfig
 
 	if conf.HasItem(_HANDLER_EXTEND) {
+		// Mark the handler to detect cycles of extends
+		ctx[handlerName] = _Loading{}
+
 		if extName, err := conf.GetString(_HANDLER_EXTEND); err != nil {
 			return nil, goutils.WrapErrorf(err, "failed to get extend from config")
 		} else if loggerConfig, err := loadExtendConfig(extName, conf, ctx); err != nil {
@@ -189,40 +382,54 @@ func loadHandler(handlerName string, conf *goconf.Conf, ctx context) (*LoggerCon
 	// add to context
 	ctx[handlerName] = handlerConf
 
+	if err := loadFallback(handlerName, handlerConf, conf, ctx); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to load fallback handler, handler: %s", handlerName)
+	}
+
 	return handlerConf, nil
 }
 
-func loadExtendConfig(extName string, conf *goconf.Conf, ctx context) (*LoggerConfig, error) {
-	if configObj, ok := ctx[extName]; ok {
-		if config, assertOk := configObj.(*LoggerConfig); assertOk {
-			return config, nil
-		} else {
-			return nil, goutils.NewErr("config named '%s' in context isn't a *LoggerConfig", extName)
-		}
+// loadFallback loads the fallback handler, which is loaded after the handler
+// as it turns to the section of the fallback handler.
+func loadFallback(handlerName string, handlerConf *LoggerConfig, conf confSource, ctx loadContext) error {
+	// Back to section of handler config, which is checked by loadHandler.
+	conf.Section(handlerName)
+	if !conf.HasItem(_FALLBACK_LABEL) {
+		return nil
 	}
 
-	if err := conf.Section(extName); err != nil {
-		return nil, goutils.WrapErrorf(err, "failed to turn to section, section: %s", extName)
+	fallbackName, err := conf.GetString(_FALLBACK_LABEL)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to get fallback from config")
 	}
 
-	configObj := &LoggerConfig{}
-	if err := loadLoggerConfig(configObj, conf, ctx); err != nil {
-		return nil, goutils.WrapErrorf(err, "failed to load logger config, extend name: %s", extName)
+	fallback, err := loadHandler(fallbackName, conf, ctx)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to load handler, name: %s", fallbackName)
+	}
+	if fallback == handlerConf || fallback.Fallback != nil {
+		return goutils.NewErr("fallback handler can't have a fallback, name: %s", fallbackName)
 	}
+	if fallback.Handler == "" {
+		return goutils.NewErr("no type of handler, name: %s", fallbackName)
+	}
+	handlerConf.Fallback = fallback
 
-	// add to context
-	ctx[extName] = configObj
+	return nil
+}
 
-	return configObj, nil
+// A config to extend is loaded as a handler, so it can extend another one.
+func loadExtendConfig(extName string, conf confSource, ctx loadContext) (*LoggerConfig, error) {
+	return loadHandler(extName, conf, ctx)
 }
 
-func loadLoggerConfig(configObj *LoggerConfig, conf *goconf.Conf, ctx context) error {
+func loadLoggerConfig(configObj *LoggerConfig, conf confSource, ctx loadContext) error {
 	if configObj == nil {
 		return goutils.NewErr("invalid param, configObj is nil")
 	}
 
-	if configObj.Handler == "" {
-		// Type of handler has not been set
+	// Type of handler can be inherited from the config to extend
+	if conf.HasItem(_TYPE_LABEL) {
 		if htStr, err := conf.GetString(_TYPE_LABEL); err != nil {
 			return goutils.WrapErrorf(err, "failed to get config, name: %s", _TYPE_LABEL)
 		} else if _, ok := handlerTypes[htStr]; !ok {
@@ -239,12 +446,110 @@ func loadLoggerConfig(configObj *LoggerConfig, conf *goconf.Conf, ctx context) e
 		}
 	}
 
+	if conf.HasItem(_SCHEDULE_LABEL) {
+		if configObj.Schedule, err = conf.GetString(_SCHEDULE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get schedule from config")
+		}
+	}
+
+	if conf.HasItem(_TIMEZONE_LABEL) {
+		if configObj.TimeZone, err = conf.GetString(_TIMEZONE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get timezone from config")
+		}
+	}
+
+	if conf.HasItem(_ROTATE_TIMER_LABEL) {
+		if timerStr, err := conf.GetString(_ROTATE_TIMER_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get rotate timer from config")
+		} else if configObj.RotateTimer, err = parseBool(timerStr); err != nil {
+			return goutils.WrapErrorf(err, "invalid config value for rotate timer")
+		}
+	}
+
+	if conf.HasItem(_ROTATE_START_LABEL) {
+		if startStr, err := conf.GetString(_ROTATE_START_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get rotate on start from config")
+		} else if configObj.RotateOnStart, err = parseBool(startStr); err != nil {
+			return goutils.WrapErrorf(err, "invalid config value for rotate on start")
+		}
+	}
+
 	if conf.HasItem(_MAX_SIZE_LABEL) {
-		if configObj.MaxBytes, err = parseSize(conf); err != nil {
+		if configObj.MaxBytes, err = parseSize(conf, _MAX_SIZE_LABEL); err != nil {
 			return goutils.WrapErrorf(err, "failed to parse max size")
 		}
 	}
 
+	if conf.HasItem(_BUFFER_SIZE_LABEL) {
+		bufferSize, err := parseSize(conf, _BUFFER_SIZE_LABEL)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to parse buffer size")
+		}
+		configObj.BufferSize = int(bufferSize)
+	}
+
+	if conf.HasItem(_MAX_MSG_LABEL) {
+		maxBytes, err := parseSize(conf, _MAX_MSG_LABEL)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to parse max message bytes")
+		}
+		configObj.MaxMessageBytes = int(maxBytes)
+	}
+
+	if conf.HasItem(_MAX_RECORD_LABEL) {
+		maxBytes, err := parseSize(conf, _MAX_RECORD_LABEL)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to parse max record bytes")
+		}
+		configObj.MaxRecordBytes = int(maxBytes)
+	}
+
+	if conf.HasItem(_ON_FAILURE_LABEL) {
+		if policyStr, err := conf.GetString(_ON_FAILURE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get failure policy from config")
+		} else if configObj.OnFailure, err = NewFailurePolicyString(policyStr); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse failure policy")
+		}
+	}
+
+	if conf.HasItem(_RETRIES_LABEL) {
+		if configObj.Retries, err = conf.GetInt(_RETRIES_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse retries")
+		}
+	}
+
+	if conf.HasItem(_RETRY_BACK_LABEL) {
+		if configObj.RetryBackoff, err = parseDuration(conf, _RETRY_BACK_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse retry backoff")
+		}
+	}
+
+	if conf.HasItem(_DISABLE_AFT_LABEL) {
+		if configObj.DisableAfter, err = conf.GetInt(_DISABLE_AFT_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse disable after")
+		}
+	}
+
+	if conf.HasItem(_FLUSH_INTVAL_LABEL) {
+		if configObj.FlushInterval, err = parseDuration(conf, _FLUSH_INTVAL_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse flush interval")
+		}
+	}
+
+	if conf.HasItem(_FSYNC_LABEL) {
+		if policyStr, err := conf.GetString(_FSYNC_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get fsync policy from config")
+		} else if configObj.FsyncPolicy, err = NewFsyncPolicyString(policyStr); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse fsync policy")
+		}
+	}
+
+	if conf.HasItem(_FSYNC_INTVAL_LABEL) {
+		if configObj.FsyncInterval, err = parseDuration(conf, _FSYNC_INTVAL_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse fsync interval")
+		}
+	}
+
 	if conf.HasItem(_BACKUP_COUNT_LABEL) {
 		bakupCount, err := conf.GetInt(_BACKUP_COUNT_LABEL)
 		if err != nil {
@@ -262,6 +567,40 @@ func loadLoggerConfig(configObj *LoggerConfig, conf *goconf.Conf, ctx context) e
 		configObj.SyncWrite = configObj.SyncMode
 	}
 
+	if conf.HasItem(_MULTI_PROC_LABEL) {
+		if multiStr, err := conf.GetString(_MULTI_PROC_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get multi-process config")
+		} else if configObj.MultiProcess, err = parseBool(multiStr); err != nil {
+			return goutils.WrapErrorf(err, "invalid config value for multi-process")
+		}
+	}
+
+	if conf.HasItem(_AUDIT_LABEL) {
+		if auditStr, err := conf.GetString(_AUDIT_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get audit config")
+		} else if configObj.Audit, err = parseBool(auditStr); err != nil {
+			return goutils.WrapErrorf(err, "invalid config value for audit")
+		}
+	}
+
+	if conf.HasItem(_KEY_FILE_LABEL) {
+		if configObj.EncryptKeyFile, err = conf.GetString(_KEY_FILE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get key file from config")
+		}
+	}
+
+	if conf.HasItem(_KEY_ENV_LABEL) {
+		if configObj.EncryptKeyEnv, err = conf.GetString(_KEY_ENV_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get key env from config")
+		}
+	}
+
+	if conf.HasItem(_KEY_ID_LABEL) {
+		if configObj.EncryptKeyID, err = conf.GetString(_KEY_ID_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get key id from config")
+		}
+	}
+
 	if conf.HasItem(_LOG_PATH_LABEL) {
 		configObj.LogPath, err = conf.GetString(_LOG_PATH_LABEL)
 		if err != nil {
@@ -269,14 +608,45 @@ func loadLoggerConfig(configObj *LoggerConfig, conf *goconf.Conf, ctx context) e
 		}
 	}
 
-	// format
-	if conf.HasItem(_FORMMATER_LABEL) {
-		if fmtName, err := conf.GetString(_FORMMATER_LABEL); err != nil {
-			return goutils.WrapErrorf(err, "failed to get formatter from config")
-		} else if fmtStr, err := loadFormatter(fmtName, conf, ctx); err != nil {
-			return goutils.WrapErrorf(err, "failed to load formatter, name: %s", fmtName)
-		} else {
-			configObj.Format = fmtStr
+	if conf.HasItem(_FILE_MODE_LABEL) {
+		if configObj.FileMode, err = parseFileMode(conf, _FILE_MODE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse file mode")
+		}
+	}
+
+	if conf.HasItem(_DIR_MODE_LABEL) {
+		if configObj.DirMode, err = parseFileMode(conf, _DIR_MODE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to parse dir mode")
+		}
+	}
+
+	if conf.HasItem(_OWNER_LABEL) {
+		if configObj.Owner, err = conf.GetString(_OWNER_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get owner from config")
+		}
+	}
+
+	if conf.HasItem(_GROUP_LABEL) {
+		if configObj.Group, err = conf.GetString(_GROUP_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get group from config")
+		}
+	}
+
+	if conf.HasItem(_BACKUP_NAME_LABEL) {
+		if configObj.BackupPattern, err = conf.GetString(_BACKUP_NAME_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get backup name from config")
+		}
+	}
+
+	if conf.HasItem(_SYMLINK_LABEL) {
+		if configObj.Symlink, err = conf.GetString(_SYMLINK_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get symlink from config")
+		}
+	}
+
+	if conf.HasItem(_POST_ROTATE_LABEL) {
+		if configObj.PostRotate, err = conf.GetStringArray(_POST_ROTATE_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get post rotate hooks from config")
 		}
 	}
 
@@ -288,37 +658,59 @@ func loadLoggerConfig(configObj *LoggerConfig, conf *goconf.Conf, ctx context) e
 		}
 	}
 
+	// format, which must be the last as it turns to the section of the formatter
+	if conf.HasItem(_FORMMATER_LABEL) {
+		if fmtName, err := conf.GetString(_FORMMATER_LABEL); err != nil {
+			return goutils.WrapErrorf(err, "failed to get formatter from config")
+		} else if fmtConf, err := loadFormatter(fmtName, conf, ctx); err != nil {
+			return goutils.WrapErrorf(err, "failed to load formatter, name: %s", fmtName)
+		} else {
+			configObj.Format = fmtConf.format
+			configObj.Multiline = fmtConf.multiline
+		}
+	}
+
 	return nil
 }
 
-func loadFormatter(fmtName string, conf *goconf.Conf, ctx context) (string, error) {
+func loadFormatter(fmtName string, conf confSource, ctx loadContext) (*_FormatterConf, error) {
 	if fmtObj, ok := ctx[fmtName]; ok {
-		if fmtStr, assertOk := fmtObj.(string); assertOk {
-			return fmtStr, nil
+		if fmtConf, assertOk := fmtObj.(*_FormatterConf); assertOk {
+			return fmtConf, nil
 		} else {
-			return "", goutils.NewErr("object for formatter in context is't a string, formatter: %s",
+			return nil, goutils.NewErr("object for formatter in context is't a formatter config, formatter: %s",
 				fmtName)
 		}
 	}
 
 	if !conf.HasSection(fmtName) {
-		return "", goutils.NewErr("no formatter named '%s'", fmtName)
+		return nil, goutils.NewErr("no formatter named '%s'", fmtName)
 	}
 
 	if err := conf.Section(fmtName); err != nil {
-		return "", goutils.WrapErrorf(err, "failed to go to section, name: %s", fmtName)
+		return nil, goutils.WrapErrorf(err, "failed to go to section, name: %s", fmtName)
 	}
 
+	fmtConf := &_FormatterConf{}
 	if fmtStr, err := conf.GetString(_FORMAT_LABEL); err != nil {
-		return "", goutils.WrapErrorf(err, "failed to get format from config")
+		return nil, goutils.WrapErrorf(err, "failed to get format from config")
 	} else {
-		ctx[fmtName] = fmtStr
+		fmtConf.format = fmtStr
+	}
 
-		return fmtStr, nil
+	if conf.HasItem(_MULTILINE_LABEL) {
+		if policyStr, err := conf.GetString(_MULTILINE_LABEL); err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to get multiline policy from config")
+		} else if fmtConf.multiline, err = NewMultilinePolicyString(policyStr); err != nil {
+			return nil, err
+		}
 	}
+	ctx[fmtName] = fmtConf
+
+	return fmtConf, nil
 }
 
-func parseInterval(conf *goconf.Conf) (RotateInterval, error) {
+func parseInterval(conf confSource) (RotateInterval, error) {
 	intervalStr, err := conf.GetString(_INTERVAL_LABEL)
 	if err != nil {
 		return 0, goutils.WrapErrorf(err, "failed to get conifg, name: %s", _INTERVAL_LABEL)
@@ -331,18 +723,21 @@ func parseInterval(conf *goconf.Conf) (RotateInterval, error) {
 		if !ok {
 			return 0, goutils.NewErr("unknown interval type: %s", unitStr)
 		}
+		if num <= 0 || int64(num)*int64(unit) > math.MaxUint32 {
+			return 0, goutils.NewErr("interval is out of range, value: %s", intervalStr)
+		}
 		return RotateInterval(uint32(num) * uint32(unit)), nil
 	}
 }
 
-func parseSize(conf *goconf.Conf) (int64, error) {
-	sizeStr, err := conf.GetString(_MAX_SIZE_LABEL)
+func parseSize(conf confSource, label string) (int64, error) {
+	sizeStr, err := conf.GetString(label)
 	if err != nil {
-		return 0, goutils.WrapErrorf(err, "failed to get max size from config")
+		return 0, goutils.WrapErrorf(err, "failed to get config, name: %s", label)
 	}
 
 	if num, unitStr, err := splitNumAndStr(sizeStr); err != nil {
-		return 0, goutils.WrapErrorf(err, "failed to split max size")
+		return 0, goutils.WrapErrorf(err, "failed to split size, value: %s", sizeStr)
 	} else {
 		unit, ok := sizeTypes[strings.ToUpper(unitStr)]
 		if !ok {
@@ -352,13 +747,60 @@ func parseSize(conf *goconf.Conf) (int64, error) {
 	}
 }
 
-func parseSyncMode(conf *goconf.Conf) (bool, error) {
+// input: "200ms", "1s"
+func parseDuration(conf confSource, label string) (time.Duration, error) {
+	durationStr, err := conf.GetString(label)
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "failed to get config, name: %s", label)
+	}
+
+	d, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "invalid duration, value: %s", durationStr)
+	}
+
+	return d, nil
+}
+
+// input: "0640", octal as chmod
+func parseFileMode(conf confSource, label string) (os.FileMode, error) {
+	modeStr, err := conf.GetString(label)
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "failed to get config, name: %s", label)
+	}
+
+	mode, err := strconv.ParseUint(modeStr, 8, 32)
+	if err != nil || mode == 0 || mode > uint64(os.ModePerm) {
+		return 0, goutils.NewErr("invalid file mode, value: %s", modeStr)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// input: "true", "FALSE", case-insensitive
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	return false, goutils.NewErr("invalid boolean, value: %s", s)
+}
+
+func parseSyncMode(conf confSource) (bool, error) {
 	syncMode, err := conf.GetString(_SYNC_MODE_LABEL)
 	if err != nil {
 		return false, goutils.WrapErrorf(err, "failed to get sync mode from config")
 	}
 
-	return strings.ToLower(syncMode) == "true", nil
+	isSync, err := parseBool(syncMode)
+	if err != nil {
+		return false, goutils.WrapErrorf(err, "invalid config value for sync mode")
+	}
+
+	return isSync, nil
 }
 
 // input: "100day"
@@ -388,12 +830,15 @@ func init() {
 	}
 
 	intervalTypes = map[string]RotateInterval{
-		"day":  DAY,
-		"min":  MINUTE,
-		"hour": HOUR,
+		"day":   DAY,
+		"min":   MINUTE,
+		"hour":  HOUR,
+		"week":  WEEK,
+		"month": MONTH,
 	}
 
 	sizeTypes = map[string]int64{
+		"B":  1,
 		"KB": KB,
 		"MB": MB,
 		"GB": GB,
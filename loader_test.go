@@ -7,9 +7,19 @@
 package gologging
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 )
 
+// requireYAML skips the test if YAML configs aren't built in.
+func requireYAML(t *testing.T) {
+	if !_YAML_SUPPORTED {
+		t.Skip("YAML configs need the build tag 'yaml'")
+	}
+}
+
 func TestLoad(t *testing.T) {
 	if err := Load("logger-sample.conf"); err != nil {
 		t.Errorf("failed to Load 'logger-sample.conf', err: %s", err.Error())
@@ -29,3 +39,227 @@ func TestLoad(t *testing.T) {
 
 	dlog.Info("test")
 }
+
+func TestLoadYAML(t *testing.T) {
+	if !_YAML_SUPPORTED {
+		if err := Load("logger-sample.yaml"); err == nil {
+			t.Fatalf("YAML config is loaded without the build tag 'yaml'")
+		}
+		return
+	}
+
+	// Loggers of the same names may be loaded by TestLoad, and overwrite is false
+	loggerMgr.mu.Lock()
+	delete(loggerMgr.logCache, "error")
+	loggerMgr.mu.Unlock()
+
+	if err := Load("logger-sample.yaml"); err != nil {
+		t.Fatalf("failed to Load 'logger-sample.yaml', err: %s", err.Error())
+	}
+
+	GetLogger("error").Error("yaml error")
+	GetLogger("info").Info("yaml info password=secret")
+	GetLogger("dev").Info("yaml dev")
+}
+
+func TestLoadJSON(t *testing.T) {
+	dir := t.TempDir()
+	config := `{
+	"loggers": {
+		"json": {"level": "WARN", "handlers": ["handler-json"], "caller-skip": 0}
+	},
+	"handlers": {
+		"base": {"type": "size-rotate", "max-size": "1MB", "backup-count": 3, "formatter": "fmt-json"},
+		"handler-json": {"extends": "base", "log-path": "` + dir + `", "file-name": "json.log",
+			"sync": true, "file-mode": "0600"}
+	},
+	"formatters": {
+		"fmt-json": {"format": "${levelname} ${message}"}
+	}
+}`
+	configPath := filepath.Join(dir, "logger.json")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	if err := Load(configPath); err != nil {
+		t.Fatalf("failed to load JSON config, err: %v", err)
+	}
+
+	logger := GetLogger("json")
+	logger.Error("json error")
+	logger.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "json.log"))
+	if err != nil {
+		t.Fatalf("failed to read log, err: %v", err)
+	}
+	if string(data) != "ERROR json error\n" {
+		t.Fatalf("unexpected log: %q", data)
+	}
+
+	// Lines are kept for Validate
+	conf, err := parseJSONConf([]byte(config))
+	if err != nil {
+		t.Fatalf("failed to parse JSON, err: %v", err)
+	}
+	if conf.line("handler-json", "") != 7 || conf.line("handler-json", "sync") != 8 ||
+		conf.line("", _LOGGERS_LABEL) != 2 {
+		t.Fatalf("unexpected lines: %d, %d", conf.line("handler-json", ""), conf.line("handler-json", "sync"))
+	}
+
+	for _, invalid := range []string{`{"loggers": {"json": `, `{"loggers": {"a": {"handlers": [{}]}}}`,
+		`{"loggers": {"a": {}}, "others": {}}`, `{"handlers": {"h": {}}}`, `{"loggers": {"a": {}}} {}`} {
+		if _, err := parseJSONConf([]byte(invalid)); err == nil {
+			t.Fatalf("invalid JSON is parsed: %s", invalid)
+		}
+	}
+}
+
+func TestYAMLSectionConf(t *testing.T) {
+	requireYAML(t)
+
+	conf, err := parseYAMLConf([]byte(`
+loggers:
+  app: {handlers: "h1 h2"}
+handlers:
+  h1: {type: console, post-rotate: [a, b]}
+  h2: {type: console, backup-count: 10, file-mode: 0640}
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML, err: %v", err)
+	}
+
+	if loggers, _ := conf.GetStringArray(_LOGGERS_LABEL); len(loggers) != 1 || loggers[0] != "logger-app" {
+		t.Fatalf("unexpected loggers: %v", loggers)
+	}
+
+	conf.Section("logger-app")
+	if handlers, _ := conf.GetStringArray(_HANDLERS_LABEL); len(handlers) != 2 {
+		t.Fatalf("unexpected handlers: %v", handlers)
+	}
+
+	conf.Section("h1")
+	if hooks, _ := conf.GetStringArray(_POST_ROTATE_LABEL); len(hooks) != 2 || hooks[1] != "b" {
+		t.Fatalf("unexpected post rotate hooks: %v", hooks)
+	}
+
+	conf.Section("h2")
+	if count, err := conf.GetInt(_BACKUP_COUNT_LABEL); err != nil || count != 10 {
+		t.Fatalf("unexpected backup count: %d, err: %v", count, err)
+	}
+	// The text of the scalar is kept, so the mode is parsed as octal
+	if mode, err := parseFileMode(conf, _FILE_MODE_LABEL); err != nil || mode != 0640 {
+		t.Fatalf("unexpected file mode: %#o, err: %v", mode, err)
+	}
+
+	if _, err := parseYAMLConf([]byte("handlers:\n  h1: {type: console}\n")); err == nil {
+		t.Fatalf("config without loggers is parsed")
+	}
+	if _, err := parseYAMLConf([]byte("loggers:\n  a: {handlers: h}\nhandlers:\n  logger-a: {}\n")); err == nil {
+		t.Fatalf("duplicated section is parsed")
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	requireYAML(t)
+
+	t.Setenv("GOLOGGING_TEST_DIR", "/tmp/logs")
+	t.Setenv("GOLOGGING_TEST_EMPTY", "")
+
+	source, err := parseYAMLConf([]byte(`
+loggers:
+  app: {handlers: [h]}
+handlers:
+  h:
+    log-path: ${env:GOLOGGING_TEST_DIR}
+    file-name: ${logger-name}-${pid}.log
+    symlink: ${env:GOLOGGING_TEST_EMPTY:-current}.log
+    backup-name: ${name}-${hostname}
+    owner: ${env:GOLOGGING_TEST_UNSET}
+`))
+	if err != nil {
+		t.Fatalf("failed to parse YAML, err: %v", err)
+	}
+
+	hostname, _ := os.Hostname()
+	conf := &interpConf{confSource: source, loggerName: "app"}
+	conf.Section("h")
+	expected := map[string]string{
+		_LOG_PATH_LABEL:    "/tmp/logs",
+		_FILENAME_LABEL:    "app-" + strconv.Itoa(os.Getpid()) + ".log",
+		_SYMLINK_LABEL:     "current.log",
+		_BACKUP_NAME_LABEL: "${name}-" + hostname,
+	}
+	for label, val := range expected {
+		if s, err := conf.GetString(label); err != nil || s != val {
+			t.Fatalf("unexpected value, label: %s, expected: %s, got: %s, err: %v", label, val, s, err)
+		}
+	}
+
+	if _, err := conf.GetString(_OWNER_LABEL); err == nil {
+		t.Fatalf("unset env var is interpolated")
+	}
+}
+
+func TestEnvLevel(t *testing.T) {
+	requireYAML(t)
+
+	if name := EnvLevelName("http-access.v2"); name != "GOLOGGING_LEVEL_HTTP_ACCESS_V2" {
+		t.Fatalf("unexpected env name: %s", name)
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logger.yaml")
+	config := `
+loggers:
+  env-level: {level: INFO, handlers: [h]}
+handlers:
+  h: {type: size-rotate, max-size: 1MB, log-path: "` + dir + `", file-name: "${logger-name}.log", sync: true}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	t.Setenv("GOLOGGING_LEVEL_ENV_LEVEL", "debug")
+	if err := Load(configPath); err != nil {
+		t.Fatalf("failed to load config, err: %v", err)
+	}
+
+	logger := GetLogger("env-level")
+	logger.Debug("debug log")
+	logger.Close()
+
+	if data, err := os.ReadFile(filepath.Join(dir, "env-level.log")); err != nil || len(data) == 0 {
+		t.Fatalf("level isn't overridden by env, log: %q, err: %v", data, err)
+	}
+
+	t.Setenv("GOLOGGING_LEVEL_ENV_LEVEL", "verbose")
+	if err := Load(configPath); err == nil {
+		t.Fatalf("invalid level in env is loaded")
+	}
+}
+
+// Boolean items are case-insensitive, and invalid values are rejected.
+func TestLoadBool(t *testing.T) {
+	labels := []string{_SYNC_MODE_LABEL, _ROTATE_TIMER_LABEL, _ROTATE_START_LABEL, _MULTI_PROC_LABEL,
+		_AUDIT_LABEL}
+	for _, label := range labels {
+		for val, valid := range map[string]bool{"TRUE": true, "false": true, "yes": false, "1": false} {
+			conf, err := parseJSONConf([]byte(`{"loggers": {"app": {"handlers": ["h"]}},
+				"handlers": {"h": {"` + label + `": "` + val + `"}}}`))
+			if err != nil {
+				t.Fatalf("failed to parse JSON, err: %v", err)
+			}
+			conf.Section("h")
+
+			config := &LoggerConfig{}
+			err = loadLoggerConfig(config, conf, loadContext{})
+			if valid && err != nil {
+				t.Errorf("failed to load boolean, label: %s, value: %s, err: %v", label, val, err)
+			} else if !valid && err == nil {
+				t.Errorf("invalid boolean is loaded, label: %s, value: %s", label, val)
+			}
+		}
+	}
+}
@@ -8,12 +8,14 @@ package gologging
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/chosen0ne/goutils"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -74,6 +76,15 @@ type Logger struct {
 	//
 	// TODO: support sync mode to handle message
 	handlers []*handlerLoop
+	// Number of extra frames to skip when resolving the caller info,
+	// which is used by functions wrapping the Logger.
+	callerSkip int
+	// Whether the caller info is left out, as the caller tells nothing,
+	// e.g. logs emitted by middlewares.
+	noCaller bool
+	// Secrets in messages are masked by redactor before being emitted to
+	// handlers, nil if redaction isn't enabled.
+	redactor *Redactor
 }
 
 func newLogger(name string, enableConsoleLog bool) *Logger {
@@ -87,7 +98,9 @@ func newLogger(name string, enableConsoleLog bool) *Logger {
 	return logger
 }
 
-func (logger *Logger) log(level Level, fmtStr string, vals ...interface{}) {
+// log emits a log to all the handlers. 'ctx' is nil if the log isn't
+// emitted by the Ctx methods.
+func (logger *Logger) log(ctx context.Context, level Level, fmtStr string, vals ...interface{}) {
 	if !checkLevel(level) {
 		panic(errors.New("not support level"))
 	}
@@ -96,16 +109,43 @@ func (logger *Logger) log(level Level, fmtStr string, vals ...interface{}) {
 		return
 	}
 
-	// Fill message
-	msg := bytes.Buffer{}
-	fmt.Fprintf(&msg, fmtStr, vals...)
+	handlers := logger.handlers
+	if len(handlers) == 0 {
+		return
+	}
+
+	// Fill message, which is shared by all the handlers.
+	msg := getMsg()
+	msg.loggerName, msg.level, msg.time = logger.name, level, time.Now()
+	msg.message = fmt.Appendf(msg.message, fmtStr, vals...)
+	if logger.redactor != nil {
+		msg.message = logger.redactor.Redact(msg.message)
+	}
+	if ctx != nil {
+		extractContext(ctx, &msg.ctxFields)
+	}
+	if !logger.noCaller && logger.needsCaller(handlers) {
+		msg.fillCaller(logger.callerSkip)
+	}
+	msg.refs = int32(len(handlers))
 
 	// Emit the message to all the handlers
-	for _, handler := range logger.handlers {
-		handler.Emit(&_Msg{loggerName: logger.name, level: level, message: msg.Bytes()})
+	for _, handler := range handlers {
+		handler.Emit(msg)
 	}
 }
 
+// Caller info is resolved only when any handler needs it.
+func (logger *Logger) needsCaller(handlers []*handlerLoop) bool {
+	for _, handler := range handlers {
+		if handler.needsCaller() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (logger *Logger) debugInfo() string {
 	b := &bytes.Buffer{}
 
@@ -127,42 +167,139 @@ func (logger *Logger) SetLevel(level Level) {
 	logger.level = level
 }
 
+// CallerSkip sets the number of extra stack frames to skip when resolving
+// the caller info. When Logger is wrapped by a function, CallerSkip(1)
+// makes the caller of the wrapper be reported.
+func (logger *Logger) CallerSkip(n int) {
+	logger.callerSkip = n
+}
+
+// WithCallerSkip returns a copy of the logger which skips 'n' more stack
+// frames. The copy shares the handlers with the original logger, but the
+// level is copied.
+func (logger *Logger) WithCallerSkip(n int) *Logger {
+	l := *logger
+	l.callerSkip += n
+	return &l
+}
+
+// withoutCaller returns a copy of the logger which leaves out the caller
+// info.
+func (logger *Logger) withoutCaller() *Logger {
+	l := *logger
+	l.noCaller = true
+	return &l
+}
+
+// SetRedactor makes secrets in messages masked before they are formatted
+// by any handler. nil disables redaction.
+func (logger *Logger) SetRedactor(redactor *Redactor) {
+	logger.redactor = redactor
+}
+
+// Redactor returns the redactor of the logger, nil if redaction isn't
+// enabled.
+func (logger *Logger) Redactor() *Redactor {
+	return logger.redactor
+}
+
 func (logger *Logger) AddHandler(handler Handler) {
+	logger.AddNamedHandler(fmt.Sprintf("handler-%d", len(logger.handlers)), handler)
+}
+
+// AddNamedHandler adds the handler with the name reported by Stats.
+func (logger *Logger) AddNamedHandler(name string, handler Handler) {
 	loop := NewLoop(_DEFAULT_CHAN_SIZE, handler)
+	loop.name = name
+	loop.loggerName = logger.name
 	logger.handlers = append(logger.handlers, loop)
 	go loop.HandleLoop()
 }
 
 func (logger *Logger) Debug(fmt string, vals ...interface{}) {
-	logger.log(DEBUG, fmt, vals...)
+	logger.log(nil, DEBUG, fmt, vals...)
 }
 
 func (logger *Logger) Trace(fmt string, vals ...interface{}) {
-	logger.log(TRACE, fmt, vals...)
+	logger.log(nil, TRACE, fmt, vals...)
 }
 
 func (logger *Logger) Info(fmt string, vals ...interface{}) {
-	logger.log(INFO, fmt, vals...)
+	logger.log(nil, INFO, fmt, vals...)
 }
 
 func (logger *Logger) Warn(fmt string, vals ...interface{}) {
-	logger.log(WARN, fmt, vals...)
+	logger.log(nil, WARN, fmt, vals...)
 }
 
 func (logger *Logger) Error(fmt string, vals ...interface{}) {
-	logger.log(ERROR, fmt, vals...)
+	logger.log(nil, ERROR, fmt, vals...)
 }
 
 func (logger *Logger) Exception(err error, fmt string, vals ...interface{}) {
-	fmt = fmt + ", err: " + err.Error()
-	logger.log(ERROR, fmt, vals...)
+	logger.log(nil, ERROR, exceptionFmt(err, fmt), vals...)
 }
 
 func (logger *Logger) Fatal(fmt string, vals ...interface{}) {
-	logger.log(FATAL, fmt, vals...)
+	logger.log(nil, FATAL, fmt, vals...)
+	fatalExit()
+}
+
+// Methods with a context, the trace ID, span ID and request ID are extracted
+// from the context by the registered ContextExtractor.
+func (logger *Logger) DebugCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, DEBUG, fmt, vals...)
+}
+
+func (logger *Logger) TraceCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, TRACE, fmt, vals...)
+}
+
+func (logger *Logger) InfoCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, INFO, fmt, vals...)
+}
+
+func (logger *Logger) WarnCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, WARN, fmt, vals...)
+}
+
+func (logger *Logger) ErrorCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, ERROR, fmt, vals...)
+}
+
+func (logger *Logger) ExceptionCtx(ctx context.Context, err error, fmt string, vals ...interface{}) {
+	logger.log(ctx, ERROR, exceptionFmt(err, fmt), vals...)
+}
+
+func (logger *Logger) FatalCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	logger.log(ctx, FATAL, fmt, vals...)
+	fatalExit()
+}
+
+func exceptionFmt(err error, fmt string) string {
+	return fmt + ", err: " + err.Error()
+}
+
+func fatalExit() {
+	// Make sure buffered logs are written before exit.
+	Shutdown()
 	os.Exit(1)
 }
 
+// Close waits for all the emitted logs to be handled, and closes all the
+// handlers of the logger, which flushes the buffered logs.
+func (logger *Logger) Close() error {
+	var closeErr error
+	for _, handler := range logger.handlers {
+		if err := handler.Close(); err != nil {
+			reportErr("failed to close handler", err)
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
+
 func checkLevel(level Level) bool {
 	return level >= DEBUG && level < _MAX_LEVEL
 }
@@ -203,47 +340,111 @@ func (mgr *_LogMgr) AddLogger(name string, logger *Logger) error {
 	return nil
 }
 
+// AddOrUpdateLogger adds the logger, or replaces the logger with the same
+// name. The replaced logger is closed after the swap, which flushes its
+// buffered logs, and logs emitted to it afterwards are dropped.
 func (mgr *_LogMgr) AddOrUpdateLogger(name string, logger *Logger) {
 	mgr.mu.Lock()
-	defer mgr.mu.Unlock()
-
+	old, ok := mgr.logCache[name]
 	mgr.logCache[name] = logger
+	mgr.mu.Unlock()
+
+	if ok && old != logger {
+		if err := old.Close(); err != nil {
+			reportErr("failed to close replaced logger", err)
+		}
+	}
+}
+
+func (mgr *_LogMgr) CloseAll() {
+	mgr.mu.Lock()
+	loggers := make([]*Logger, 0, len(mgr.logCache)+1)
+	for _, logger := range mgr.logCache {
+		loggers = append(loggers, logger)
+	}
+	loggers = append(loggers, mgr.rootLogger)
+	mgr.mu.Unlock()
+
+	for _, logger := range loggers {
+		logger.Close()
+	}
 }
 
 // Methods for root logger, all the message emit by root logger
-// wil be output to stdout
+// wil be output to stdout.
+// They call Logger.log directly to keep the same stack depth as the
+// methods of Logger, so that the caller info is resolved correctly.
 func Debug(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Debug(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, DEBUG, fmt, vals...)
 }
 
 func Trace(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Trace(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, TRACE, fmt, vals...)
 }
 
 func Info(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Info(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, INFO, fmt, vals...)
 }
 
 func Warn(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Warn(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, WARN, fmt, vals...)
 }
 
 func Error(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Error(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, ERROR, fmt, vals...)
 }
 
 func Exception(err error, fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Exception(err, fmt, vals...)
+	loggerMgr.rootLogger.log(nil, ERROR, exceptionFmt(err, fmt), vals...)
 }
 
 func Fatal(fmt string, vals ...interface{}) {
-	loggerMgr.rootLogger.Fatal(fmt, vals...)
+	loggerMgr.rootLogger.log(nil, FATAL, fmt, vals...)
+	fatalExit()
+}
+
+// Functions with a context log by the logger carried by the context, see
+// FromContext.
+func DebugCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, DEBUG, fmt, vals...)
+}
+
+func TraceCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, TRACE, fmt, vals...)
+}
+
+func InfoCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, INFO, fmt, vals...)
+}
+
+func WarnCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, WARN, fmt, vals...)
+}
+
+func ErrorCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, ERROR, fmt, vals...)
+}
+
+func ExceptionCtx(ctx context.Context, err error, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, ERROR, exceptionFmt(err, fmt), vals...)
+}
+
+func FatalCtx(ctx context.Context, fmt string, vals ...interface{}) {
+	FromContext(ctx).log(ctx, FATAL, fmt, vals...)
+	fatalExit()
 }
 
 func GetLogger(name string) *Logger {
 	return loggerMgr.GetLogger(name)
 }
 
+// Shutdown closes all the loggers, which makes sure all the buffered logs
+// are written. It should be called before the process exits. Logs emitted
+// after Shutdown are dropped.
+func Shutdown() {
+	loggerMgr.CloseAll()
+}
+
 // ConfigSizeRotateLogger configure a size rotated logger
 func ConfigSizeRotateLogger(
 	name string,
@@ -0,0 +1,97 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 12:10:48
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// newBufferLogger returns a logger which writes to the returned buffer
+// synchronously.
+func newBufferLogger(format string) (*Logger, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	handler := NewStreamHandle(out)
+	handler.SetSyncMode(true)
+	formatter, _ := NewFormatter(format)
+	handler.SetFormatter(formatter)
+
+	logger := newLogger("test", false)
+	logger.AddHandler(handler)
+
+	return logger, out
+}
+
+func logWrapper(logger *Logger, msg string) {
+	logger.Info(msg)
+}
+
+func TestCallerInfo(t *testing.T) {
+	logger, out := newBufferLogger("${filename}:${funcname} ${message}")
+	defer logger.Close()
+
+	logger.Info("direct")
+	logWrapper(logger, "wrapped")
+	logWrapper(logger.WithCallerSkip(1), "skipped")
+
+	expected := "logger_test.go:gologging.TestCallerInfo direct\n" +
+		"logger_test.go:gologging.logWrapper wrapped\n" +
+		"logger_test.go:gologging.TestCallerInfo skipped\n"
+	if out.String() != expected {
+		t.Errorf("unexpected caller info, expected: %q, got: %q", expected, out.String())
+	}
+}
+
+func TestCallerNotNeeded(t *testing.T) {
+	logger, out := newBufferLogger("${levelname} ${message}")
+	defer logger.Close()
+
+	if logger.needsCaller(logger.handlers) {
+		t.Errorf("caller info isn't used by the format")
+	}
+
+	logger.Warn("no caller")
+	if strings.TrimSpace(out.String()) != "WARN no caller" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestContext(t *testing.T) {
+	logger, out := newBufferLogger("${trace_id} ${span_id} ${request_id} ${filename} ${message}")
+	defer logger.Close()
+
+	err := RegisterContextExtractor("test", func(ctx context.Context, fields *ContextFields) {
+		if span, ok := ctx.Value("test-span").(string); ok {
+			fields.SpanID = span
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register extractor, err: %s", err.Error())
+	}
+	defer UnregisterContextExtractor("test")
+
+	ctx := WithRequestID(WithTraceID(context.Background(), "t1"), "r1")
+	ctx = context.WithValue(ctx, "test-span", "s1")
+	logger.InfoCtx(ctx, "with context")
+	logger.Info("without context")
+
+	// Logger carried by the context
+	InfoCtx(NewContext(ctx, logger), "from context")
+
+	expected := "t1 s1 r1 logger_test.go with context\n" +
+		"- - - logger_test.go without context\n" +
+		"t1 s1 r1 logger_test.go from context\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output, expected: %q, got: %q", expected, out.String())
+	}
+
+	if FromContext(context.Background()) != loggerMgr.rootLogger {
+		t.Errorf("root logger should be returned without a logger in context")
+	}
+}
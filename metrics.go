@@ -0,0 +1,139 @@
+/**
+ * Exposition of the statistics of handlers in the Prometheus text format,
+ * without depending on the Prometheus client.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 16:12:30
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	_METRIC_PREFIX        = "gologging_"
+	_METRICS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// A metric whose value is a field of LoopStats.
+type _Metric struct {
+	name  string
+	kind  string // counter or gauge
+	help  string
+	value func(stats *LoopStats) uint64
+}
+
+var loopMetrics = []_Metric{
+	{"written_bytes_total", "counter", "Bytes of the logs written by the handler.",
+		func(stats *LoopStats) uint64 { return stats.Bytes }},
+	{"write_errors_total", "counter", "Errors of writing logs.",
+		func(stats *LoopStats) uint64 { return stats.WriteErrors }},
+	{"rotations_total", "counter", "Rotations of the log file.",
+		func(stats *LoopStats) uint64 { return stats.Rotations }},
+	{"truncated_total", "counter", "Logs whose message is truncated by the size limits.",
+		func(stats *LoopStats) uint64 { return stats.Truncated }},
+	{"handled_total", "counter", "Logs handled by the handler, including the ones below its level.",
+		func(stats *LoopStats) uint64 { return stats.Handled }},
+	{"handle_errors_total", "counter", "Logs failed to be handled.",
+		func(stats *LoopStats) uint64 { return stats.HandleErrors }},
+	{"dropped_total", "counter", "Logs dropped as they are emitted after the logger is closed, or the handler is disabled.",
+		func(stats *LoopStats) uint64 { return stats.Dropped }},
+	{"consecutive_failures", "gauge", "Logs failed to be handled in a row.",
+		func(stats *LoopStats) uint64 { return stats.ConsecutiveFailures }},
+	{"queue_depth", "gauge", "Logs in the queue of the handler.",
+		func(stats *LoopStats) uint64 { return uint64(stats.QueueDepth) }},
+	{"queue_capacity", "gauge", "Capacity of the queue of the handler.",
+		func(stats *LoopStats) uint64 { return uint64(stats.QueueCapacity) }},
+	{"queue_high_water", "gauge", "The highest number of logs in the queue of the handler.",
+		func(stats *LoopStats) uint64 { return uint64(stats.QueueHighWater) }},
+}
+
+// MetricsHandler returns a http.Handler which serves the statistics of all
+// the handlers by Stats in the Prometheus text format, e.g.
+//
+//	http.Handle("/metrics", gologging.MetricsHandler())
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", _METRICS_CONTENT_TYPE)
+		if err := WriteMetrics(w, Stats()); err != nil {
+			reportErr("failed to write metrics", err)
+		}
+	})
+}
+
+// WriteMetrics writes the statistics in the Prometheus text format. Each
+// metric is labeled by 'logger' and 'handler'.
+func WriteMetrics(w io.Writer, stats []LoopStats) error {
+	out := bufio.NewWriter(w)
+
+	writeMetricHeader(out, "records_total", "counter", "Logs written by the handler by level.")
+	for i := range stats {
+		labels := metricLabels(&stats[i])
+		for level, n := range stats[i].Records {
+			fmt.Fprintf(out, "%srecords_total{%s,level=\"%s\"} %d\n", _METRIC_PREFIX, labels,
+				Level(level).Name(), n)
+		}
+	}
+
+	for _, metric := range loopMetrics {
+		writeMetricHeader(out, metric.name, metric.kind, metric.help)
+		for i := range stats {
+			fmt.Fprintf(out, "%s%s{%s} %d\n", _METRIC_PREFIX, metric.name, metricLabels(&stats[i]),
+				metric.value(&stats[i]))
+		}
+	}
+
+	writeMetricHeader(out, "health", "gauge", "Health of the handler, 1 for the current status.")
+	for i := range stats {
+		labels := metricLabels(&stats[i])
+		for health, name := range handlerHealthNames {
+			val := 0
+			if HandlerHealth(health) == stats[i].Health {
+				val = 1
+			}
+			fmt.Fprintf(out, "%shealth{%s,status=\"%s\"} %d\n", _METRIC_PREFIX, labels, name, val)
+		}
+	}
+
+	const latency = "handle_duration_seconds"
+	writeMetricHeader(out, latency, "histogram", "Duration of handling a log.")
+	for i := range stats {
+		labels, hist := metricLabels(&stats[i]), &stats[i].Latency
+		var cumulative uint64
+		for j, n := range hist.Counts {
+			cumulative += n
+			le := "+Inf"
+			if j < len(hist.Bounds) {
+				le = strconv.FormatFloat(hist.Bounds[j].Seconds(), 'g', -1, 64)
+			}
+			fmt.Fprintf(out, "%s%s_bucket{%s,le=\"%s\"} %d\n", _METRIC_PREFIX, latency, labels, le, cumulative)
+		}
+		fmt.Fprintf(out, "%s%s_sum{%s} %s\n", _METRIC_PREFIX, latency, labels,
+			strconv.FormatFloat(hist.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(out, "%s%s_count{%s} %d\n", _METRIC_PREFIX, latency, labels, hist.Count)
+	}
+
+	return out.Flush()
+}
+
+func writeMetricHeader(out io.Writer, name, kind, help string) {
+	fmt.Fprintf(out, "# HELP %s%s %s\n", _METRIC_PREFIX, name, help)
+	fmt.Fprintf(out, "# TYPE %s%s %s\n", _METRIC_PREFIX, name, kind)
+}
+
+func metricLabels(stats *LoopStats) string {
+	return fmt.Sprintf("logger=\"%s\",handler=\"%s\"", escapeLabel(stats.Logger), escapeLabel(stats.Handler))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(val string) string {
+	return labelEscaper.Replace(val)
+}
@@ -0,0 +1,189 @@
+/**
+ * Policies of the newlines in messages, so that line-oriented log shippers
+ * don't split a multi-line message, e.g. a stack trace, into records.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 14:10:36
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"github.com/chosen0ne/goutils"
+	"strings"
+)
+
+type MultilinePolicy int
+
+const (
+	MULTILINE_KEEP   MultilinePolicy = iota // newlines are written as they are
+	MULTILINE_ESCAPE                        // newlines are escaped as '\n', so each log is one line
+	MULTILINE_INDENT                        // continuation lines are indented by a tab
+	MULTILINE_PREFIX                        // continuation lines are prefixed by the header and '| '
+)
+
+const (
+	_ESCAPE_CHAR = '\\'
+	_INDENT      = "\t"
+	// Mark after the header of a continuation line of MULTILINE_PREFIX.
+	_CONTINUATION_MARK = "| "
+)
+
+var multilinePolicyNames = []string{
+	"keep",
+	"escape",
+	"indent",
+	"prefix",
+}
+
+func NewMultilinePolicyString(name string) (MultilinePolicy, error) {
+	for idx, policyName := range multilinePolicyNames {
+		if policyName == strings.ToLower(name) {
+			return MultilinePolicy(idx), nil
+		}
+	}
+
+	return MULTILINE_KEEP, goutils.NewErr("unknown multiline policy: %s", name)
+}
+
+func (policy MultilinePolicy) Name() string {
+	if policy >= MULTILINE_KEEP && int(policy) < len(multilinePolicyNames) {
+		return multilinePolicyNames[policy]
+	}
+
+	return "UNKNOWN_MULTILINE_POLICY"
+}
+
+// WithMultiline returns a copy of the formatter whose messages are written
+// by the policy. The header of MULTILINE_PREFIX is the text formatted before
+// ${message}, e.g.
+//
+//	2026-10-18 15:04:05 [ERROR] panic: boom
+//	2026-10-18 15:04:05 [ERROR] | goroutine 1 [running]:
+//
+// A JSON formatter escapes newlines anyway, so the policy is ignored.
+func (format *Formatter) WithMultiline(policy MultilinePolicy) *Formatter {
+	formatter := *format
+	if format.jsonFields == nil {
+		formatter.multiline = policy
+	}
+
+	return &formatter
+}
+
+// Multiline returns the policy of the newlines in messages.
+func (format *Formatter) Multiline() MultilinePolicy {
+	return format.multiline
+}
+
+// appendMultiline appends the message by the policy, 'header' is the start
+// of the log in 'dst'.
+func (format *Formatter) appendMultiline(dst []byte, header int, msg *_Msg) []byte {
+	message := msg.message
+	if format.multiline == MULTILINE_ESCAPE {
+		if bytes.IndexByte(message, _NEWLINE) == -1 && bytes.IndexByte(message, '\r') == -1 &&
+			bytes.IndexByte(message, _ESCAPE_CHAR) == -1 {
+			return append(dst, message...)
+		}
+
+		for _, c := range message {
+			switch c {
+			case _NEWLINE:
+				dst = append(dst, _ESCAPE_CHAR, 'n')
+			case '\r':
+				dst = append(dst, _ESCAPE_CHAR, 'r')
+			case _ESCAPE_CHAR:
+				dst = append(dst, _ESCAPE_CHAR, _ESCAPE_CHAR)
+			default:
+				dst = append(dst, c)
+			}
+		}
+		return dst
+	}
+
+	headerEnd := len(dst)
+	for {
+		idx := bytes.IndexByte(message, _NEWLINE)
+		if idx == -1 {
+			return append(dst, message...)
+		}
+
+		dst = append(dst, message[:idx+1]...)
+		if format.multiline == MULTILINE_INDENT {
+			dst = append(dst, _INDENT...)
+		} else {
+			dst = append(dst, dst[header:headerEnd]...)
+			dst = append(dst, _CONTINUATION_MARK...)
+		}
+		message = message[idx+1:]
+	}
+}
+
+// restoreMessage reverts the message parsed from a log written by the
+// policy, 'header' is the text before the message.
+func (format *Formatter) restoreMessage(message, header string) string {
+	switch format.multiline {
+	case MULTILINE_ESCAPE:
+		if strings.IndexByte(message, _ESCAPE_CHAR) == -1 {
+			return message
+		}
+
+		var b strings.Builder
+		for i := 0; i < len(message); i++ {
+			c := message[i]
+			if c == _ESCAPE_CHAR && i+1 < len(message) {
+				switch message[i+1] {
+				case 'n':
+					c = _NEWLINE
+					i++
+				case 'r':
+					c = '\r'
+					i++
+				case _ESCAPE_CHAR:
+					i++
+				}
+			}
+			b.WriteByte(c)
+		}
+		return b.String()
+
+	case MULTILINE_INDENT:
+		return strings.ReplaceAll(message, string(_NEWLINE)+_INDENT, string(_NEWLINE))
+
+	case MULTILINE_PREFIX:
+		return strings.ReplaceAll(message, string(_NEWLINE)+header+_CONTINUATION_MARK, string(_NEWLINE))
+	}
+
+	return message
+}
+
+// matchHeader matches the start of a log up to ${message}, and returns the
+// attributes with the rest of the line as the message, and the header.
+// False if the line isn't the first line of a log.
+func (format *Formatter) matchHeader(line string) (map[string]string, string, bool) {
+	segments := format.segments
+	if format.msgIdx >= 0 {
+		segments = segments[:format.msgIdx+1]
+	}
+
+	attrs := make(map[string]string)
+	if !matchSegments(segments, line, attrs) {
+		return nil, "", false
+	}
+
+	return attrs, line[:len(line)-len(attrs[_MESSAGE])], true
+}
+
+// isContinuation reports whether the line continues the log with the header
+// by the policy.
+func (format *Formatter) isContinuation(line, header string) bool {
+	switch format.multiline {
+	case MULTILINE_INDENT:
+		return strings.HasPrefix(line, _INDENT)
+	case MULTILINE_PREFIX:
+		return strings.HasPrefix(line, header+_CONTINUATION_MARK)
+	}
+
+	return false
+}
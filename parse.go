@@ -0,0 +1,243 @@
+/**
+ * Parsing of formatted log lines back into records.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 09:12:44
+ */
+
+package gologging
+
+import (
+	"errors"
+	"github.com/chosen0ne/goutils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log parsed from the lines formatted by a Formatter.
+type Record struct {
+	// Time of ${datetime}, or ${date} and ${time}, in the local timezone.
+	// Zero if the format has neither.
+	Time time.Time
+	// Level of ${levelname}, which is only valid if it's in Attrs.
+	Level   Level
+	Logger  string
+	Message string
+	// Values of the attributes in the format by name, e.g. 'lineno'.
+	Attrs map[string]string
+	// The lines of the log as they are read, without the last newline.
+	Raw string
+}
+
+// Widths of the attributes formatted in fixed width.
+var attrWidths = map[string]int{
+	_DATE:     len(_DATE_LAYOUT),
+	_TIME:     len(_TIME_LAYOUT),
+	_DATETIME: len(_DATETIME_LAYOUT),
+}
+
+var errLineNotMatch = errors.New("line doesn't match the format")
+
+// Parse parses a log formatted by the formatter back into a record, the
+// newline at the end is optional. The lines of a multi-line message are kept
+// in Record.Message, e.g. a stack trace, as only ${message} can contain
+// newlines, and they are restored by the multiline policy of the formatter.
+// A log of a JSON formatter is decoded, and the values of its fields are
+// matched by the format.
+func (format *Formatter) Parse(line []byte) (Record, error) {
+	record, err := format.parse(strings.TrimSuffix(string(line), string(_NEWLINE)))
+	if err != nil {
+		return Record{}, err
+	}
+
+	return *record, nil
+}
+
+func (format *Formatter) parse(line string) (*Record, error) {
+	if format.jsonFields != nil {
+		attrs, err := format.parseJSON(line)
+		if err != nil {
+			return nil, goutils.WrapErrorf(err, "failed to parse JSON log")
+		}
+		return newRecord(attrs, line), nil
+	}
+
+	attrs := make(map[string]string)
+	if !matchSegments(format.segments, line, attrs) {
+		return nil, errLineNotMatch
+	}
+
+	if format.multiline != MULTILINE_KEEP {
+		firstLine := line
+		if idx := strings.IndexByte(line, _NEWLINE); idx != -1 {
+			firstLine = line[:idx]
+		}
+		if _, header, ok := format.matchHeader(firstLine); ok {
+			attrs[_MESSAGE] = format.restoreMessage(attrs[_MESSAGE], header)
+		}
+	}
+
+	return newRecord(attrs, line), nil
+}
+
+// parseLine parses a line without the newline, false if it doesn't match.
+func (format *Formatter) parseLine(line string) (*Record, bool) {
+	record, err := format.parse(line)
+	return record, err == nil
+}
+
+// matchSegments matches 'line' with the segments, and sets the values of
+// the attributes. An attribute ends before the next literal, so all the
+// occurrences of the literal are tried until the rest of the line matches.
+// ${message} tries the longest value first, as it may include the literal.
+func matchSegments(segments []fmtSegment, line string, attrs map[string]string) bool {
+	m := &_SegmentMatcher{segments: segments, line: line, attrs: attrs, failed: make(map[int]bool)}
+	return m.match(0, 0)
+}
+
+// _SegmentMatcher remembers the segments and offsets which failed to match
+// the rest of the line, as the same rest is reached by different values of
+// the attributes before, so each one is tried at most once and a line which
+// doesn't match can't take exponential time.
+type _SegmentMatcher struct {
+	segments []fmtSegment
+	line     string
+	attrs    map[string]string
+	// Keyed by idx*(len(line)+1)+offset.
+	failed map[int]bool
+}
+
+// match matches the segments from 'idx' with the line from 'offset'.
+func (m *_SegmentMatcher) match(idx, offset int) bool {
+	key := idx*(len(m.line)+1) + offset
+	if m.failed[key] {
+		return false
+	}
+
+	if m.matchSegment(idx, offset) {
+		return true
+	}
+	m.failed[key] = true
+
+	return false
+}
+
+func (m *_SegmentMatcher) matchSegment(idx, offset int) bool {
+	segments, line := m.segments[idx:], m.line[offset:]
+	if len(segments) == 0 {
+		return line == ""
+	}
+
+	seg := &segments[0]
+	if seg.appendVal == nil {
+		literal := segmentLiteral(m.segments, idx)
+		return strings.HasPrefix(line, literal) && m.match(idx+1, offset+len(literal))
+	}
+
+	if width, ok := attrWidths[seg.attr]; ok {
+		return len(line) >= width && setAttr(m.attrs, seg.attr, line[:width]) &&
+			m.match(idx+1, offset+width)
+	}
+
+	var ends []int
+	switch {
+	case len(segments) == 1 || segmentLiteral(m.segments, idx+1) == "":
+		// The last attribute takes the rest of the line
+		ends = []int{len(line)}
+	case segments[1].appendVal != nil:
+		// Attributes without a literal between can't be split
+		return false
+	default:
+		literal := segmentLiteral(m.segments, idx+1)
+		for start := 0; ; {
+			pos := strings.Index(line[start:], literal)
+			if pos == -1 {
+				break
+			}
+			ends = append(ends, start+pos)
+			start += pos + 1
+		}
+	}
+
+	for i := range ends {
+		end := ends[i]
+		if seg.attr == _MESSAGE {
+			end = ends[len(ends)-1-i]
+		}
+
+		if setAttr(m.attrs, seg.attr, line[:end]) && m.match(idx+1, offset+end) {
+			return true
+		}
+	}
+	delete(m.attrs, seg.attr)
+
+	return false
+}
+
+// segmentLiteral returns the literal of the segment, the newline at the end
+// of the format is excluded.
+func segmentLiteral(segments []fmtSegment, idx int) string {
+	literal := string(segments[idx].literal)
+	if idx == len(segments)-1 {
+		literal = strings.TrimSuffix(literal, string(_NEWLINE))
+	}
+
+	return literal
+}
+
+// setAttr sets the value if it's valid for the attribute.
+func setAttr(attrs map[string]string, attr, val string) bool {
+	var valid bool
+	switch attr {
+	case _DATE:
+		_, err := time.ParseInLocation(_DATE_LAYOUT, val, time.Local)
+		valid = err == nil
+	case _TIME:
+		_, err := time.ParseInLocation(_TIME_LAYOUT, val, time.Local)
+		valid = err == nil
+	case _DATETIME:
+		_, err := time.ParseInLocation(_DATETIME_LAYOUT, val, time.Local)
+		valid = err == nil
+	case _LINENO:
+		_, err := strconv.Atoi(val)
+		valid = err == nil
+	case _LEVELNAME:
+		valid = NewLevelString(val).IsValid()
+	case _MESSAGE:
+		valid = true
+	default:
+		valid = val != "" && !strings.ContainsRune(val, _NEWLINE)
+	}
+
+	if valid {
+		attrs[attr] = val
+	}
+
+	return valid
+}
+
+func newRecord(attrs map[string]string, raw string) *Record {
+	record := &Record{
+		Logger:  attrs[_LOGGER_NAME],
+		Message: attrs[_MESSAGE],
+		Attrs:   attrs,
+		Raw:     raw,
+	}
+
+	if lvStr, ok := attrs[_LEVELNAME]; ok {
+		record.Level = NewLevelString(lvStr)
+	}
+
+	if datetime, ok := attrs[_DATETIME]; ok {
+		record.Time, _ = time.ParseInLocation(_DATETIME_LAYOUT, datetime, time.Local)
+	} else if date, ok := attrs[_DATE]; ok {
+		layout, val := _DATE_LAYOUT, date
+		if t, ok := attrs[_TIME]; ok {
+			layout, val = _DATETIME_LAYOUT, date+" "+t
+		}
+		record.Time, _ = time.ParseInLocation(layout, val, time.Local)
+	}
+
+	return record
+}
@@ -0,0 +1,164 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 11:20:31
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	formatter, _ := NewFormatter(defautlFormatStr)
+	msg := &_Msg{
+		time:       time.Date(2026, 10, 18, 15, 4, 5, 0, time.Local),
+		level:      WARN,
+		loggerName: "app",
+		fileName:   "/src/app/main.go",
+		lineNo:     42,
+		funcName:   "main.main",
+		message:    []byte("disk [usage] 91%: main.go:1 [WARN]"),
+	}
+
+	line := formatter.Format(msg)
+	record, ok := formatter.parseLine(string(line[:len(line)-1]))
+	if !ok {
+		t.Fatalf("failed to parse line")
+	}
+	if !record.Time.Equal(msg.time) || record.Level != WARN || record.Logger != "app" ||
+		record.Message != string(msg.message) || record.Attrs[_LINENO] != "42" || record.Attrs[_FILENAME] != "main.go" ||
+		record.Attrs[_FUNCNAME] != "main.main" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	formatter, _ = NewFormatter("${date} ${time} ${levelname}: ${message} (${lineno})")
+	record, ok = formatter.parseLine("2026-10-18 15:04:05 ERROR: failed (retry) (7)")
+	if !ok || record.Message != "failed (retry)" || record.Attrs[_LINENO] != "7" || !record.Time.Equal(msg.time) {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	for _, line := range []string{"", "2026-10-18 15:04:05 VERBOSE: failed (7)", "2026-10-18 ERROR: x (7)",
+		"2026-10-18 15:04:05 ERROR: failed (x)"} {
+		if record, ok := formatter.parseLine(line); ok {
+			t.Fatalf("invalid line is parsed: %q, record: %+v", line, record)
+		}
+	}
+}
+
+// A line which doesn't match tries each occurrence of a literal once, so it
+// doesn't take exponential time.
+func TestParseLongLine(t *testing.T) {
+	formatter, _ := NewFormatter("${name} ${funcname} ${filename} ${message} ${lineno}")
+	line := strings.Repeat("a ", 1000) + "x"
+	if record, ok := formatter.parseLine(line); ok {
+		t.Fatalf("invalid line is parsed, record: %+v", record)
+	}
+
+	record, ok := formatter.parseLine(strings.Repeat("a ", 1000) + "7")
+	if !ok || record.Logger != "a" || record.Attrs[_LINENO] != "7" ||
+		record.Message != strings.TrimSuffix(strings.Repeat("a ", 997), " ") {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
+
+func TestParse(t *testing.T) {
+	msg := &_Msg{
+		time:       time.Date(2026, 10, 18, 15, 4, 5, 0, time.Local),
+		level:      ERROR,
+		loggerName: "app",
+		fileName:   "/src/app/main.go",
+		lineNo:     42,
+		funcName:   "main.main",
+		message:    []byte("panic: \"boom\"\n\tmain.go:42 [ERROR]\n\tproc.go:250"),
+	}
+
+	formatter, _ := NewFormatter(defautlFormatStr)
+	record, err := formatter.Parse(formatter.Format(msg))
+	if err != nil {
+		t.Fatalf("failed to parse multi-line log, err: %v", err)
+	}
+	if record.Message != string(msg.message) || record.Level != ERROR || record.Attrs[_LINENO] != "42" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if _, err := formatter.Parse([]byte("main.go:42 [ERROR]\n")); err == nil {
+		t.Fatalf("invalid line is parsed")
+	}
+
+	formatter, err = NewFormatter(`{"ts": "${date}T${time}", "level": "${levelname}", ` +
+		`"caller": {"file": "${filename}", "line": ${lineno}}, "msg": "${message}"}`)
+	if err != nil || formatter.jsonFields == nil {
+		t.Fatalf("failed to create JSON formatter, err: %v", err)
+	}
+	line := formatter.Format(msg)
+	if !json.Valid(line) || bytes.Count(line, []byte{'\n'}) != 1 {
+		t.Fatalf("invalid JSON log: %s", line)
+	}
+
+	record, err = formatter.Parse(line)
+	if err != nil {
+		t.Fatalf("failed to parse JSON log, err: %v", err)
+	}
+	if !record.Time.Equal(msg.time) || record.Level != ERROR || record.Message != string(msg.message) ||
+		record.Attrs[_LINENO] != "42" || record.Attrs[_FILENAME] != "main.go" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	for _, line := range []string{"", "{}", `{"ts": "2026-10-18", "level": "ERROR"}`,
+		`{"ts": "2026-10-18T15:04:05", "level": "ERROR", "caller": {"file": "a.go", "line": "x"}, "msg": ""}`} {
+		if record, err := formatter.Parse([]byte(line)); err == nil {
+			t.Fatalf("invalid line is parsed: %q, record: %+v", line, record)
+		}
+	}
+
+	// Not a JSON object
+	formatter, _ = NewFormatter("{${levelname}} ${message}")
+	if formatter.jsonFields != nil {
+		t.Fatalf("text format is taken as JSON")
+	}
+}
+
+func TestMultiline(t *testing.T) {
+	msg := &_Msg{
+		time:    time.Date(2026, 10, 18, 15, 4, 5, 0, time.Local),
+		level:   ERROR,
+		lineNo:  7,
+		message: []byte("panic: C:\\tmp\\n\n\tmain.go:42\n"),
+	}
+	expects := map[MultilinePolicy]string{
+		MULTILINE_KEEP:   "2026-10-18 15:04:05 [ERROR] panic: C:\\tmp\\n\n\tmain.go:42\n (7)\n",
+		MULTILINE_ESCAPE: "2026-10-18 15:04:05 [ERROR] panic: C:\\\\tmp\\\\n\\n\tmain.go:42\\n (7)\n",
+		MULTILINE_INDENT: "2026-10-18 15:04:05 [ERROR] panic: C:\\tmp\\n\n\t\tmain.go:42\n\t (7)\n",
+		MULTILINE_PREFIX: "2026-10-18 15:04:05 [ERROR] panic: C:\\tmp\\n\n2026-10-18 15:04:05 [ERROR] | \tmain.go:42\n" +
+			"2026-10-18 15:04:05 [ERROR] |  (7)\n",
+	}
+
+	formatter, _ := NewFormatter("${datetime} [${levelname}] ${message} (${lineno})")
+	for policy, expect := range expects {
+		format := formatter.WithMultiline(policy)
+		line := format.Format(msg)
+		if string(line) != expect {
+			t.Fatalf("unexpected log of %s: %q", policy.Name(), line)
+		}
+
+		record, err := format.Parse(line)
+		if err != nil {
+			t.Fatalf("failed to parse log of %s, err: %v", policy.Name(), err)
+		}
+		if record.Message != string(msg.message) || record.Attrs[_LINENO] != "7" {
+			t.Fatalf("unexpected record of %s: %+v", policy.Name(), record)
+		}
+	}
+
+	if formatter.Multiline() != MULTILINE_KEEP {
+		t.Fatalf("formatter is changed by WithMultiline")
+	}
+	if _, err := NewMultilinePolicyString("fold"); err == nil {
+		t.Fatalf("invalid policy is parsed")
+	}
+}
@@ -0,0 +1,191 @@
+/**
+ * Permissions and ownership of log files and directories.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 16:40:12
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	_DEFAULT_DIR_MODE = os.ModePerm & 0755
+)
+
+// FilePerm specifies the permissions and ownership of log files, including
+// the files created on rotation, and of the directories created for them.
+type FilePerm struct {
+	// Mode of log files, which is applied regardless of the umask of the
+	// process. 0 means 0644 for new files and unchanged for existing ones.
+	FileMode os.FileMode
+	// Mode of the missing directories created for log files, 0755 by
+	// default.
+	DirMode os.FileMode
+	// User name or uid of the owner of log files, empty means the user of
+	// the process.
+	Owner string
+	// Group name or gid of log files, empty means the group of the process.
+	Group string
+}
+
+func (perm *FilePerm) fileMode() os.FileMode {
+	if perm == nil || perm.FileMode == 0 {
+		return _OPEN_FILE_MODE
+	}
+
+	return perm.FileMode & os.ModePerm
+}
+
+func (perm *FilePerm) dirMode() os.FileMode {
+	if perm == nil || perm.DirMode == 0 {
+		return _DEFAULT_DIR_MODE
+	}
+
+	return perm.DirMode & os.ModePerm
+}
+
+// ids resolves the owner and group to uid and gid, -1 means unchanged.
+func (perm *FilePerm) ids() (int, int, error) {
+	uid, gid := -1, -1
+	if perm == nil {
+		return uid, gid, nil
+	}
+
+	if perm.Owner != "" {
+		id := perm.Owner
+		if _, err := strconv.Atoi(id); err != nil {
+			u, err := user.Lookup(perm.Owner)
+			if err != nil {
+				return -1, -1, goutils.WrapErrorf(err, "failed to lookup user, user: %s", perm.Owner)
+			}
+			id = u.Uid
+		}
+		var err error
+		if uid, err = strconv.Atoi(id); err != nil {
+			return -1, -1, goutils.NewErr("invalid uid, user: %s, uid: %s", perm.Owner, id)
+		}
+	}
+
+	if perm.Group != "" {
+		id := perm.Group
+		if _, err := strconv.Atoi(id); err != nil {
+			g, err := user.LookupGroup(perm.Group)
+			if err != nil {
+				return -1, -1, goutils.WrapErrorf(err, "failed to lookup group, group: %s", perm.Group)
+			}
+			id = g.Gid
+		}
+		var err error
+		if gid, err = strconv.Atoi(id); err != nil {
+			return -1, -1, goutils.NewErr("invalid gid, group: %s, gid: %s", perm.Group, id)
+		}
+	}
+
+	return uid, gid, nil
+}
+
+func (perm *FilePerm) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "FilePerm{fileMode: %#o, dirMode: %#o, owner: %s, group: %s}",
+		perm.fileMode(), perm.dirMode(), perm.Owner, perm.Group)
+
+	return string(b.Bytes())
+}
+
+// openLogFile opens the log file for appending. A new file is created with
+// the mode of 'perm', so it's never more permissive than expected even for
+// a moment. Then the mode and ownership are applied to the opened file.
+func openLogFile(fileName string, perm *FilePerm) (*os.File, error) {
+	file, err := os.OpenFile(fileName, _OPEN_FILE_FLAG, perm.fileMode())
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to open file, file: %s", fileName)
+	}
+
+	if err := applyFilePerm(file, perm); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func applyFilePerm(file *os.File, perm *FilePerm) error {
+	if perm == nil {
+		return nil
+	}
+
+	if perm.FileMode != 0 {
+		if err := file.Chmod(perm.fileMode()); err != nil {
+			return goutils.WrapErrorf(err, "failed to chmod file, file: %s, mode: %#o",
+				file.Name(), perm.fileMode())
+		}
+	}
+
+	uid, gid, err := perm.ids()
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to resolve owner")
+	}
+
+	if uid != -1 || gid != -1 {
+		if err := file.Chown(uid, gid); err != nil {
+			return goutils.WrapErrorf(err, "failed to chown file, file: %s, owner: %s, group: %s",
+				file.Name(), perm.Owner, perm.Group)
+		}
+	}
+
+	return nil
+}
+
+// createLogFile creates the log file with 'perm' if it doesn't exist, which
+// is called before the file is opened by a handler.
+func createLogFile(fileName string, perm *FilePerm) error {
+	if err := prepareLogDir(fileName, perm); err != nil {
+		return goutils.WrapErrorf(err, "failed to prepare log dir")
+	}
+
+	file, err := openLogFile(fileName, perm)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to create log file")
+	}
+
+	return file.Close()
+}
+
+// prepareLogDir creates the missing directories of the log file, and checks
+// that the directory is writable, so that a misconfigured path is reported
+// at startup instead of at the first rotation.
+func prepareLogDir(fileName string, perm *FilePerm) error {
+	dir := filepath.Dir(fileName)
+	if err := os.MkdirAll(dir, perm.dirMode()); err != nil {
+		return goutils.WrapErrorf(err, "failed to create log dir, dir: %s", dir)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to stat log dir, dir: %s", dir)
+	}
+	if !info.IsDir() {
+		return goutils.NewErr("log path isn't a directory, path: %s", dir)
+	}
+
+	// Rotation creates files in the directory, so it must be writable
+	// even if the log file exists.
+	tmp, err := os.CreateTemp(dir, ".gologging-*")
+	if err != nil {
+		return goutils.WrapErrorf(err, "log dir isn't writable, dir: %s", dir)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	return nil
+}
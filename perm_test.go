@@ -0,0 +1,54 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 17:05:48
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFilePerm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file mode isn't supported on windows")
+	}
+
+	fileName := filepath.Join(t.TempDir(), "audit", "2026", "audit.log")
+	perm := &FilePerm{FileMode: 0600, DirMode: 0700}
+	if err := createLogFile(fileName, perm); err != nil {
+		t.Fatalf("failed to create log file, err: %s", err.Error())
+	}
+
+	handler, err := NewSizeRotateFileHandler(fileName, MB, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %s", err.Error())
+	}
+	defer handler.Close()
+
+	if err := handler.SetFilePerm(*perm); err != nil {
+		t.Fatalf("failed to set file perm, err: %s", err.Error())
+	}
+	if _, err := handler.rotateFile(time.Now(), handler.backupCount, ROTATE_BY_SIZE); err != nil {
+		t.Fatalf("failed to rotate, err: %s", err.Error())
+	}
+
+	for path, mode := range map[string]os.FileMode{
+		filepath.Dir(fileName): 0700,
+		fileName:               0600,
+		fileName + "_0001":     0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat, path: %s, err: %s", path, err.Error())
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("unexpected mode, path: %s, mode: %#o, expected: %#o", path, info.Mode().Perm(), mode)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build race
+
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 11:20:05
+ */
+
+package gologging
+
+// sync.Pool drops objects randomly with the race detector, which makes
+// the allocation counts meaningless.
+func init() {
+	raceEnabled = true
+}
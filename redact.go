@@ -0,0 +1,231 @@
+/**
+ * Redaction of secrets and PII in log messages.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 18:21:44
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// Text replacing the redacted values.
+	REDACTED = "***"
+
+	DETECTOR_CREDIT_CARD = "credit-card"
+	DETECTOR_EMAIL       = "email"
+	DETECTOR_BEARER      = "bearer"
+)
+
+// Keys masked by default when no key is specified, e.g. by NewRedactor.
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization"}
+
+type _RedactRule struct {
+	name string
+	re   *regexp.Regexp
+	// Group of the match to replace, 0 means the whole match.
+	group int
+	// Optional check of the matched text to reduce false positives.
+	check func(match []byte) bool
+}
+
+var detectors = map[string]_RedactRule{
+	DETECTOR_CREDIT_CARD: {
+		name:  DETECTOR_CREDIT_CARD,
+		re:    regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		check: luhnValid,
+	},
+	DETECTOR_EMAIL: {
+		name: DETECTOR_EMAIL,
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	DETECTOR_BEARER: {
+		name:  DETECTOR_BEARER,
+		re:    regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+		group: 1,
+	},
+}
+
+// Redactor masks secrets in log messages before they are formatted by any
+// handler, so they are never written. Values of sensitive keys in the forms
+// of 'key=value', 'key: value' and '"key": "value"' are masked, as well as
+// the text matching the rules and detectors. A Redactor must not be changed
+// after it's set to a logger.
+type Redactor struct {
+	keys  []string
+	rules []_RedactRule
+	// Number of redacted values.
+	count uint64
+}
+
+// NewRedactor returns a Redactor masking 'keys', or DefaultRedactKeys if
+// no key is specified. Keys are case-insensitive.
+func NewRedactor(keys ...string) (*Redactor, error) {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+
+	redactor := &Redactor{}
+	if err := redactor.MaskKeys(keys...); err != nil {
+		return nil, err
+	}
+
+	return redactor, nil
+}
+
+// MaskKeys adds keys whose values are masked. A key matches the names
+// containing it as well, e.g. 'password' matches 'db_password' and
+// 'passwordHash', and 'secret' matches 'client_secret'.
+func (redactor *Redactor) MaskKeys(keys ...string) error {
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return goutils.NewErr("empty redact key")
+		}
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	// The name is a word of letters, digits, '_', '.' and '-' containing
+	// a key. The value is a quoted string, an auth scheme with credentials
+	// or a word ended by a space or a separator.
+	expr := `(?i)[\w.\-]*(?:` + strings.Join(quoted, "|") + `)[\w.\-]*"?\s*[:=]\s*` +
+		`("[^"]*"|(?:bearer|basic)\s+[^\s,;&"]+|[^\s,;&"]+)`
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to compile redact keys, keys: %v", keys)
+	}
+
+	redactor.keys = append(redactor.keys, keys...)
+	redactor.rules = append(redactor.rules, _RedactRule{name: "keys", re: re, group: 1})
+
+	return nil
+}
+
+// AddRule adds a regexp, text matching it is masked. If the regexp has a
+// capturing group, only the first group is masked.
+func (redactor *Redactor) AddRule(name, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to compile redact rule, name: %s, pattern: %s",
+			name, pattern)
+	}
+
+	rule := _RedactRule{name: name, re: re}
+	if re.NumSubexp() > 0 {
+		rule.group = 1
+	}
+	redactor.rules = append(redactor.rules, rule)
+
+	return nil
+}
+
+// AddDetector adds a built-in detector, which is one of
+// DETECTOR_CREDIT_CARD, DETECTOR_EMAIL and DETECTOR_BEARER.
+func (redactor *Redactor) AddDetector(name string) error {
+	rule, ok := detectors[strings.ToLower(name)]
+	if !ok {
+		return goutils.NewErr("unknown redact detector: %s", name)
+	}
+	redactor.rules = append(redactor.rules, rule)
+
+	return nil
+}
+
+// Count returns the number of redacted values.
+func (redactor *Redactor) Count() uint64 {
+	return atomic.LoadUint64(&redactor.count)
+}
+
+// Redact returns the message with secrets masked. 'msg' is returned as it
+// is if nothing is redacted.
+func (redactor *Redactor) Redact(msg []byte) []byte {
+	for i := range redactor.rules {
+		msg = redactor.apply(&redactor.rules[i], msg)
+	}
+
+	return msg
+}
+
+func (redactor *Redactor) apply(rule *_RedactRule, msg []byte) []byte {
+	matches := rule.re.FindAllSubmatchIndex(msg, -1)
+	if len(matches) == 0 {
+		return msg
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*rule.group], m[2*rule.group+1]
+		if start < 0 || (rule.check != nil && !rule.check(msg[start:end])) {
+			continue
+		}
+
+		if out == nil {
+			out = make([]byte, 0, len(msg))
+		}
+		out = append(out, msg[last:start]...)
+		if end-start >= 2 && msg[start] == '"' && msg[end-1] == '"' {
+			// Keep the quotes of a quoted value
+			out = append(out, '"')
+			out = append(out, REDACTED...)
+			out = append(out, '"')
+		} else {
+			out = append(out, REDACTED...)
+		}
+		last = end
+		atomic.AddUint64(&redactor.count, 1)
+	}
+
+	if out == nil {
+		return msg
+	}
+
+	return append(out, msg[last:]...)
+}
+
+func (redactor *Redactor) String() string {
+	var b bytes.Buffer
+
+	names := make([]string, 0, len(redactor.rules))
+	for _, rule := range redactor.rules {
+		names = append(names, rule.name)
+	}
+	fmt.Fprintf(&b, "Redactor{keys: %v, rules: %v, count: %d}", redactor.keys, names, redactor.Count())
+
+	return string(b.Bytes())
+}
+
+// luhnValid checks the digits of a card number by the Luhn algorithm,
+// spaces and dashes are ignored.
+func luhnValid(number []byte) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+
+	return n >= 13 && sum%10 == 0
+}
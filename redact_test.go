@@ -0,0 +1,77 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 18:46:03
+ */
+
+package gologging
+
+import (
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor, err := NewRedactor()
+	if err != nil {
+		t.Fatalf("failed to create redactor, err: %s", err.Error())
+	}
+	for _, name := range []string{DETECTOR_CREDIT_CARD, DETECTOR_EMAIL, DETECTOR_BEARER} {
+		if err := redactor.AddDetector(name); err != nil {
+			t.Fatalf("failed to add detector, err: %s", err.Error())
+		}
+	}
+	if err := redactor.AddRule("ssn", `ssn=(\d{3}-\d{2}-\d{4})`); err != nil {
+		t.Fatalf("failed to add rule, err: %s", err.Error())
+	}
+
+	cases := []struct {
+		in, out string
+	}{
+		{"login user=bob password=s3cret ok", "login user=bob password=*** ok"},
+		{`{"Token": "abc def", "id": 1}`, `{"Token": "***", "id": 1}`},
+		{"Authorization: Bearer eyJhbGciOi.x-y", "Authorization: ***"},
+		{"header bearer abc.def", "header bearer ***"},
+		{"card 4111 1111 1111 1111 paid", "card *** paid"},
+		{"order 1234567890123 paid", "order 1234567890123 paid"},
+		{"mail to bob@example.com", "mail to ***"},
+		{"ssn=123-45-6789", "ssn=***"},
+		{"nothing secret", "nothing secret"},
+	}
+	for _, c := range cases {
+		if out := string(redactor.Redact([]byte(c.in))); out != c.out {
+			t.Errorf("unexpected redaction, in: %q, expected: %q, got: %q", c.in, c.out, out)
+		}
+	}
+
+	if redactor.Count() != 7 {
+		t.Errorf("unexpected count of redactions: %d", redactor.Count())
+	}
+
+	if err := redactor.AddDetector("unknown"); err == nil {
+		t.Errorf("unknown detector should be rejected")
+	}
+}
+
+func TestRedactCompoundKeys(t *testing.T) {
+	redactor, err := NewRedactor("password", "token", "secret", "api-key")
+	if err != nil {
+		t.Fatalf("failed to create redactor, err: %s", err.Error())
+	}
+
+	cases := []struct {
+		in, out string
+	}{
+		{"connect db_password=s3cret host=db", "connect db_password=*** host=db"},
+		{"access_token=abc&user=bob", "access_token=***&user=bob"},
+		{"client_secret: xyz", "client_secret: ***"},
+		{"passwordHash=9f86d0 ok", "passwordHash=*** ok"},
+		{`{"DB.Password": "p w", "x-api-key": "k"}`, `{"DB.Password": "***", "x-api-key": "***"}`},
+		{"password-reset succeeded", "password-reset succeeded"},
+		{"tokens are refreshed", "tokens are refreshed"},
+	}
+	for _, c := range cases {
+		if out := string(redactor.Redact([]byte(c.in))); out != c.out {
+			t.Errorf("unexpected redaction, in: %q, expected: %q, got: %q", c.in, c.out, out)
+		}
+	}
+}
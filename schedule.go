@@ -0,0 +1,277 @@
+/**
+ * Schedules of time rotation.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 14:52:10
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Cron schedules are searched in this range, otherwise they never match.
+	_MAX_CRON_YEARS = 5
+)
+
+// RotateSchedule decides when a time rotated file is rotated.
+type RotateSchedule interface {
+	// Next returns the first rotation time after 't', in the location of 't'.
+	// A zero time means no more rotation.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// intervalSchedule rotates every 'interval', and the rotation times are
+// aligned to the calendar in the location of the time:
+//	multiple of MONTH: the first day of months, counted from January
+//	multiple of WEEK: Mondays
+//	multiple of DAY: midnights, counted from 1970-01-01
+//	divisor of DAY: midnight plus multiples of 'interval'
+// Other intervals are aligned to the Unix epoch in the location.
+type intervalSchedule struct {
+	interval RotateInterval
+}
+
+func NewIntervalSchedule(interval RotateInterval) RotateSchedule {
+	return &intervalSchedule{interval}
+}
+
+func (s *intervalSchedule) Next(t time.Time) time.Time {
+	interval := int64(s.interval)
+	loc := t.Location()
+	y, m, d := t.Date()
+
+	switch {
+	case interval%int64(MONTH) == 0:
+		n := int(interval / int64(MONTH))
+		months := y*12 + int(m) - 1
+		next := (months/n + 1) * n
+		return time.Date(next/12, time.Month(next%12+1), 1, 0, 0, 0, 0, loc)
+
+	case interval%int64(WEEK) == 0:
+		// 1970-01-05 is the first Monday after the epoch.
+		n := interval / int64(WEEK)
+		weeks := floorDiv(daysSinceEpoch(y, m, d)-4, 7)
+		next := (floorDiv(weeks, n) + 1) * n
+		return time.Date(1970, 1, 5+int(next*7), 0, 0, 0, 0, loc)
+
+	case interval%int64(DAY) == 0:
+		n := interval / int64(DAY)
+		next := (floorDiv(daysSinceEpoch(y, m, d), n) + 1) * n
+		return time.Date(1970, 1, 1+int(next), 0, 0, 0, 0, loc)
+
+	case int64(DAY)%interval == 0:
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		nextDay := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		elapsed := int64(t.Sub(midnight) / time.Second)
+		next := midnight.Add(time.Duration((elapsed/interval+1)*interval) * time.Second)
+		if !next.Before(nextDay) {
+			return nextDay
+		}
+		return next
+
+	default:
+		_, offset := t.Zone()
+		local := t.Unix() + int64(offset)
+		next := (floorDiv(local, interval)+1)*interval - int64(offset)
+		return time.Unix(next, 0).In(loc)
+	}
+}
+
+func (s *intervalSchedule) String() string {
+	return fmt.Sprintf("every %ds", s.interval)
+}
+
+// cronSchedule rotates at times matching a cron expression of 5 fields:
+//	minute hour day-of-month month day-of-week
+// Each field can be '*', a number, a range 'a-b', a step '*/n' or 'a-b/n',
+// or a list of them separated by ','. Day-of-week is 0-6 from Sunday, and 7
+// is Sunday too. As the standard cron, if both day-of-month and day-of-week
+// are restricted, a day matching either of them matches.
+// e.g. '0 3 * * *' rotates at 03:00 every day.
+type cronSchedule struct {
+	expr    string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+type _CronField struct {
+	min int
+	max int
+}
+
+var cronFields = []_CronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week
+}
+
+func NewCronSchedule(expr string) (RotateSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, goutils.NewErr("cron expression must have 5 fields, expr: %s", expr)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, goutils.WrapErrorf(err, "invalid cron field, field: %s, expr: %s", field, expr)
+		}
+		bits[i] = b
+	}
+
+	// 7 is Sunday as well
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	s := &cronSchedule{
+		expr:    expr,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+
+	return s, nil
+}
+
+func parseCronField(field string, bounds _CronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeStr, step := part, 1
+		if idx := strings.IndexByte(part, '/'); idx != -1 {
+			var err error
+			if step, err = strconv.Atoi(part[idx+1:]); err != nil || step <= 0 {
+				return 0, goutils.NewErr("invalid step: %s", part)
+			}
+			rangeStr = part[:idx]
+		}
+
+		start, end := bounds.min, bounds.max
+		if rangeStr != "*" {
+			nums := strings.SplitN(rangeStr, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(nums[0]); err != nil {
+				return 0, goutils.NewErr("invalid number: %s", part)
+			}
+			end = start
+			if len(nums) == 2 {
+				if end, err = strconv.Atoi(nums[1]); err != nil {
+					return 0, goutils.NewErr("invalid number: %s", part)
+				}
+			} else if step != 1 {
+				// 'a/n' means from 'a' to the max
+				end = bounds.max
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, goutils.NewErr("out of range [%d, %d]: %s", bounds.min, bounds.max, part)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// Start from the next minute
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	yearLimit := t.Year() + _MAX_CRON_YEARS
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+func (s *cronSchedule) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "cron '%s'", s.expr)
+
+	return string(b.Bytes())
+}
+
+// LoadLocation returns the location by name. Empty name and 'Local' mean
+// the local timezone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || strings.ToLower(name) == "local" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to load timezone, name: %s", name)
+	}
+
+	return loc, nil
+}
+
+func daysSinceEpoch(y int, m time.Month, d int) int64 {
+	return floorDiv(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix(), int64(DAY))
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
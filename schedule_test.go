@@ -0,0 +1,92 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 15:30:16
+ */
+
+package gologging
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIntervalSchedule(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	// Saturday
+	now := time.Date(2026, 10, 17, 23, 40, 5, 0, loc)
+
+	cases := []struct {
+		interval RotateInterval
+		expected time.Time
+	}{
+		{HALF_HOUR, time.Date(2026, 10, 18, 0, 0, 0, 0, loc)},
+		{6 * HOUR, time.Date(2026, 10, 18, 0, 0, 0, 0, loc)},
+		{DAY, time.Date(2026, 10, 18, 0, 0, 0, 0, loc)},
+		{WEEK, time.Date(2026, 10, 19, 0, 0, 0, 0, loc)},
+		{MONTH, time.Date(2026, 11, 1, 0, 0, 0, 0, loc)},
+		{3 * MONTH, time.Date(2027, 1, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		next := NewIntervalSchedule(c.interval).Next(now)
+		if !next.Equal(c.expected) {
+			t.Errorf("unexpected next rotation, interval: %d, expected: %s, got: %s",
+				c.interval, c.expected, next)
+		}
+	}
+}
+
+// Intervals which are 0 or overflow are rejected instead of dividing by 0.
+func TestInvalidInterval(t *testing.T) {
+	if _, err := NewTimeRotateFileHandler(filepath.Join(t.TempDir(), "app.log"), 0, 2); err == nil {
+		t.Fatalf("interval 0 is accepted")
+	}
+
+	for _, interval := range []string{"0day", "0min", "100000day"} {
+		conf, err := parseJSONConf([]byte(`{"loggers": {"app": {"handlers": ["h"]}},
+			"handlers": {"h": {"interval": "` + interval + `"}}}`))
+		if err != nil {
+			t.Fatalf("failed to parse JSON, err: %v", err)
+		}
+		conf.Section("h")
+
+		if _, err := parseInterval(conf); err == nil {
+			t.Errorf("invalid interval is parsed: %s", interval)
+		}
+	}
+}
+
+func TestCronSchedule(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2026, 10, 18, 3, 0, 0, 0, loc)
+
+	cases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"0 3 * * *", time.Date(2026, 10, 19, 3, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2026, 10, 18, 3, 15, 0, 0, loc)},
+		{"30 1 1 * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc)},
+		{"0 0 * * 1-5", time.Date(2026, 10, 19, 0, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		schedule, err := NewCronSchedule(c.expr)
+		if err != nil {
+			t.Fatalf("failed to parse cron, expr: %s, err: %s", c.expr, err.Error())
+		}
+
+		if next := schedule.Next(now); !next.Equal(c.expected) {
+			t.Errorf("unexpected next rotation, expr: %s, expected: %s, got: %s",
+				c.expr, c.expected, next)
+		}
+	}
+
+	for _, expr := range []string{"0 3 * *", "60 * * * *", "0 3 * * mon", "*/0 * * * *"} {
+		if _, err := NewCronSchedule(expr); err == nil {
+			t.Errorf("cron expression should be rejected, expr: %s", expr)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+/**
+ * Statistics of handlers and the loops running them, which are exposed by
+ * Stats, expvar and the Prometheus text format, see metrics.go.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 15:20:13
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Upper bounds of the buckets of the latency histogram of handling logs,
+// the last bucket is unbounded.
+var latencyBounds = [...]time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// HandlerStats is a snapshot of the statistics of a handler.
+type HandlerStats struct {
+	// Number of logs written by level, indexed by Level.
+	Records [_MAX_LEVEL]uint64
+	// Bytes of the formatted logs written.
+	Bytes       uint64
+	WriteErrors uint64
+	Rotations   uint64
+	// Number of logs whose message is truncated by the limits, see
+	// SetMessageLimit.
+	Truncated uint64
+}
+
+// Counters of a handler, which are updated atomically.
+type handlerCounters struct {
+	records     [_MAX_LEVEL]uint64
+	bytes       uint64
+	writeErrors uint64
+	rotations   uint64
+	truncated   uint64
+}
+
+// countWrite counts a log written by 'n' bytes.
+func (counters *handlerCounters) countWrite(level Level, n int, err error) {
+	if err != nil {
+		atomic.AddUint64(&counters.writeErrors, 1)
+	}
+	if n > 0 {
+		atomic.AddUint64(&counters.records[level], 1)
+		atomic.AddUint64(&counters.bytes, uint64(n))
+	}
+}
+
+// Handlers which report their statistics.
+type statsReporter interface {
+	Stats() HandlerStats
+}
+
+// Stats returns the statistics of the handler, it's safe to be called when
+// logs are being handled.
+func (handler *StreamHandler) Stats() HandlerStats {
+	counters := &handler.counters
+	stats := HandlerStats{
+		Bytes:       atomic.LoadUint64(&counters.bytes),
+		WriteErrors: atomic.LoadUint64(&counters.writeErrors),
+		Rotations:   atomic.LoadUint64(&counters.rotations),
+		Truncated:   atomic.LoadUint64(&counters.truncated),
+	}
+	for i := range counters.records {
+		stats.Records[i] = atomic.LoadUint64(&counters.records[i])
+	}
+
+	return stats
+}
+
+func (stats HandlerStats) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprint(&b, "HandlerStats{records: {")
+	for level, n := range stats.Records {
+		if level > 0 {
+			fmt.Fprint(&b, ", ")
+		}
+		fmt.Fprintf(&b, "%s: %d", Level(level).Name(), n)
+	}
+	fmt.Fprintf(&b, "}, bytes: %d, writeErrors: %d, rotations: %d, truncated: %d}",
+		stats.Bytes, stats.WriteErrors, stats.Rotations, stats.Truncated)
+
+	return string(b.Bytes())
+}
+
+// Histogram is a snapshot of a histogram of durations.
+type Histogram struct {
+	// Upper bounds of the buckets, the last bucket is unbounded.
+	Bounds []time.Duration
+	// Number of values in each bucket, which isn't cumulative. Its length
+	// is len(Bounds)+1.
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// Buckets of a histogram, which are updated atomically.
+type histogram struct {
+	counts [len(latencyBounds) + 1]uint64
+	count  uint64
+	sum    uint64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	idx := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+	atomic.AddUint64(&h.counts[idx], 1)
+	atomic.AddUint64(&h.sum, uint64(d))
+	atomic.AddUint64(&h.count, 1)
+}
+
+func (h *histogram) snapshot() Histogram {
+	snapshot := Histogram{
+		Bounds: latencyBounds[:],
+		Counts: make([]uint64, len(h.counts)),
+		Count:  atomic.LoadUint64(&h.count),
+		Sum:    time.Duration(atomic.LoadUint64(&h.sum)),
+	}
+	for i := range h.counts {
+		snapshot.Counts[i] = atomic.LoadUint64(&h.counts[i])
+	}
+
+	return snapshot
+}
+
+// Counters of a handler loop, which are updated atomically.
+type loopCounters struct {
+	handled      uint64
+	handleErrors uint64
+	dropped      uint64
+	// Consecutive failures of handling logs.
+	failures  uint64
+	highWater int64
+	latency   histogram
+}
+
+// updateHighWater records the depth of the queue if it's the highest.
+func (counters *loopCounters) updateHighWater(depth int) {
+	for {
+		highWater := atomic.LoadInt64(&counters.highWater)
+		if int64(depth) <= highWater || atomic.CompareAndSwapInt64(&counters.highWater, highWater, int64(depth)) {
+			return
+		}
+	}
+}
+
+// LoopStats is a snapshot of the statistics of a handler of a logger,
+// including the queue of the logs emitted to it.
+type LoopStats struct {
+	Logger string
+	// Name of the handler, which is the section name if it's loaded from
+	// a config, or 'handler-N' by the order added to the logger.
+	Handler string
+	// Number of logs in the queue, its capacity, and the highest number
+	// ever reached.
+	QueueDepth     int
+	QueueCapacity  int
+	QueueHighWater int
+	Handled        uint64
+	// Number of logs whose Handle returns an error.
+	HandleErrors uint64
+	// Number of logs dropped, as they are emitted after the logger is
+	// closed, or the handler is disabled.
+	Dropped uint64
+	Health  HandlerHealth
+	// Number of the latest logs failed to be handled in a row, and the
+	// last error, which is kept after the handler recovers.
+	ConsecutiveFailures uint64
+	LastError           string
+	LastErrorTime       time.Time
+	// Duration of Handle of each log.
+	Latency Histogram
+	// Statistics of the handler, zero if the handler doesn't report them.
+	HandlerStats
+}
+
+func (loop *handlerLoop) stats(loggerName string) LoopStats {
+	counters := &loop.counters
+	stats := LoopStats{
+		Logger:              loggerName,
+		Handler:             loop.name,
+		QueueDepth:          len(loop.q),
+		QueueCapacity:       cap(loop.q),
+		QueueHighWater:      int(atomic.LoadInt64(&counters.highWater)),
+		Handled:             atomic.LoadUint64(&counters.handled),
+		HandleErrors:        atomic.LoadUint64(&counters.handleErrors),
+		Dropped:             atomic.LoadUint64(&counters.dropped),
+		Latency:             counters.latency.snapshot(),
+		Health:              loop.healthStatus(),
+		ConsecutiveFailures: atomic.LoadUint64(&counters.failures),
+	}
+	loop.health.mu.Lock()
+	if loop.health.lastErr != nil {
+		stats.LastError, stats.LastErrorTime = loop.health.lastErr.Error(), loop.health.errTime
+	}
+	loop.health.mu.Unlock()
+	if reporter, ok := loop.handler.(statsReporter); ok {
+		stats.HandlerStats = reporter.Stats()
+	}
+
+	return stats
+}
+
+func (stats LoopStats) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "LoopStats{logger: %s, handler: %s, queue: %d/%d, highWater: %d, handled: %d, "+
+		"handleErrors: %d, dropped: %d, health: %s, failures: %d, %s}", stats.Logger, stats.Handler,
+		stats.QueueDepth, stats.QueueCapacity, stats.QueueHighWater, stats.Handled, stats.HandleErrors,
+		stats.Dropped, stats.Health.Name(), stats.ConsecutiveFailures, stats.HandlerStats)
+
+	return string(b.Bytes())
+}
+
+// Stats returns the statistics of the handlers of the logger in order.
+func (logger *Logger) Stats() []LoopStats {
+	stats := make([]LoopStats, 0, len(logger.handlers))
+	for _, loop := range logger.handlers {
+		stats = append(stats, loop.stats(logger.name))
+	}
+
+	return stats
+}
+
+// Stats returns the statistics of the handlers of all the loggers, sorted by
+// the logger names.
+func Stats() []LoopStats {
+	loggerMgr.mu.Lock()
+	loggers := make([]*Logger, 0, len(loggerMgr.logCache)+1)
+	for _, logger := range loggerMgr.logCache {
+		loggers = append(loggers, logger)
+	}
+	loggers = append(loggers, loggerMgr.rootLogger)
+	loggerMgr.mu.Unlock()
+
+	sort.SliceStable(loggers, func(i, j int) bool { return loggers[i].name < loggers[j].name })
+
+	var stats []LoopStats
+	for _, logger := range loggers {
+		stats = append(stats, logger.Stats()...)
+	}
+
+	return stats
+}
+
+// PublishExpvar publishes the result of Stats as the expvar variable 'name',
+// e.g. 'gologging', which is served by '/debug/vars'. Like expvar.Publish,
+// it panics if the name is already published.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return Stats()
+	}))
+}
@@ -0,0 +1,97 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 16:40:22
+ */
+
+package gologging
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	logger, out := newBufferLogger("${levelname} ${message}")
+	logger.SetLevel(DEBUG)
+	logger.Debug("below the level of the handler")
+	logger.Info("one")
+	logger.Error("two")
+
+	stats := logger.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+	s := stats[0]
+	if s.Logger != "test" || s.Handler != "handler-0" || s.Handled != 3 || s.Records[INFO] != 1 ||
+		s.Records[ERROR] != 1 || s.Records[DEBUG] != 0 || s.Bytes != uint64(out.Len()) ||
+		s.QueueCapacity != _DEFAULT_CHAN_SIZE || s.QueueHighWater < 1 || s.Latency.Count != 3 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+
+	logger.Close()
+	logger.Info("dropped")
+	if s := logger.Stats()[0]; s.Dropped != 1 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+
+	// Rotations of file handlers
+	handler, err := NewSizeRotateFileHandler(filepath.Join(t.TempDir(), "app.log"), 16, 2)
+	if err != nil {
+		t.Fatalf("failed to create handler, err: %v", err)
+	}
+	defer handler.Close()
+	formatter, _ := NewFormatter("${message}")
+	handler.SetFormatter(formatter)
+	for i := 0; i < 3; i++ {
+		handler.Handle(&_Msg{level: INFO, message: []byte("0123456789")})
+	}
+	if s := handler.Stats(); s.Rotations != 2 || s.Records[INFO] != 3 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	logger, _ := newBufferLogger("${message}")
+	logger.AddNamedHandler("handler-\"x\"", NewStreamHandle(&strings.Builder{}))
+	logger.Warn("one")
+	logger.Close()
+
+	var b strings.Builder
+	if err := WriteMetrics(&b, logger.Stats()); err != nil {
+		t.Fatalf("failed to write metrics, err: %v", err)
+	}
+	metrics := b.String()
+	for _, line := range []string{
+		"# TYPE gologging_records_total counter",
+		`gologging_records_total{logger="test",handler="handler-0",level="WARN"} 1`,
+		`gologging_written_bytes_total{logger="test",handler="handler-0"} 4`,
+		`gologging_handled_total{logger="test",handler="handler-\"x\""} 1`,
+		`gologging_queue_capacity{logger="test",handler="handler-0"} 100`,
+		"# TYPE gologging_handle_duration_seconds histogram",
+		`gologging_handle_duration_seconds_bucket{logger="test",handler="handler-0",le="+Inf"} 1`,
+		`gologging_handle_duration_seconds_count{logger="test",handler="handler-0"} 1`,
+	} {
+		if !strings.Contains(metrics, line+"\n") {
+			t.Fatalf("no line in metrics: %s\n%s", line, metrics)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Header().Get("Content-Type") != _METRICS_CONTENT_TYPE ||
+		!strings.Contains(rec.Body.String(), `gologging_handled_total{logger="root",handler="handler-0"}`) {
+		t.Fatalf("unexpected response: %s", rec.Body.String())
+	}
+
+	PublishExpvar("gologging-test")
+	var published []LoopStats
+	if err := json.Unmarshal([]byte(expvar.Get("gologging-test").String()), &published); err != nil ||
+		len(published) == 0 {
+		t.Fatalf("unexpected expvar, err: %v", err)
+	}
+}
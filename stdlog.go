@@ -0,0 +1,224 @@
+/**
+ * Adapters making gologging the backend of the standard log package and
+ * of libraries writing logs to an io.Writer.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 21:05:33
+ */
+
+package gologging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// A partial line longer than this is emitted without waiting for the
+	// newline.
+	_MAX_PARTIAL_LINE = 64 * 1024
+	// Max number of frames of the log and fmt packages between the caller
+	// and StdWriter.Write.
+	_MAX_WRITER_FRAMES = 8
+)
+
+// Packages whose frames are skipped to find the caller of a log written to
+// a StdWriter.
+var writerPkgPrefixes = []string{"log.", "fmt."}
+
+// Aliases of level names in the prefixes detected by StdWriter.
+var levelAliases = map[string]Level{
+	"WARNING": WARN,
+	"ERR":     ERROR,
+	"CRIT":    FATAL,
+}
+
+// StdWriter is an io.Writer which emits each line written to it as a log of
+// the logger. The caller info of a log is the function calling the log or
+// fmt package, or calling Write directly.
+//
+// Logs are handled asynchronously, so logs written by log.Fatal and
+// log.Panic may be lost as the process exits before they are handled.
+type StdWriter struct {
+	logger *Logger
+	level  Level
+	// Whether to detect the level by the prefix of a line, such as '[ERROR]'
+	// or 'ERROR:'.
+	detectLevel bool
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// NewStdWriter returns a writer emitting logs of 'level' to 'logger', the
+// level detection is enabled.
+func NewStdWriter(logger *Logger, level Level) *StdWriter {
+	if !checkLevel(level) {
+		panic(errors.New("not support level"))
+	}
+
+	return &StdWriter{logger: logger, level: level, detectLevel: true}
+}
+
+// SetDetectLevel enables or disables the level detection. When enabled, a
+// line starting with a level name in the form of '[ERROR] msg' or
+// 'ERROR: msg', case-insensitive, is logged at the level without the prefix.
+func (w *StdWriter) SetDetectLevel(detect bool) {
+	w.mu.Lock()
+	w.detectLevel = detect
+	w.mu.Unlock()
+}
+
+// Write emits each complete line in 'p' as a log. A partial line is kept
+// until its newline is written, or Flush is called.
+func (w *StdWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	skip := w.callerSkip()
+	data := p
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, _NEWLINE)
+		if idx == -1 {
+			w.partial = append(w.partial, data...)
+			if len(w.partial) >= _MAX_PARTIAL_LINE {
+				w.emit(w.partial, skip)
+				w.partial = w.partial[:0]
+			}
+			break
+		}
+
+		if len(w.partial) != 0 {
+			w.partial = append(w.partial, data[:idx]...)
+			w.emit(w.partial, skip)
+			w.partial = w.partial[:0]
+		} else {
+			w.emit(data[:idx], skip)
+		}
+		data = data[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush emits the partial line which hasn't ended by a newline.
+func (w *StdWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) != 0 {
+		w.emit(w.partial, 0)
+		w.partial = w.partial[:0]
+	}
+}
+
+// callerSkip returns the number of frames of the log and fmt packages
+// between Write and its caller.
+func (w *StdWriter) callerSkip() int {
+	var pcs [_MAX_WRITER_FRAMES]uintptr
+	// Skip runtime.Callers, callerSkip and Write
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	skip := 0
+	for {
+		frame, more := frames.Next()
+		if !isWriterPkg(frame.Function) {
+			break
+		}
+		skip++
+		if !more {
+			break
+		}
+	}
+
+	return skip
+}
+
+func isWriterPkg(funcName string) bool {
+	for _, prefix := range writerPkgPrefixes {
+		if strings.HasPrefix(funcName, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emit logs a line, 'skip' is the number of frames to skip above Write.
+// Empty lines are ignored.
+func (w *StdWriter) emit(line []byte, skip int) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	level := w.level
+	if w.detectLevel {
+		level, line = detectLevel(line, level)
+	}
+
+	// The frame of Write (or Flush) is between emit and the caller
+	w.logger.WithCallerSkip(skip+1).log(nil, level, "%s", line)
+}
+
+// detectLevel returns the level named by the prefix of the line and the
+// line without the prefix, or 'level' and the line if there's no prefix.
+func detectLevel(line []byte, level Level) (Level, []byte) {
+	s := bytes.TrimLeft(line, " \t")
+
+	var name, rest []byte
+	if len(s) != 0 && s[0] == '[' {
+		idx := bytes.IndexByte(s, ']')
+		if idx == -1 {
+			return level, line
+		}
+		name, rest = s[1:idx], s[idx+1:]
+	} else {
+		idx := bytes.IndexByte(s, ':')
+		if idx == -1 {
+			return level, line
+		}
+		name, rest = s[:idx], s[idx+1:]
+	}
+
+	upper := strings.ToUpper(string(name))
+	detected, ok := levelAliases[upper]
+	if !ok {
+		if detected = NewLevelString(upper); !checkLevel(detected) {
+			return level, line
+		}
+	}
+
+	return detected, bytes.TrimLeft(rest, " \t")
+}
+
+// NewStdLogger returns a standard log.Logger writing logs of 'level' to
+// 'logger', which can be passed to libraries accepting a *log.Logger. The
+// level is detected by the prefix of each log, see StdWriter.SetDetectLevel.
+func NewStdLogger(logger *Logger, level Level) *log.Logger {
+	// Date and time are added by gologging
+	return log.New(NewStdWriter(logger, level), "", 0)
+}
+
+// RedirectStdLog makes the logs of the standard log package written to the
+// logger named 'loggerName' at INFO, with the level detection enabled. The
+// flags and the prefix of the standard logger are cleared, as they're added
+// by the formatter. It returns a function restoring the standard logger.
+func RedirectStdLog(loggerName string) func() {
+	out, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	log.SetOutput(NewStdWriter(GetLogger(loggerName), INFO))
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
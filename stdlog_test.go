@@ -0,0 +1,67 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 21:31:50
+ */
+
+package gologging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	logger, out := newBufferLogger("${levelname} ${filename}:${funcname} ${message}")
+	defer logger.Close()
+
+	stdLogger := NewStdLogger(logger, WARN)
+	stdLogger.Printf("plain %d", 1)
+	stdLogger.Println("[error] detected")
+	stdLogger.Print("info: lower case\nsecond line")
+
+	expected := []string{
+		"WARN stdlog_test.go:gologging.TestStdLogger plain 1",
+		"ERROR stdlog_test.go:gologging.TestStdLogger detected",
+		"INFO stdlog_test.go:gologging.TestStdLogger lower case",
+		"WARN stdlog_test.go:gologging.TestStdLogger second line",
+	}
+	if lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Fatalf("unexpected logs, expected: %q, got: %q", expected, lines)
+	}
+}
+
+func TestStdWriter(t *testing.T) {
+	logger, out := newBufferLogger("${levelname} ${filename} ${message}")
+	defer logger.Close()
+
+	w := NewStdWriter(logger, INFO)
+	w.SetDetectLevel(false)
+	fmt.Fprint(w, "[ERROR] first ")
+	fmt.Fprint(w, "part\r\n\n")
+	fmt.Fprint(w, "partial")
+	if s := out.String(); s != "INFO stdlog_test.go [ERROR] first part\n" {
+		t.Fatalf("unexpected logs: %q", s)
+	}
+
+	w.Flush()
+	if s := out.String(); !strings.HasSuffix(s, "INFO stdlog_test.go partial\n") {
+		t.Fatalf("partial line isn't flushed: %q", s)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	logger, out := newBufferLogger("${levelname} ${filename} ${message}")
+	defer logger.Close()
+	loggerMgr.AddOrUpdateLogger("std", logger)
+
+	restore := RedirectStdLog("std")
+	log.Printf("WARNING: redirected")
+	restore()
+
+	if s := out.String(); s != "WARN stdlog_test.go redirected\n" {
+		t.Fatalf("unexpected logs: %q", s)
+	}
+}
@@ -0,0 +1,907 @@
+/**
+ * Validation of config files without side effects.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 23:04:51
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/chosen0ne/goutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+type Severity int
+
+const (
+	// Load fails or the logger doesn't work as configured.
+	SEVERITY_ERROR Severity = iota
+	// The config is ignored by Load, e.g. an unknown key.
+	SEVERITY_WARNING
+)
+
+var severityNames = []string{
+	"error",
+	"warning",
+}
+
+func (severity Severity) Name() string {
+	if severity >= SEVERITY_ERROR && int(severity) < len(severityNames) {
+		return severityNames[severity]
+	}
+
+	return "UNKNOWN_SEVERITY"
+}
+
+// Diagnostic is a problem of a config file found by Validate.
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	// Line of the key or the section, 0 if it's unknown.
+	Line int
+	// Section of the problem, empty for the global items. Loggers are in
+	// the sections named 'logger-${name}' in all the formats.
+	Section string
+	// Key of the problem, empty if it's about the section.
+	Key     string
+	Message string
+}
+
+func (diag Diagnostic) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "%s", diag.File)
+	if diag.Line != 0 {
+		fmt.Fprintf(&b, ":%d", diag.Line)
+	}
+	fmt.Fprintf(&b, ": %s: ", diag.Severity.Name())
+	if diag.Section != "" {
+		fmt.Fprintf(&b, "[%s] ", diag.Section)
+	}
+	if diag.Key != "" {
+		fmt.Fprintf(&b, "%s: ", diag.Key)
+	}
+	b.WriteString(diag.Message)
+
+	return string(b.Bytes())
+}
+
+// HasErrors returns whether any diagnostic is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, diag := range diags {
+		if diag.Severity == SEVERITY_ERROR {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A check of the value of an item, the section of the item is the current
+// section of 'conf'.
+type _ItemCheck func(conf confSource, label string) error
+
+type _ItemRule struct {
+	// Handler types which the item takes effect on, nil for all.
+	types []HandlerType
+	check _ItemCheck
+	// Severity of a failed check, e.g. a hook may be registered after the
+	// config is validated.
+	severity Severity
+}
+
+var (
+	fileHandlerTypes = []HandlerType{TIME_ROTATE_HANDLER, SIZE_ROTATE_HANDLER}
+	timeHandlerTypes = []HandlerType{TIME_ROTATE_HANDLER}
+	sizeHandlerTypes = []HandlerType{SIZE_ROTATE_HANDLER}
+
+	globalItems    map[string]_ItemCheck
+	loggerItems    map[string]_ItemCheck
+	handlerItems   map[string]_ItemRule
+	formatterItems map[string]_ItemCheck
+)
+
+// Validate checks the config file as Load without creating any logger, file
+// or goroutine, and reports all the problems found. The format is detected
+// as Load. Placeholders are interpolated as Load, so env vars must be set as
+// they are when the config is loaded. Post rotate hooks are reported as
+// warnings if they aren't registered when Validate is called.
+func Validate(configPath string) []Diagnostic {
+	v := &validator{
+		path:       configPath,
+		visited:    make(map[string]bool),
+		validated:  make(map[string]bool),
+		reported:   make(map[string]bool),
+		formatters: make(map[string]bool),
+	}
+
+	conf, err := parseConfFile(configPath)
+	if err != nil {
+		v.report(SEVERITY_ERROR, "", "", "%s", err.Error())
+		return v.diags
+	}
+	v.conf = conf
+	v.interp = &interpConf{confSource: conf}
+	v.validate()
+
+	return v.diags
+}
+
+// parseConfFile parses the config file into sections by the format detected
+// as Load.
+func parseConfFile(configPath string) (*sectionConf, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to read conf, conf: %s", configPath)
+	}
+
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return parseYAMLConf(data)
+	case ".json":
+		return parseJSONConf(data)
+	}
+
+	return parseGoconf(data)
+}
+
+// parseGoconf parses the format of goconf, which consists of '[section]'
+// lines and 'key: value' lines. Lines starting with '#' are comments.
+func parseGoconf(data []byte) (*sectionConf, error) {
+	conf := newSectionConf()
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, goutils.NewErr("section isn't closed by ']', line: %d", lineNo)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if err := conf.addSection(section, lineNo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		idx := strings.IndexByte(line, ':')
+		if idx <= 0 {
+			return nil, goutils.NewErr("line isn't in the form of 'key: value', line: %d", lineNo)
+		}
+		conf.setItem(section, strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), lineNo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to read conf")
+	}
+
+	return conf, nil
+}
+
+type validator struct {
+	path   string
+	conf   *sectionConf
+	interp *interpConf
+	diags  []Diagnostic
+	// Sections referenced by loggers.
+	visited map[string]bool
+	// Sections whose items have been checked.
+	validated map[string]bool
+	// Diagnostics reported, a handler may be checked for multiple loggers.
+	reported   map[string]bool
+	formatters map[string]bool
+	// Whether a fallback handler is being checked, which can't fall back
+	// again.
+	inFallback bool
+}
+
+func (v *validator) report(severity Severity, section, key, format string, vals ...interface{}) {
+	diag := Diagnostic{
+		Severity: severity,
+		File:     v.path,
+		Section:  section,
+		Key:      key,
+		Message:  fmt.Sprintf(format, vals...),
+	}
+	if v.conf != nil {
+		diag.Line = v.conf.line(section, key)
+	}
+
+	if s := diag.String(); !v.reported[s] {
+		v.reported[s] = true
+		v.diags = append(v.diags, diag)
+	}
+}
+
+func (v *validator) validate() {
+	v.checkItems("", globalItems)
+
+	if !v.conf.HasSection("") || !v.hasItem("", _LOGGERS_LABEL) {
+		v.report(SEVERITY_ERROR, "", _LOGGERS_LABEL, "no loggers configured")
+	} else if loggerNames, err := v.getStringArray("", _LOGGERS_LABEL); err != nil {
+		v.report(SEVERITY_ERROR, "", _LOGGERS_LABEL, "%s", err.Error())
+	} else {
+		for _, loggerName := range loggerNames {
+			v.validateLogger(loggerName)
+		}
+	}
+
+	for _, name := range v.conf.names {
+		if !v.visited[name] {
+			v.report(SEVERITY_WARNING, name, "", "section isn't referenced by any logger")
+		}
+	}
+}
+
+func (v *validator) validateLogger(sectionName string) {
+	if !isValidLogger(sectionName) {
+		v.report(SEVERITY_ERROR, "", _LOGGERS_LABEL, "invalid section name for logger '%s', "+
+			"it must start with 'logger-'", sectionName)
+		return
+	}
+	if !v.conf.HasSection(sectionName) {
+		v.report(SEVERITY_ERROR, "", _LOGGERS_LABEL, "no section named '%s'", sectionName)
+		return
+	}
+
+	name := strings.SplitN(sectionName, "-", 2)[1]
+	v.visited[sectionName] = true
+	v.interp.loggerName = name
+	v.checkItems(sectionName, loggerItems)
+
+	if _, _, err := envLevel(name); err != nil {
+		v.report(SEVERITY_ERROR, sectionName, _LEVEL_LABEL, "%s", err.Error())
+	}
+
+	if !v.hasItem(sectionName, _HANDLERS_LABEL) {
+		v.report(SEVERITY_ERROR, sectionName, "", "logger has no handlers")
+		return
+	}
+
+	handlerNames, err := v.getStringArray(sectionName, _HANDLERS_LABEL)
+	if err != nil {
+		v.report(SEVERITY_ERROR, sectionName, _HANDLERS_LABEL, "%s", err.Error())
+		return
+	}
+	if len(handlerNames) == 0 {
+		v.report(SEVERITY_ERROR, sectionName, _HANDLERS_LABEL, "logger has no handlers")
+	}
+
+	for _, handlerName := range handlerNames {
+		if !v.conf.HasSection(handlerName) {
+			v.report(SEVERITY_ERROR, sectionName, _HANDLERS_LABEL, "no section named '%s'", handlerName)
+			continue
+		}
+		v.validateHandler(handlerName)
+	}
+}
+
+// validateHandler checks a handler with the configs it extends, which are
+// checked by the type of the handler.
+func (v *validator) validateHandler(handlerName string) {
+	chain := v.extendsChain(handlerName)
+	for _, section := range chain {
+		v.visited[section] = true
+	}
+
+	// The items of a handler override the items of the configs it extends
+	owners := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, key := range v.conf.infos[chain[i]].keys {
+			owners[key] = chain[i]
+		}
+	}
+
+	typeSection, ok := owners[_TYPE_LABEL]
+	if !ok {
+		v.report(SEVERITY_ERROR, handlerName, "", "no type of handler, set 'type' or 'extends'")
+		return
+	}
+	typeStr, err := v.getString(typeSection, _TYPE_LABEL)
+	if err != nil {
+		return
+	}
+	ht, ok := handlerTypes[typeStr]
+	if !ok {
+		v.report(SEVERITY_ERROR, typeSection, _TYPE_LABEL, "unknown handler type: %s", typeStr)
+		return
+	}
+
+	for _, section := range chain {
+		if !v.validated[section] {
+			v.validated[section] = true
+			v.checkHandlerItems(section)
+		}
+
+		for _, key := range v.conf.infos[section].keys {
+			rule, ok := handlerItems[key]
+			if ok && owners[key] == section && !handlerTypeIn(ht, rule.types) {
+				v.report(SEVERITY_WARNING, section, key, "it takes no effect on handler '%s' of type '%s'",
+					handlerName, typeStr)
+			}
+		}
+	}
+
+	if fmtSection, ok := owners[_FORMMATER_LABEL]; ok {
+		if fmtName, err := v.getString(fmtSection, _FORMMATER_LABEL); err == nil {
+			v.validateFormatter(fmtSection, fmtName)
+		}
+	}
+
+	if handlerTypeIn(ht, fileHandlerTypes) {
+		v.validateFile(handlerName, owners)
+	}
+
+	v.validateFailure(handlerName, owners)
+}
+
+// validateFailure checks the items of the failure policy depending on each
+// other, and the fallback handler.
+func (v *validator) validateFailure(handlerName string, owners map[string]string) {
+	policy := FAILURE_IGNORE
+	if section, ok := owners[_ON_FAILURE_LABEL]; ok {
+		policyStr, err := v.getString(section, _ON_FAILURE_LABEL)
+		if err != nil {
+			return
+		}
+		if policy, err = NewFailurePolicyString(policyStr); err != nil {
+			// Reported by the check of the item
+			return
+		}
+	}
+
+	for _, item := range []struct {
+		key    string
+		policy FailurePolicy
+	}{
+		{_RETRIES_LABEL, FAILURE_RETRY},
+		{_RETRY_BACK_LABEL, FAILURE_RETRY},
+		{_DISABLE_AFT_LABEL, FAILURE_DISABLE},
+		{_FALLBACK_LABEL, FAILURE_FALLBACK},
+	} {
+		if section, ok := owners[item.key]; ok && item.policy != policy {
+			v.report(SEVERITY_WARNING, section, item.key, "it takes no effect on handler '%s' with '%s: %s'",
+				handlerName, _ON_FAILURE_LABEL, policy.Name())
+		}
+	}
+
+	fbSection, ok := owners[_FALLBACK_LABEL]
+	if !ok {
+		if policy == FAILURE_FALLBACK {
+			v.report(SEVERITY_ERROR, handlerName, _ON_FAILURE_LABEL, "no fallback handler, set '%s'",
+				_FALLBACK_LABEL)
+		}
+		return
+	}
+
+	fallbackName, err := v.getString(fbSection, _FALLBACK_LABEL)
+	if err != nil {
+		return
+	}
+	if v.inFallback || fallbackName == handlerName {
+		v.report(SEVERITY_ERROR, fbSection, _FALLBACK_LABEL, "fallback handler can't have a fallback")
+		return
+	}
+	if !v.conf.HasSection(fallbackName) {
+		v.report(SEVERITY_ERROR, fbSection, _FALLBACK_LABEL, "no section named '%s'", fallbackName)
+		return
+	}
+
+	v.inFallback = true
+	v.validateHandler(fallbackName)
+	v.inFallback = false
+}
+
+// extendsChain returns the handler and the configs it extends in order.
+// Missing configs and cycles are reported.
+func (v *validator) extendsChain(handlerName string) []string {
+	chain := []string{handlerName}
+	for cur := handlerName; v.hasItem(cur, _HANDLER_EXTEND); {
+		extName, err := v.getString(cur, _HANDLER_EXTEND)
+		if err != nil {
+			break
+		}
+
+		for _, section := range chain {
+			if section == extName {
+				v.report(SEVERITY_ERROR, cur, _HANDLER_EXTEND, "cycle of extends: %s -> %s",
+					strings.Join(chain, " -> "), extName)
+				return chain
+			}
+		}
+		if !v.conf.HasSection(extName) {
+			v.report(SEVERITY_ERROR, cur, _HANDLER_EXTEND, "no section named '%s'", extName)
+			break
+		}
+
+		chain = append(chain, extName)
+		cur = extName
+	}
+
+	return chain
+}
+
+func (v *validator) checkHandlerItems(section string) {
+	v.conf.Section(section)
+	for _, key := range v.conf.infos[section].keys {
+		rule, ok := handlerItems[key]
+		if !ok {
+			v.report(SEVERITY_WARNING, section, key, "unknown key")
+			continue
+		}
+		if rule.check == nil {
+			continue
+		}
+
+		if err := rule.check(v.interp, key); err != nil {
+			v.report(rule.severity, section, key, "%s", err.Error())
+		}
+	}
+}
+
+func (v *validator) validateFormatter(section, fmtName string) {
+	if !v.conf.HasSection(fmtName) {
+		v.report(SEVERITY_ERROR, section, _FORMMATER_LABEL, "no formatter named '%s'", fmtName)
+		return
+	}
+
+	v.visited[fmtName] = true
+	if v.formatters[fmtName] {
+		return
+	}
+	v.formatters[fmtName] = true
+	v.checkItems(fmtName, formatterItems)
+
+	if !v.hasItem(fmtName, _FORMAT_LABEL) {
+		v.report(SEVERITY_ERROR, fmtName, "", "formatter has no format")
+	}
+}
+
+// validateFile checks the log file can be created, and the items depending
+// on each other.
+func (v *validator) validateFile(handlerName string, owners map[string]string) {
+	values := make(map[string]string)
+	for _, key := range []string{_LOG_PATH_LABEL, _FILENAME_LABEL, _BACKUP_NAME_LABEL, _AUDIT_LABEL,
+		_MULTI_PROC_LABEL, _KEY_FILE_LABEL, _KEY_ENV_LABEL, _KEY_ID_LABEL} {
+		if section, ok := owners[key]; ok {
+			values[key], _ = v.getString(section, key)
+		}
+	}
+
+	// The file is named by the logger by default
+	defaults := &LoggerConfig{LogPath: values[_LOG_PATH_LABEL], FileName: values[_FILENAME_LABEL]}
+	setDefaultConfig(v.interp.loggerName, defaults)
+
+	fpath, err := getAbsPath(defaults.LogPath, defaults.FileName)
+	if err != nil {
+		v.report(SEVERITY_ERROR, handlerName, "", "%s", err.Error())
+		return
+	}
+
+	pathKey := _FILENAME_LABEL
+	if _, ok := owners[_LOG_PATH_LABEL]; ok {
+		pathKey = _LOG_PATH_LABEL
+	}
+	if err := checkLogPath(fpath); err != nil {
+		v.report(SEVERITY_ERROR, owners[pathKey], pathKey, "%s", err.Error())
+	}
+
+	if pattern := values[_BACKUP_NAME_LABEL]; pattern != "" {
+		if _, err := newBackupNamer(pattern, fpath); err != nil {
+			v.report(SEVERITY_ERROR, owners[_BACKUP_NAME_LABEL], _BACKUP_NAME_LABEL, "%s", err.Error())
+		}
+	}
+
+	audit, _ := parseBool(values[_AUDIT_LABEL])
+	multiProcess, _ := parseBool(values[_MULTI_PROC_LABEL])
+	if audit && multiProcess {
+		v.report(SEVERITY_ERROR, owners[_AUDIT_LABEL], _AUDIT_LABEL, "audit can't be used with multi-process")
+	}
+
+	config := &LoggerConfig{
+		EncryptKeyFile: values[_KEY_FILE_LABEL],
+		EncryptKeyEnv:  values[_KEY_ENV_LABEL],
+		EncryptKeyID:   values[_KEY_ID_LABEL],
+	}
+	if config.EncryptKeyID != "" && config.EncryptKeyFile == "" && config.EncryptKeyEnv == "" {
+		v.report(SEVERITY_WARNING, owners[_KEY_ID_LABEL], _KEY_ID_LABEL, "no encryption key is configured")
+	} else if config.EncryptKeyFile != "" && config.EncryptKeyEnv != "" {
+		v.report(SEVERITY_ERROR, owners[_KEY_ENV_LABEL], _KEY_ENV_LABEL,
+			"only one of key file and key env can be specified")
+	} else if config.EncryptKeyID != "" {
+		if _, err := config.keyRing(); err != nil {
+			v.report(SEVERITY_ERROR, owners[_KEY_ID_LABEL], _KEY_ID_LABEL, "%s", err.Error())
+		}
+	}
+}
+
+// checkItems checks the items of a section by 'checks', keys not in
+// 'checks' are unknown.
+func (v *validator) checkItems(section string, checks map[string]_ItemCheck) {
+	if !v.conf.HasSection(section) {
+		return
+	}
+
+	v.conf.Section(section)
+	for _, key := range v.conf.infos[section].keys {
+		check, ok := checks[key]
+		if !ok {
+			v.report(SEVERITY_WARNING, section, key, "unknown key")
+		} else if check != nil {
+			if err := check(v.interp, key); err != nil {
+				v.report(SEVERITY_ERROR, section, key, "%s", err.Error())
+			}
+		}
+	}
+}
+
+func (v *validator) hasItem(section, key string) bool {
+	v.conf.Section(section)
+	return v.conf.HasItem(key)
+}
+
+// getString returns the interpolated item, an error is reported.
+func (v *validator) getString(section, key string) (string, error) {
+	v.conf.Section(section)
+	val, err := v.interp.GetString(key)
+	if err != nil {
+		v.report(SEVERITY_ERROR, section, key, "%s", err.Error())
+	}
+
+	return val, err
+}
+
+func (v *validator) getStringArray(section, key string) ([]string, error) {
+	v.conf.Section(section)
+	return v.interp.GetStringArray(key)
+}
+
+func handlerTypeIn(ht HandlerType, types []HandlerType) bool {
+	if types == nil {
+		return true
+	}
+
+	for _, t := range types {
+		if t == ht {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkLogPath checks the log file can be opened for appending, or can be
+// created with the missing directories, without creating anything.
+func checkLogPath(fpath string) error {
+	if info, err := os.Stat(fpath); err == nil {
+		if info.IsDir() {
+			return goutils.NewErr("log file is a directory, path: %s", fpath)
+		}
+		if err := checkWritable(fpath); err != nil {
+			return goutils.WrapErrorf(err, "log file isn't writable, path: %s", fpath)
+		}
+		return nil
+	} else if !isNotExist(err) {
+		return goutils.WrapErrorf(err, "failed to stat log file, path: %s", fpath)
+	}
+
+	// The nearest existing directory must be writable to create the file or
+	// the missing directories.
+	for dir := filepath.Dir(fpath); ; {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return goutils.NewErr("log path isn't a directory, path: %s", dir)
+			}
+			if err := checkWritable(dir); err != nil {
+				return goutils.WrapErrorf(err, "log dir isn't writable, dir: %s", dir)
+			}
+			return nil
+		} else if !isNotExist(err) {
+			return goutils.WrapErrorf(err, "failed to stat log dir, dir: %s", dir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return goutils.NewErr("no existing dir of log file, path: %s", fpath)
+		}
+		dir = parent
+	}
+}
+
+// isNotExist returns whether the path doesn't exist, including the case of a
+// file in the middle of the path.
+func isNotExist(err error) bool {
+	return os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR)
+}
+
+func checkString(conf confSource, label string) error {
+	_, err := conf.GetString(label)
+	return err
+}
+
+func checkBool(conf confSource, label string) error {
+	str, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseBool(str)
+	return err
+}
+
+func checkInt(conf confSource, label string) error {
+	val, err := conf.GetInt(label)
+	if err != nil {
+		return err
+	}
+	if val < 0 {
+		return goutils.NewErr("negative value: %d", val)
+	}
+
+	return nil
+}
+
+func checkLevelItem(conf confSource, label string) error {
+	lvStr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+	if !NewLevelString(lvStr).IsValid() {
+		return goutils.NewErr("Unkown logger level, level: %s", lvStr)
+	}
+
+	return nil
+}
+
+func checkNames(conf confSource, label string) error {
+	_, err := conf.GetStringArray(label)
+	return err
+}
+
+// checkRedact checks an item of redaction by itself.
+func checkRedact(conf confSource, label string) error {
+	vals, err := conf.GetStringArray(label)
+	if err != nil {
+		return err
+	}
+
+	switch label {
+	case _REDACT_KEYS_LABEL:
+		_, err = newConfigRedactor(vals, nil, nil)
+	case _REDACT_PAT_LABEL:
+		_, err = newConfigRedactor(nil, vals, nil)
+	case _REDACT_DET_LABEL:
+		_, err = newConfigRedactor(nil, nil, vals)
+	}
+
+	return err
+}
+
+func checkType(conf confSource, label string) error {
+	htStr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+	if _, ok := handlerTypes[htStr]; !ok {
+		return goutils.NewErr("unknown handler type: %s", htStr)
+	}
+
+	return nil
+}
+
+func checkBackupCount(conf confSource, label string) error {
+	count, err := conf.GetInt(label)
+	if err != nil {
+		return err
+	}
+	if count < 0 || count > 0xffff {
+		return goutils.NewErr("backup count is out of range [0, 65535], value: %d", count)
+	}
+
+	return nil
+}
+
+func checkInterval(conf confSource, label string) error {
+	_, err := parseInterval(conf)
+	return err
+}
+
+func checkSize(conf confSource, label string) error {
+	_, err := parseSize(conf, label)
+	return err
+}
+
+func checkDuration(conf confSource, label string) error {
+	_, err := parseDuration(conf, label)
+	return err
+}
+
+func checkFileMode(conf confSource, label string) error {
+	_, err := parseFileMode(conf, label)
+	return err
+}
+
+func checkFsync(conf confSource, label string) error {
+	policyStr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewFsyncPolicyString(policyStr)
+	return err
+}
+
+func checkSchedule(conf confSource, label string) error {
+	expr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewCronSchedule(expr)
+	return err
+}
+
+func checkTimezone(conf confSource, label string) error {
+	name, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = LoadLocation(name)
+	return err
+}
+
+func checkHooks(conf confSource, label string) error {
+	names, err := conf.GetStringArray(label)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := getRotateHook(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkOwner(conf confSource, label string) error {
+	name, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	perm := &FilePerm{}
+	if label == _OWNER_LABEL {
+		perm.Owner = name
+	} else {
+		perm.Group = name
+	}
+	_, _, err = perm.ids()
+
+	return err
+}
+
+func checkKeyFile(conf confSource, label string) error {
+	path, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = LoadKeyFile(path)
+	return err
+}
+
+func checkKeyEnv(conf confSource, label string) error {
+	name, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = LoadKeyEnv(name)
+	return err
+}
+
+func checkFormat(conf confSource, label string) error {
+	format, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewFormatter(format)
+	return err
+}
+
+func checkMultiline(conf confSource, label string) error {
+	policyStr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewMultilinePolicyString(policyStr)
+	return err
+}
+
+func checkFailurePolicy(conf confSource, label string) error {
+	policyStr, err := conf.GetString(label)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewFailurePolicyString(policyStr)
+	return err
+}
+
+func init() {
+	globalItems = map[string]_ItemCheck{
+		_LOGGERS_LABEL: checkNames,
+	}
+
+	loggerItems = map[string]_ItemCheck{
+		_LEVEL_LABEL:       checkLevelItem,
+		_HANDLERS_LABEL:    checkNames,
+		_OVERWRITE_LABEL:   checkBool,
+		_CALLER_SKIP_LABEL: checkInt,
+		_REDACT_KEYS_LABEL: checkRedact,
+		_REDACT_PAT_LABEL:  checkRedact,
+		_REDACT_DET_LABEL:  checkRedact,
+	}
+
+	handlerItems = map[string]_ItemRule{
+		_HANDLER_EXTEND:     {check: checkString},
+		_TYPE_LABEL:         {check: checkType},
+		_FORMMATER_LABEL:    {check: checkString},
+		_SYNC_MODE_LABEL:    {check: checkBool},
+		_MAX_MSG_LABEL:      {check: checkSize},
+		_MAX_RECORD_LABEL:   {check: checkSize},
+		_ON_FAILURE_LABEL:   {check: checkFailurePolicy},
+		_RETRIES_LABEL:      {check: checkInt},
+		_RETRY_BACK_LABEL:   {check: checkDuration},
+		_DISABLE_AFT_LABEL:  {check: checkInt},
+		_FALLBACK_LABEL:     {check: checkString},
+		_LOG_PATH_LABEL:     {types: fileHandlerTypes, check: checkString},
+		_FILENAME_LABEL:     {types: fileHandlerTypes, check: checkString},
+		_INTERVAL_LABEL:     {types: timeHandlerTypes, check: checkInterval},
+		_SCHEDULE_LABEL:     {types: timeHandlerTypes, check: checkSchedule},
+		_TIMEZONE_LABEL:     {types: timeHandlerTypes, check: checkTimezone},
+		_ROTATE_TIMER_LABEL: {types: timeHandlerTypes, check: checkBool},
+		_ROTATE_START_LABEL: {types: fileHandlerTypes, check: checkBool},
+		_MAX_SIZE_LABEL:     {types: sizeHandlerTypes, check: checkSize},
+		_BACKUP_COUNT_LABEL: {types: fileHandlerTypes, check: checkBackupCount},
+		_BUFFER_SIZE_LABEL:  {types: fileHandlerTypes, check: checkSize},
+		_FLUSH_INTVAL_LABEL: {types: fileHandlerTypes, check: checkDuration},
+		_FSYNC_LABEL:        {types: fileHandlerTypes, check: checkFsync},
+		_FSYNC_INTVAL_LABEL: {types: fileHandlerTypes, check: checkDuration},
+		_MULTI_PROC_LABEL:   {types: fileHandlerTypes, check: checkBool},
+		_BACKUP_NAME_LABEL:  {types: fileHandlerTypes, check: checkString},
+		_SYMLINK_LABEL:      {types: fileHandlerTypes, check: checkString},
+		_POST_ROTATE_LABEL:  {types: fileHandlerTypes, check: checkHooks, severity: SEVERITY_WARNING},
+		_FILE_MODE_LABEL:    {types: fileHandlerTypes, check: checkFileMode},
+		_DIR_MODE_LABEL:     {types: fileHandlerTypes, check: checkFileMode},
+		_OWNER_LABEL:        {types: fileHandlerTypes, check: checkOwner, severity: SEVERITY_WARNING},
+		_GROUP_LABEL:        {types: fileHandlerTypes, check: checkOwner, severity: SEVERITY_WARNING},
+		_AUDIT_LABEL:        {types: fileHandlerTypes, check: checkBool},
+		_KEY_FILE_LABEL:     {types: fileHandlerTypes, check: checkKeyFile},
+		_KEY_ENV_LABEL:      {types: fileHandlerTypes, check: checkKeyEnv, severity: SEVERITY_WARNING},
+		_KEY_ID_LABEL:       {types: fileHandlerTypes, check: checkString},
+	}
+
+	formatterItems = map[string]_ItemCheck{
+		_FORMAT_LABEL:    checkFormat,
+		_MULTILINE_LABEL: checkMultiline,
+	}
+}
@@ -0,0 +1,189 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 23:41:26
+ */
+
+package gologging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func findDiag(diags []Diagnostic, severity Severity, section, key, msg string) *Diagnostic {
+	for i, diag := range diags {
+		if diag.Severity == severity && diag.Section == section && diag.Key == key &&
+			strings.Contains(diag.Message, msg) {
+			return &diags[i]
+		}
+	}
+
+	return nil
+}
+
+func TestValidateSample(t *testing.T) {
+	configPaths := []string{"logger-sample.conf"}
+	if _YAML_SUPPORTED {
+		configPaths = append(configPaths, "logger-sample.yaml")
+	}
+	for _, configPath := range configPaths {
+		diags := Validate(configPath)
+		if HasErrors(diags) {
+			t.Fatalf("errors in %s: %v", configPath, diags)
+		}
+		if findDiag(diags, SEVERITY_WARNING, "handler-access", "", "isn't referenced") == nil {
+			t.Fatalf("unreferenced section isn't reported, config: %s, diags: %v", configPath, diags)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatalf("failed to write file, err: %v", err)
+	}
+
+	config := `loggers: logger-a logger-b
+[logger-a]
+    level: VERBOSE
+    handlers: handler-a handler-b
+    colour: red
+[logger-b]
+    handlers: handler-c handler-d
+    overwrite: yes
+[handler-a]
+    type: time-rotate
+    log-path: ` + dir + `
+    file-name: a.log
+    max-size: 1MB
+    formatter: formatter-a
+[handler-b]
+    extends: handler-c
+[handler-c]
+    extends: handler-b
+[handler-d]
+    type: size-rotate
+    max-size: 1MB
+    log-path: ` + notDir + `/logs
+    file-name: d.log
+[formatter-a]
+    format: ${datetime} ${unknown-attr} ${message}
+[formatter-unused]
+    format: ${message}
+`
+	configPath := filepath.Join(dir, "logger.conf")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	diags := Validate(configPath)
+	cases := []struct {
+		severity     Severity
+		section, key string
+		msg          string
+		line         int
+	}{
+		{SEVERITY_ERROR, "logger-a", "level", "Unkown logger level", 3},
+		{SEVERITY_WARNING, "logger-a", "colour", "unknown key", 5},
+		{SEVERITY_ERROR, "logger-b", "overwrite", "", 8},
+		{SEVERITY_WARNING, "handler-a", "max-size", "takes no effect", 13},
+		{SEVERITY_ERROR, "formatter-a", "format", "unknown-attr", 25},
+		{SEVERITY_ERROR, "handler-c", "extends", "cycle of extends", 18},
+		{SEVERITY_ERROR, "handler-d", "log-path", "isn't a directory", 22},
+		{SEVERITY_WARNING, "formatter-unused", "", "isn't referenced", 26},
+	}
+	for _, c := range cases {
+		diag := findDiag(diags, c.severity, c.section, c.key, c.msg)
+		if diag == nil {
+			t.Fatalf("diagnostic of [%s] %s isn't reported, diags: %v", c.section, c.key, diags)
+		}
+		if diag.Line != c.line || diag.File != configPath {
+			t.Fatalf("unexpected location: %s, expected line: %d", diag, c.line)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "a.log")); !os.IsNotExist(err) {
+		t.Fatalf("log file is created by Validate")
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	requireYAML(t)
+
+	dir := t.TempDir()
+	config := `
+loggers:
+  a: {handlers: [h]}
+handlers:
+  h:
+    type: console
+    file-name: a.log
+    formatter: missing
+`
+	configPath := filepath.Join(dir, "logger.yaml")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	diags := Validate(configPath)
+	if diag := findDiag(diags, SEVERITY_WARNING, "h", "file-name", "takes no effect"); diag == nil || diag.Line != 7 {
+		t.Fatalf("key of file handler on console isn't reported, diags: %v", diags)
+	}
+	if findDiag(diags, SEVERITY_ERROR, "h", "formatter", "no formatter") == nil {
+		t.Fatalf("missing formatter isn't reported, diags: %v", diags)
+	}
+
+	os.WriteFile(configPath, []byte("loggers: [a"), 0644)
+	if diags := Validate(configPath); len(diags) != 1 || !HasErrors(diags) {
+		t.Fatalf("invalid YAML isn't reported, diags: %v", diags)
+	}
+}
+
+func TestValidateFailure(t *testing.T) {
+	dir := t.TempDir()
+	config := `loggers: logger-a
+[logger-a]
+    handlers: handler-a handler-b handler-c
+[handler-a]
+    type: console
+    on-failure: fallback
+    fallback: handler-b
+[handler-b]
+    type: console
+    on-failure: fallback
+    fallback: handler-c
+    retries: 3
+[handler-c]
+    type: console
+    on-failure: fallback
+`
+	configPath := filepath.Join(dir, "logger.conf")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config, err: %v", err)
+	}
+
+	diags := Validate(configPath)
+	cases := []struct {
+		severity     Severity
+		section, key string
+		msg          string
+		line         int
+	}{
+		{SEVERITY_ERROR, "handler-b", "fallback", "can't have a fallback", 11},
+		{SEVERITY_WARNING, "handler-b", "retries", "takes no effect", 12},
+		{SEVERITY_ERROR, "handler-c", "on-failure", "no fallback handler", 15},
+	}
+	for _, c := range cases {
+		diag := findDiag(diags, c.severity, c.section, c.key, c.msg)
+		if diag == nil {
+			t.Fatalf("diagnostic of [%s] %s isn't reported, diags: %v", c.section, c.key, diags)
+		}
+		if diag.Line != c.line {
+			t.Fatalf("unexpected location: %s, expected line: %d", diag, c.line)
+		}
+	}
+}
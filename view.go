@@ -0,0 +1,502 @@
+/**
+ * Viewing of log files and their backups as records.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 09:47:20
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"github.com/chosen0ne/goutils"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// Interval to check the log file for new logs and rotation by Follow.
+	DEFAULT_FOLLOW_INTERVAL = 200 * time.Millisecond
+
+	_GZIP_SUFFIX = ".gz"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	// Hash appended to the lines of audit logs, see audit.go.
+	chainRe = regexp.MustCompile(regexp.QuoteMeta(_CHAIN_SEP) + "[0-9a-f]{64}$")
+)
+
+// ViewFilter selects the records read by a LogView, the zero value selects
+// all. A condition on an attribute which isn't in the format is ignored.
+type ViewFilter struct {
+	// Records below the level are skipped.
+	MinLevel Level
+	// Range of the time of records, [Since, Until). Zero means unbounded.
+	Since time.Time
+	Until time.Time
+	// Name of the logger of records.
+	Logger string
+	// Records whose raw lines don't match the regexp are skipped.
+	Pattern *regexp.Regexp
+}
+
+func (filter *ViewFilter) match(record *Record) bool {
+	if _, ok := record.Attrs[_LEVELNAME]; ok && record.Level < filter.MinLevel {
+		return false
+	}
+
+	if !record.Time.IsZero() {
+		if !filter.Since.IsZero() && record.Time.Before(filter.Since) {
+			return false
+		}
+		if !filter.Until.IsZero() && !record.Time.Before(filter.Until) {
+			return false
+		}
+	}
+
+	if name, ok := record.Attrs[_LOGGER_NAME]; ok && filter.Logger != "" && name != filter.Logger {
+		return false
+	}
+
+	return filter.Pattern == nil || filter.Pattern.MatchString(record.Raw)
+}
+
+// LogView reads the records of a log file and its backups from the oldest,
+// which are parsed by the format of the handler. Backups compressed by gzip,
+// e.g. by a post rotate hook, are read as well, and they are found by the
+// backup pattern followed by '.gz'.
+type LogView struct {
+	fileName string
+	namer    *backupNamer
+	format   *Formatter
+	ring     *KeyRing
+	filter   ViewFilter
+	// The active file read by Cat or Tail, and the offset read, where
+	// Follow starts.
+	active os.FileInfo
+	offset int64
+}
+
+// NewLogView returns a view of the log file and its backups named by
+// 'backupPattern'. Empty pattern means DEFAULT_TIME_BACKUP_PATTERN.
+func NewLogView(fileName, backupPattern string, format *Formatter) (*LogView, error) {
+	if backupPattern == "" {
+		backupPattern = DEFAULT_TIME_BACKUP_PATTERN
+	}
+
+	namer, err := newBackupNamer(backupPattern, fileName)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse backup pattern")
+	}
+
+	return &LogView{fileName: fileName, namer: namer, format: format}, nil
+}
+
+// SetKeys decrypts the files by the keys.
+func (view *LogView) SetKeys(ring *KeyRing) {
+	view.ring = ring
+}
+
+func (view *LogView) SetFilter(filter ViewFilter) {
+	view.filter = filter
+}
+
+// Files returns the backups from the oldest, followed by the log file if it
+// exists.
+func (view *LogView) Files() ([]string, error) {
+	backups, err := view.namer.list(_GZIP_SUFFIX)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to list backups")
+	}
+
+	paths := make([]string, 0, len(backups)+1)
+	for _, backup := range backups {
+		paths = append(paths, backup.path)
+	}
+
+	if _, err := os.Stat(view.fileName); err == nil {
+		paths = append(paths, view.fileName)
+	} else if !os.IsNotExist(err) {
+		return nil, goutils.WrapErrorf(err, "failed to stat file, file: %s", view.fileName)
+	}
+
+	return paths, nil
+}
+
+// Cat calls 'fn' with the records of all the files in order.
+func (view *LogView) Cat(fn func(*Record) error) error {
+	paths, err := view.Files()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := view.readFile(path, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Tail returns the last 'n' records, the backups are read only if the log
+// file has less than 'n' records.
+func (view *LogView) Tail(n int) ([]*Record, error) {
+	paths, err := view.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	for i := len(paths) - 1; i >= 0 && len(records) < n; i-- {
+		need := n - len(records)
+		var fileRecords []*Record
+		err := view.readFile(paths[i], func(record *Record) error {
+			// Only the last 'need' records are kept
+			if fileRecords = append(fileRecords, record); len(fileRecords) >= 2*need {
+				fileRecords = append(fileRecords[:0], fileRecords[len(fileRecords)-need:]...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fileRecords) > need {
+			fileRecords = fileRecords[len(fileRecords)-need:]
+		}
+		records = append(fileRecords, records...)
+	}
+
+	return records, nil
+}
+
+// Follow calls 'fn' with the records appended to the log file until 'ctx'
+// is done. It starts where Cat or Tail stopped reading the log file, or at
+// the end of it. When the file is rotated, the rest of the old file is read
+// and the new file is followed from the start. A record is passed to 'fn'
+// after the next record is read, or no more lines are read in 'interval',
+// so that the lines of a multi-line message are kept together. Encrypted
+// files can't be followed.
+func (view *LogView) Follow(ctx context.Context, interval time.Duration, fn func(*Record) error) error {
+	if view.ring != nil {
+		return goutils.NewErr("encrypted log file can't be followed")
+	}
+	if interval <= 0 {
+		interval = DEFAULT_FOLLOW_INTERVAL
+	}
+
+	f := &followedFile{path: view.fileName, scanner: view.newScanner(fn)}
+	defer f.close()
+
+	if info, err := os.Stat(view.fileName); err == nil {
+		offset := info.Size()
+		if view.active != nil {
+			offset = 0
+			if os.SameFile(info, view.active) {
+				offset = view.offset
+			}
+		}
+		if err := f.open(offset); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return goutils.WrapErrorf(err, "failed to stat file, file: %s", view.fileName)
+	}
+
+	for {
+		if err := f.poll(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return f.scanner.flush()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// followedFile reads the lines appended to the file, and reopens it when
+// it's rotated.
+type followedFile struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial string // the last line without newline
+	scanner *recordScanner
+}
+
+func (f *followedFile) open(offset int64) error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		// Wait for the file to be created
+		return nil
+	} else if err != nil {
+		return goutils.WrapErrorf(err, "failed to open file, file: %s", f.path)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return goutils.WrapErrorf(err, "failed to stat file, file: %s", f.path)
+	}
+	if offset > info.Size() {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return goutils.WrapErrorf(err, "failed to seek file, file: %s", f.path)
+	}
+
+	f.file, f.info, f.offset, f.partial = file, info, offset, ""
+	f.reader = bufio.NewReader(file)
+
+	return nil
+}
+
+func (f *followedFile) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// poll reads the new lines, and checks whether the file is rotated or
+// truncated.
+func (f *followedFile) poll() error {
+	if f.file == nil {
+		return f.open(0)
+	}
+
+	n, err := f.readLines()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(f.path)
+	switch {
+	case os.IsNotExist(err):
+		// Being rotated, the new file will be opened
+	case err != nil:
+		return goutils.WrapErrorf(err, "failed to stat file, file: %s", f.path)
+	case !os.SameFile(info, f.info):
+		// Lines may be appended to the old file after it's read above and
+		// before it's replaced, so it's read again to the end.
+		if _, err := f.readLines(); err != nil {
+			return err
+		}
+		if err := f.flushPartial(); err != nil {
+			return err
+		}
+		f.close()
+		return f.open(0)
+	case info.Size() < f.offset:
+		if err := f.flushPartial(); err != nil {
+			return err
+		}
+		f.close()
+		return f.open(0)
+	}
+
+	if n == 0 {
+		return f.scanner.flush()
+	}
+
+	return nil
+}
+
+// readLines reads the complete lines appended to the file, and returns the
+// number of bytes read.
+func (f *followedFile) readLines() (int, error) {
+	total := 0
+	for {
+		line, err := f.reader.ReadString(byte(_NEWLINE))
+		total += len(line)
+		f.offset += int64(len(line))
+
+		if err == io.EOF {
+			f.partial += line
+			return total, nil
+		} else if err != nil {
+			return total, goutils.WrapErrorf(err, "failed to read file, file: %s", f.path)
+		}
+
+		line, f.partial = f.partial+line, ""
+		if err := f.scanner.addLine(strings.TrimSuffix(line, string(_NEWLINE))); err != nil {
+			return total, err
+		}
+	}
+}
+
+func (f *followedFile) flushPartial() error {
+	if f.partial != "" {
+		if err := f.scanner.addLine(f.partial); err != nil {
+			return err
+		}
+		f.partial = ""
+	}
+
+	return f.scanner.flush()
+}
+
+// readFile calls 'fn' with the records of the file, which is decompressed
+// and decrypted if needed.
+func (view *LogView) readFile(path string, fn func(*Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to open file, file: %s", path)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return goutils.WrapErrorf(err, "failed to stat file, file: %s", path)
+	}
+
+	br := bufio.NewReader(file)
+	var r io.Reader = br
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return goutils.WrapErrorf(err, "failed to read compressed file, file: %s", path)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if view.ring != nil {
+		r = NewDecryptReader(r, view.ring)
+	}
+
+	scanner := view.newScanner(fn)
+	lines := bufio.NewReader(r)
+	var offset int64
+	for {
+		line, err := lines.ReadString(byte(_NEWLINE))
+		offset += int64(len(line))
+		if line != "" {
+			if err := scanner.addLine(strings.TrimSuffix(line, string(_NEWLINE))); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return goutils.WrapErrorf(err, "failed to read file, file: %s", path)
+		}
+	}
+
+	if path == view.fileName {
+		view.active, view.offset = info, offset
+	}
+
+	return scanner.flush()
+}
+
+func (view *LogView) newScanner(fn func(*Record) error) *recordScanner {
+	return &recordScanner{format: view.format, filter: &view.filter, emit: fn}
+}
+
+// recordScanner groups lines into records. A line is the first line of a
+// record if it matches the format up to ${message}, and the following lines
+// are continuations of the message by the multiline policy of the format.
+// For MULTILINE_KEEP, a line which doesn't start a record continues the
+// previous one, e.g. a stack trace.
+type recordScanner struct {
+	format *Formatter
+	filter *ViewFilter
+	emit   func(*Record) error
+	// Lines of the pending record, without the hash of audit logs.
+	lines  []string
+	raw    []string
+	header string
+}
+
+func (scanner *recordScanner) addLine(line string) error {
+	// The hash of audit logs isn't a part of the record
+	text := line
+	if loc := chainRe.FindStringIndex(line); loc != nil {
+		text = line[:loc[0]]
+	}
+
+	if len(scanner.lines) != 0 && scanner.format.isContinuation(text, scanner.header) {
+		scanner.lines, scanner.raw = append(scanner.lines, text), append(scanner.raw, line)
+		return nil
+	}
+
+	if header, ok := scanner.matchStart(text); ok {
+		if err := scanner.flush(); err != nil {
+			return err
+		}
+		scanner.lines, scanner.raw, scanner.header = append(scanner.lines, text), append(scanner.raw, line), header
+		return nil
+	}
+
+	if len(scanner.lines) == 0 {
+		// Lines before the first record, e.g. the format doesn't match
+		scanner.lines, scanner.raw, scanner.header = append(scanner.lines, text), append(scanner.raw, line), ""
+		return nil
+	}
+	scanner.lines, scanner.raw = append(scanner.lines, text), append(scanner.raw, line)
+
+	return nil
+}
+
+// matchStart returns the header if the line starts a record.
+func (scanner *recordScanner) matchStart(text string) (string, bool) {
+	if scanner.format.jsonFields != nil {
+		// A log of a JSON format is always one line
+		_, ok := scanner.format.parseLine(text)
+		return "", ok
+	}
+
+	_, header, ok := scanner.format.matchHeader(text)
+	return header, ok
+}
+
+// flush emits the pending record if it's selected by the filter.
+func (scanner *recordScanner) flush() error {
+	if len(scanner.lines) == 0 {
+		return nil
+	}
+	record := scanner.record()
+	scanner.lines, scanner.raw, scanner.header = scanner.lines[:0], scanner.raw[:0], ""
+
+	if !scanner.filter.match(record) {
+		return nil
+	}
+
+	return scanner.emit(record)
+}
+
+func (scanner *recordScanner) record() *Record {
+	text := strings.Join(scanner.lines, string(_NEWLINE))
+	raw := strings.Join(scanner.raw, string(_NEWLINE))
+
+	if record, ok := scanner.format.parseLine(text); ok {
+		record.Raw = raw
+		return record
+	}
+
+	// The lines don't make a whole log, e.g. the end of the format is lost,
+	// so the attributes up to the message are kept
+	if scanner.format.jsonFields == nil {
+		if attrs, _, ok := scanner.format.matchHeader(scanner.lines[0]); ok {
+			if len(scanner.lines) > 1 {
+				attrs[_MESSAGE] += string(_NEWLINE) + strings.Join(scanner.lines[1:], string(_NEWLINE))
+			}
+			return newRecord(attrs, raw)
+		}
+	}
+
+	return &Record{Message: text, Raw: raw}
+}
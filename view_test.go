@@ -0,0 +1,232 @@
+/**
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 11:34:56
+ */
+
+package gologging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const _VIEW_FORMAT = "${datetime} [${levelname}] ${name}: ${message}"
+
+func writeViewFile(t *testing.T, path string, compress bool, lines ...string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file, err: %v", err)
+	}
+	defer f.Close()
+
+	data := strings.Join(lines, "\n") + "\n"
+	if compress {
+		gz := gzip.NewWriter(f)
+		gz.Write([]byte(data))
+		gz.Close()
+	} else {
+		f.WriteString(data)
+	}
+}
+
+func viewMessages(records []*Record) []string {
+	msgs := make([]string, len(records))
+	for i, record := range records {
+		msgs[i] = record.Message
+	}
+
+	return msgs
+}
+
+func TestLogView(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	writeViewFile(t, fileName+"_0001.gz", true,
+		"2026-10-18 10:00:00 [INFO] app: one",
+		"2026-10-18 10:00:01 [ERROR] app: two",
+		"  at main.go:1")
+	writeViewFile(t, fileName+"_0002", false,
+		"2026-10-18 11:00:00 [DEBUG] app: three",
+		"2026-10-18 11:00:01 [WARN] db: four")
+	writeViewFile(t, fileName, false,
+		"2026-10-18 12:00:00 [INFO] app: five #chain="+strings.Repeat("0a", 32))
+
+	formatter, _ := NewFormatter(_VIEW_FORMAT)
+	view, err := NewLogView(fileName, DEFAULT_SIZE_BACKUP_PATTERN, formatter)
+	if err != nil {
+		t.Fatalf("failed to create view, err: %v", err)
+	}
+
+	var records []*Record
+	if err := view.Cat(func(record *Record) error {
+		records = append(records, record)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to cat, err: %v", err)
+	}
+	if msgs := strings.Join(viewMessages(records), "|"); msgs != "one|two\n  at main.go:1|three|four|five" {
+		t.Fatalf("unexpected records: %q", msgs)
+	}
+
+	view.SetFilter(ViewFilter{
+		MinLevel: INFO,
+		Since:    time.Date(2026, 10, 18, 10, 0, 1, 0, time.Local),
+		Logger:   "app",
+		Pattern:  regexp.MustCompile("main|five"),
+	})
+	if records, err = view.Tail(5); err != nil {
+		t.Fatalf("failed to tail, err: %v", err)
+	}
+	if msgs := strings.Join(viewMessages(records), "|"); msgs != "two\n  at main.go:1|five" {
+		t.Fatalf("unexpected filtered records: %q", msgs)
+	}
+
+	// Follow from the end of the tail, across a rotation
+	view.SetFilter(ViewFilter{})
+	if records, err = view.Tail(1); err != nil || len(records) != 1 {
+		t.Fatalf("failed to tail, records: %v, err: %v", records, err)
+	}
+
+	var mu sync.Mutex
+	var followed []string
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- view.Follow(ctx, 10*time.Millisecond, func(record *Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			followed = append(followed, record.Message)
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	writeViewFile(t, fileName, false, "2026-10-18 12:00:01 [INFO] app: six")
+	time.Sleep(50 * time.Millisecond)
+	writeViewFile(t, fileName, false, "2026-10-18 12:00:02 [INFO] app: seven")
+	os.Rename(fileName, fileName+"_0003")
+	writeViewFile(t, fileName, false, "2026-10-18 12:00:03 [INFO] app: eight", "  detail")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		msgs := strings.Join(followed, "|")
+		mu.Unlock()
+		if msgs == "six|seven|eight\n  detail" {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("unexpected followed records: %q", msgs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("failed to follow, err: %v", err)
+	}
+}
+
+func TestLogViewMultiline(t *testing.T) {
+	messages := []string{"one", "two\n  at main.go:1\n2026-10-18 10:00:00 [INFO] app: dump", "three"}
+	formatter, _ := NewFormatter(_VIEW_FORMAT + " (${lineno})")
+
+	for _, policy := range []MultilinePolicy{MULTILINE_KEEP, MULTILINE_ESCAPE, MULTILINE_INDENT, MULTILINE_PREFIX} {
+		format := formatter.WithMultiline(policy)
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		var data []byte
+		for i, message := range messages {
+			data = format.AppendFormat(data, &_Msg{
+				time:       time.Date(2026, 10, 18, 10, 0, i, 0, time.Local),
+				level:      INFO,
+				loggerName: "app",
+				lineNo:     i,
+				message:    []byte(message),
+			})
+		}
+		if err := os.WriteFile(fileName, data, 0644); err != nil {
+			t.Fatalf("failed to write file, err: %v", err)
+		}
+
+		view, _ := NewLogView(fileName, "", format)
+		var records []*Record
+		view.Cat(func(record *Record) error {
+			records = append(records, record)
+			return nil
+		})
+
+		expect := messages
+		if policy == MULTILINE_KEEP {
+			// A line of the message which looks like a log is taken as a log
+			expect = []string{"one", "two\n  at main.go:1", "dump", "three"}
+		}
+		if msgs := viewMessages(records); strings.Join(msgs, "|") != strings.Join(expect, "|") {
+			t.Fatalf("unexpected records of %s: %q", policy.Name(), msgs)
+		}
+	}
+}
+
+// eofHookReader calls 'hook' when the reader reaches EOF the first time.
+type eofHookReader struct {
+	r    io.Reader
+	hook func()
+}
+
+func (r *eofHookReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF && r.hook != nil {
+		r.hook()
+		r.hook = nil
+	}
+
+	return n, err
+}
+
+// Lines appended to the old file after it's read to the end and before it's
+// replaced by the new file aren't lost.
+func TestFollowReplacedFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	writeViewFile(t, fileName, false, "2026-10-18 10:00:00 [INFO] app: one")
+
+	formatter, _ := NewFormatter(_VIEW_FORMAT)
+	view, _ := NewLogView(fileName, "", formatter)
+	var records []*Record
+	f := &followedFile{path: fileName, scanner: view.newScanner(func(record *Record) error {
+		records = append(records, record)
+		return nil
+	})}
+	defer f.close()
+
+	if err := f.poll(); err != nil {
+		t.Fatalf("failed to poll, err: %v", err)
+	}
+	f.reader = bufio.NewReader(&eofHookReader{r: f.file, hook: func() {
+		writeViewFile(t, fileName, false, "2026-10-18 10:00:01 [INFO] app: two")
+		if err := os.Rename(fileName, fileName+"_0001"); err != nil {
+			t.Fatalf("failed to rename file, err: %v", err)
+		}
+		writeViewFile(t, fileName, false, "2026-10-18 10:00:02 [INFO] app: three")
+	}})
+
+	for i := 0; i < 2; i++ {
+		if err := f.poll(); err != nil {
+			t.Fatalf("failed to poll, err: %v", err)
+		}
+	}
+	if err := f.scanner.flush(); err != nil {
+		t.Fatalf("failed to flush, err: %v", err)
+	}
+
+	if msgs := viewMessages(records); strings.Join(msgs, "|") != "one|two|three" {
+		t.Fatalf("unexpected records: %q", msgs)
+	}
+}
@@ -0,0 +1,199 @@
+/**
+ * YAML and JSON configs of loggers.
+ *
+ * The configs are converted to the sections of goconf, so they share the
+ * loader and all the options with 'logger-sample.conf':
+ *	loggers:
+ *	  info:                    # [logger-info]
+ *	    level: INFO
+ *	    handlers: [handler-info]
+ *	handlers:
+ *	  handler-info:            # [handler-info]
+ *	    extends: time-rotate-conf
+ *	    file-name: info.log
+ *	formatters:
+ *	  formatter-1:             # [formatter-1]
+ *	    format: ${datetime} ${message}
+ * Items of a section are the same as the goconf format, a value is a scalar
+ * or a list of scalars. See 'logger-sample.yaml' and 'logger-config.schema.json'.
+ *
+ * Parsing of YAML needs gopkg.in/yaml.v3, which is only built with the
+ * build tag 'yaml', e.g. 'go build -tags yaml', so the package has no
+ * dependency other than goutils and goconf by default. JSON is parsed by
+ * encoding/json.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-18 21:52:14
+ */
+
+package gologging
+
+import (
+	"github.com/chosen0ne/goutils"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	_YAML_LOGGERS    = "loggers"
+	_YAML_HANDLERS   = "handlers"
+	_YAML_FORMATTERS = "formatters"
+	_LOGGER_PREFIX   = "logger-"
+)
+
+// sectionConf is a config of sections, which is read by the loader as
+// goconf.Conf. An item is a string or a list of strings.
+type sectionConf struct {
+	sections map[string]map[string]interface{}
+	cur      map[string]interface{}
+	// Sections and items in the order of the file with the line numbers,
+	// which are used by Validate.
+	names []string
+	infos map[string]*_SectionInfo
+}
+
+type _SectionInfo struct {
+	line  int
+	keys  []string
+	lines map[string]int
+}
+
+func newSectionConf() *sectionConf {
+	global := make(map[string]interface{})
+	return &sectionConf{
+		sections: map[string]map[string]interface{}{"": global},
+		cur:      global,
+		infos:    map[string]*_SectionInfo{"": {lines: make(map[string]int)}},
+	}
+}
+
+// addSection adds an empty section at 'line', which mustn't exist.
+func (conf *sectionConf) addSection(name string, line int) error {
+	if _, ok := conf.sections[name]; ok {
+		return goutils.NewErr("duplicated section: %s, line: %d", name, line)
+	}
+
+	conf.sections[name] = make(map[string]interface{})
+	conf.names = append(conf.names, name)
+	conf.infos[name] = &_SectionInfo{line: line, lines: make(map[string]int)}
+
+	return nil
+}
+
+// setItem sets an item of a section, which is a string or []string.
+func (conf *sectionConf) setItem(section, key string, val interface{}, line int) {
+	info := conf.infos[section]
+	if _, ok := conf.sections[section][key]; !ok {
+		info.keys = append(info.keys, key)
+	}
+	info.lines[key] = line
+	conf.sections[section][key] = val
+}
+
+// line returns the line number of the item, or of the section if 'key' is
+// empty. 0 means unknown.
+func (conf *sectionConf) line(section, key string) int {
+	info, ok := conf.infos[section]
+	if !ok {
+		return 0
+	}
+	if key == "" {
+		return info.line
+	}
+
+	return info.lines[key]
+}
+
+func (conf *sectionConf) Section(name string) error {
+	section, ok := conf.sections[name]
+	if !ok {
+		return goutils.NewErr("no section named '%s'", name)
+	}
+	conf.cur = section
+
+	return nil
+}
+
+func (conf *sectionConf) HasSection(name string) bool {
+	_, ok := conf.sections[name]
+	return ok
+}
+
+func (conf *sectionConf) HasItem(name string) bool {
+	_, ok := conf.cur[name]
+	return ok
+}
+
+// GetString returns the item, a list is joined by spaces.
+func (conf *sectionConf) GetString(name string) (string, error) {
+	switch val := conf.cur[name].(type) {
+	case string:
+		return val, nil
+	case []string:
+		return strings.Join(val, " "), nil
+	}
+
+	return "", goutils.NewErr("no item named '%s'", name)
+}
+
+// GetStringArray returns the list, or the fields of a string split by spaces
+// as goconf.
+func (conf *sectionConf) GetStringArray(name string) ([]string, error) {
+	switch val := conf.cur[name].(type) {
+	case string:
+		return strings.Fields(val), nil
+	case []string:
+		return val, nil
+	}
+
+	return nil, goutils.NewErr("no item named '%s'", name)
+}
+
+func (conf *sectionConf) GetInt(name string) (int, error) {
+	str, err := conf.GetString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, goutils.WrapErrorf(err, "item isn't an integer, name: %s, value: %s", name, str)
+	}
+
+	return val, nil
+}
+
+// LoadYAML configures loggers by a YAML file. It needs the build tag 'yaml'.
+func LoadYAML(configPath string) error {
+	conf, err := readYAMLConf(configPath, parseYAMLConf)
+	if err != nil {
+		return err
+	}
+
+	return loadConf(conf)
+}
+
+// LoadJSON configures loggers by a JSON file.
+func LoadJSON(configPath string) error {
+	conf, err := readYAMLConf(configPath, parseJSONConf)
+	if err != nil {
+		return err
+	}
+
+	return loadConf(conf)
+}
+
+func readYAMLConf(configPath string, parse func([]byte) (*sectionConf, error)) (*sectionConf, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to read conf, conf: %s", configPath)
+	}
+
+	conf, err := parse(data)
+	if err != nil {
+		return nil, goutils.WrapErrorf(err, "failed to parse conf, conf: %s", configPath)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,28 @@
+//go:build !yaml
+
+/**
+ * Stubs of YAML configs without the build tag 'yaml'.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 19:12:40
+ */
+
+package gologging
+
+import (
+	"github.com/chosen0ne/goutils"
+)
+
+const _YAML_SUPPORTED = false
+
+func errNoYAML() error {
+	return goutils.NewErr("YAML configs aren't supported, build with '-tags yaml'")
+}
+
+func parseYAMLConf(data []byte) (*sectionConf, error) {
+	return nil, errNoYAML()
+}
+
+func encodeYAMLConfig(doc *_ConfNode) ([]byte, error) {
+	return nil, errNoYAML()
+}
@@ -0,0 +1,123 @@
+//go:build yaml
+
+/**
+ * Parsing of YAML configs, which needs gopkg.in/yaml.v3.
+ *
+ * @author  chosen0ne(louzhenlin86@126.com)
+ * @date    2026-10-19 19:12:40
+ */
+
+package gologging
+
+import (
+	"github.com/chosen0ne/goutils"
+	"gopkg.in/yaml.v3"
+)
+
+// Whether YAML configs are supported, which are built with the build tag
+// 'yaml'.
+const _YAML_SUPPORTED = true
+
+func parseYAMLConf(data []byte) (*sectionConf, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, goutils.WrapErrorf(err, "invalid YAML")
+	}
+
+	return newYAMLSectionConf(&doc)
+}
+
+// newYAMLSectionConf converts the YAML document to sections.
+func newYAMLSectionConf(doc *yaml.Node) (*sectionConf, error) {
+	if doc.Kind == 0 {
+		return nil, goutils.NewErr("empty config")
+	}
+	conf := newSectionConf()
+
+	root := resolveNode(doc)
+	if root.Kind == yaml.DocumentNode {
+		root = resolveNode(root.Content[0])
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, goutils.NewErr("config isn't a mapping, line: %d", root.Line)
+	}
+
+	var loggerNames []string
+	var loggersLine int
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, val := root.Content[i].Value, resolveNode(root.Content[i+1])
+		if key == _YAML_LOGGERS {
+			loggersLine = root.Content[i].Line
+		}
+		if key != _YAML_LOGGERS && key != _YAML_HANDLERS && key != _YAML_FORMATTERS {
+			return nil, goutils.NewErr("unknown config '%s', line: %d", key, root.Content[i].Line)
+		}
+		if val.Kind != yaml.MappingNode {
+			return nil, goutils.NewErr("'%s' isn't a mapping, line: %d", key, val.Line)
+		}
+
+		for j := 0; j+1 < len(val.Content); j += 2 {
+			// Loggers are in the sections named 'logger-${name}' as goconf
+			name, sectionName := val.Content[j].Value, val.Content[j].Value
+			if key == _YAML_LOGGERS {
+				sectionName = _LOGGER_PREFIX + name
+				loggerNames = append(loggerNames, sectionName)
+			}
+
+			if err := addYAMLSection(conf, sectionName, val.Content[j].Line,
+				resolveNode(val.Content[j+1])); err != nil {
+				return nil, goutils.WrapErrorf(err, "failed to convert '%s' of '%s'", name, key)
+			}
+		}
+	}
+
+	if len(loggerNames) == 0 {
+		return nil, goutils.NewErr("no logger is configured")
+	}
+	conf.setItem("", _LOGGERS_LABEL, loggerNames, loggersLine)
+
+	return conf, nil
+}
+
+func addYAMLSection(conf *sectionConf, name string, line int, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return goutils.NewErr("section isn't a mapping, line: %d", node.Line)
+	}
+
+	if err := conf.addSection(name, line); err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i].Value, resolveNode(node.Content[i+1])
+		switch val.Kind {
+		case yaml.ScalarNode:
+			if val.Tag == "!!null" {
+				continue
+			}
+			conf.setItem(name, key, val.Value, node.Content[i].Line)
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(val.Content))
+			for _, item := range val.Content {
+				if item = resolveNode(item); item.Kind != yaml.ScalarNode {
+					return goutils.NewErr("item of '%s' isn't a scalar, line: %d", key, item.Line)
+				}
+				items = append(items, item.Value)
+			}
+			conf.setItem(name, key, items, node.Content[i].Line)
+		default:
+			return goutils.NewErr("'%s' isn't a scalar or a list, line: %d", key, val.Line)
+		}
+	}
+
+	return nil
+}
+
+// resolveNode follows the aliases of anchors.
+func resolveNode(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}